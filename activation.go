@@ -0,0 +1,60 @@
+package argflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ActivationTagName is the tag, on a sub arg field, naming the flag which activates that sub arg.
+// A sub arg's flags are only accepted when its activating flag has also been set.
+// e.g. CacheOpts *CacheOpts `flag:"+" activatedby:"cache"` only accepts the CacheOpts flags when '-cache' is set.
+// The activating flag may be followed with a value, which that flag must be set to, to activate the sub arg.
+// e.g. `activatedby:"store=redis"` activates the sub arg only when '-store redis' is set.
+// Without a value, the activating flag must be set to a non zero value (i.e. true for a bool)
+const ActivationTagName = "activatedby"
+
+// checkActivations checks every applied flag, which is within an activated sub arg, has its activating flag set.
+func (p *Parser) checkActivations(applied []appliedField) error {
+	var errs []error
+	for _, af := range applied {
+		v := af.root
+		sv := v
+		for _, fi := range af.index[:len(af.index)-1] {
+			if activator, ok := sv.Type().Field(fi).Tag.Lookup(ActivationTagName); ok {
+				active, err := p.isActivated(v, activator)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("'%s'  %v", af.flag, err))
+				} else if !active {
+					errs = append(errs, ErrNotActivated{Flag: af.flag, Activator: activator})
+				}
+			}
+			sv = reflect.Indirect(sv.Field(fi))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isActivated checks if the given activator, a flag name with an optional '=value', is set in the given struct.
+func (p *Parser) isActivated(v reflect.Value, activator string) (bool, error) {
+	name, want, hasWant := strings.Cut(activator, "=")
+	index := p.findFieldIndex(name, v.Type(), nil)
+	if len(index) == 0 {
+		return false, fmt.Errorf("activating flag -%s not found in %s", name, v.Type().String())
+	}
+	fld := v
+	for _, fi := range index {
+		fld = fld.Field(fi)
+		for fld.Kind() == reflect.Ptr {
+			if fld.IsNil() {
+				return false, nil
+			}
+			fld = fld.Elem()
+		}
+	}
+	if hasWant {
+		return strings.EqualFold(fmt.Sprint(fld.Interface()), want), nil
+	}
+	return !fld.IsZero(), nil
+}
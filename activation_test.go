@@ -0,0 +1,32 @@
+package argflags
+
+import (
+	"errors"
+	"testing"
+)
+
+type redisOpts struct {
+	Addr string `flag:"redis-addr"`
+}
+
+type storeFlags struct {
+	Store string     `flag:"store"`
+	Redis *redisOpts `flag:"+" activatedby:"store=redis"`
+}
+
+func TestActivatedSubArg(t *testing.T) {
+	var sf storeFlags
+	if _, err := NewParser().Apply([]string{"-store", "redis", "-redis-addr", "a:6379"}, &sf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if sf.Redis == nil || sf.Redis.Addr != "a:6379" {
+		t.Errorf("expected the redis address, got %+v", sf.Redis)
+	}
+
+	sf = storeFlags{}
+	_, err := NewParser().Apply([]string{"-store", "memory", "-redis-addr", "a:6379"}, &sf)
+	var na ErrNotActivated
+	if !errors.As(err, &na) || na.Activator != "store=redis" {
+		t.Errorf("expected ErrNotActivated, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package argflags
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Addr is a listen address, for a server to accept connections on.
+// It accepts a tcp host and port, ':8080', '0.0.0.0:8080' or 'tcp6://[::1]:8080', a bare port, '8080',
+// a unix socket path, 'unix:///var/run/app.sock', or an inherited, open file descriptor, 'fd://3'.
+// A systemd socket activated socket is given as 'systemd://', for the first socket passed, or 'systemd://name',
+// for the socket named with FileDescriptorName in its .socket unit.
+// Network and Address can be given directly to net.Listen, other than for file descriptors and systemd sockets,
+// so use Listen to listen on any form.
+type Addr struct {
+	// Network is 'tcp', 'tcp4', 'tcp6', 'unix', 'unixpacket', 'fd' or 'systemd'
+	Network string
+	// Address is the host and port, the socket path, the file descriptor number or the systemd socket name.
+	Address string
+}
+
+// addrNetworks are the networks which may be given as the scheme of an Addr
+var addrNetworks = []string{"tcp", "tcp4", "tcp6", "unix", "unixpacket", "fd", "systemd"}
+
+// IsUnix checks if the address is a unix socket.
+func (a Addr) IsUnix() bool {
+	return strings.HasPrefix(a.Network, "unix")
+}
+
+// FD gets the file descriptor of an 'fd' address, or -1 if the address is not a file descriptor.
+func (a Addr) FD() int {
+	if a.Network != "fd" {
+		return -1
+	}
+	fd, err := strconv.Atoi(a.Address)
+	if err != nil {
+		return -1
+	}
+	return fd
+}
+
+// Listen listens on the address.  File descriptor addresses are opened as a listener on the inherited descriptor.
+// When the process was started by systemd socket activation, an inherited socket listening on the same address
+// is used in place of opening a new one, so the same address works with and without activation.
+// Each inherited socket is only given to the first address to listen on it.
+func (a Addr) Listen() (net.Listener, error) {
+	if l, ok, err := activatedListener(a); ok {
+		return l, err
+	}
+	if a.Network == "systemd" {
+		return nil, errNoActivatedSocket(a)
+	}
+	if a.Network != "fd" {
+		return net.Listen(a.Network, a.Address)
+	}
+	f := os.NewFile(uintptr(a.FD()), a.String())
+	if f == nil {
+		return nil, fmt.Errorf("invalid file descriptor %s", a.Address)
+	}
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+func (a Addr) String() string {
+	switch a.Network {
+	case "":
+		return ""
+	case "tcp":
+		return a.Address
+	}
+	return fmt.Sprintf("%s://%s", a.Network, a.Address)
+}
+
+func (a Addr) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Addr) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	v, err := parseAddr(s)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q  %v", s, err)
+	}
+	*a = v
+	return nil
+}
+
+func parseAddr(s string) (Addr, error) {
+	network, address, hasScheme := strings.Cut(s, "://")
+	if !hasScheme {
+		network, address = "tcp", s
+	}
+	if !containsString(addrNetworks, network) {
+		return Addr{}, fmt.Errorf("unknown network %q, expected one of %s", network, strings.Join(addrNetworks, ", "))
+	}
+	switch network {
+	case "unix", "unixpacket":
+		if address == "" {
+			return Addr{}, fmt.Errorf("missing socket path")
+		}
+		return Addr{Network: network, Address: address}, nil
+	case "fd":
+		if fd, err := strconv.Atoi(address); err != nil || fd < 0 {
+			return Addr{}, fmt.Errorf("invalid file descriptor %q", address)
+		}
+		return Addr{Network: network, Address: address}, nil
+	case "systemd":
+		if strings.ContainsAny(address, ":/") {
+			return Addr{}, fmt.Errorf("invalid systemd socket name %q", address)
+		}
+		return Addr{Network: network, Address: address}, nil
+	}
+	if !strings.Contains(address, ":") {
+		address = ":" + address
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return Addr{}, err
+	}
+	var p Port
+	if err := p.UnmarshalText([]byte(port)); err != nil {
+		return Addr{}, err
+	}
+	return Addr{Network: network, Address: net.JoinHostPort(host, p.String())}, nil
+}
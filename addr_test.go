@@ -0,0 +1,61 @@
+package argflags
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddr(t *testing.T) {
+	tests := map[string]Addr{
+		":8080":                    {Network: "tcp", Address: ":8080"},
+		"8080":                     {Network: "tcp", Address: ":8080"},
+		"0.0.0.0:8080":             {Network: "tcp", Address: "0.0.0.0:8080"},
+		"tcp6://[::1]:8080":        {Network: "tcp6", Address: "[::1]:8080"},
+		"unix:///var/run/app.sock": {Network: "unix", Address: "/var/run/app.sock"},
+		"fd://3":                   {Network: "fd", Address: "3"},
+		"systemd://":               {Network: "systemd"},
+		"systemd://web":            {Network: "systemd", Address: "web"},
+	}
+	for s, expect := range tests {
+		var a Addr
+		if err := a.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if a != expect {
+			t.Errorf("%s  expected %+v, got %+v", s, expect, a)
+		}
+	}
+	for _, s := range []string{"udp://:53", "unix://", "fd://x", "fd://-1", ":99999", "systemd://a/b", "host:port"} {
+		var a Addr
+		if err := a.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+	if a := (Addr{Network: "fd", Address: "3"}); a.FD() != 3 || a.IsUnix() || a.String() != "fd://3" {
+		t.Errorf("expected file descriptor 3, got %d, %s", a.FD(), a)
+	}
+	if a := (Addr{Network: "tcp", Address: ":80"}); a.FD() != -1 || a.String() != ":80" {
+		t.Errorf("expected a tcp address without a scheme, got %s", a)
+	}
+}
+
+func TestAddrListen(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "app.sock")
+	for _, s := range []string{"127.0.0.1:0", "unix://" + sock} {
+		var a Addr
+		if err := a.UnmarshalText([]byte(s)); err != nil {
+			t.Fatalf("unexpected error  %v", err)
+		}
+		l, err := a.Listen()
+		if err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if _, ok := l.Addr().(*net.UnixAddr); ok != a.IsUnix() {
+			t.Errorf("%s  expected to listen on %s, got %v", s, a.Network, l.Addr())
+		}
+		_ = l.Close()
+	}
+}
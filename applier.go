@@ -0,0 +1,378 @@
+package argflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// applyTarget is a struct which flags are applied to.
+type applyTarget struct {
+	value reflect.Value
+	// hidden holds the index keys of the fields in the struct which will not accept flags.
+	hidden map[string]bool
+	// inherited is true for the struct of a parent command, which has already been prepared, with BeforeApply, by its own command.
+	inherited bool
+}
+
+// applier applies argument flags to one or more target structs, keeping track of the fields it sets.
+// Each flag is matched to the first target with a matching field.
+type applier struct {
+	p       *Parser
+	targets []applyTarget
+	// stopAt, when set, stops applying at the first non flag argument it returns true for.
+	stopAt func(arg string) bool
+
+	// preset are fields already set, before this applier, which are not missing if required.
+	preset map[fieldKey]bool
+
+	result    *Result
+	applied   []appliedField
+	isApplied map[fieldKey]bool
+	// isFallback are the fields set from a fallback, such as the environment, rather than a flag.
+	isFallback map[fieldKey]bool
+	// remain are the arguments from the argument stopAt stopped at, or empty if it did not stop.
+	remain []string
+	// secretValues are the masked form of the arguments holding secret flag values, keyed by the argument index.
+	secretValues map[int]string
+	// verbatimFrom is the index, in the unused arguments, of the first argument following a '--' terminator.
+	// Arguments from this index are never flags, even if they begin with a dash.
+	verbatimFrom int
+	// isFailed are the fields whose flag failed to set them, which are not also reported as missing.
+	isFailed map[fieldKey]bool
+	// group is the current element of a grouped sub arg, if any, which flags are first matched to.
+	group groupElem
+}
+
+func (p *Parser) newApplier(targets ...applyTarget) *applier {
+	return &applier{
+		p:            p,
+		targets:      targets,
+		result:       &Result{},
+		isApplied:    map[fieldKey]bool{},
+		isFallback:   map[fieldKey]bool{},
+		secretValues: map[int]string{},
+		verbatimFrom: -1,
+		isFailed:     map[fieldKey]bool{},
+	}
+}
+
+// apply applies the given arguments to the targets, then checks and validates the fields which were set.
+// A flag failing to set its field does not stop the remaining flags being applied,
+// all the errors are returned together, so they can all be corrected at once.
+func (a *applier) apply(args []string) error {
+	for _, target := range a.targets {
+		if _, err := a.p.Schema(target.value.Type()); err != nil {
+			return err
+		}
+	}
+	for _, target := range a.targets {
+		if target.inherited {
+			continue
+		}
+		if err := a.p.beforeApply(target.value); err != nil {
+			return err
+		}
+	}
+	var errs []error
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == argsTerminator {
+			// all arguments following the terminator are unused, never flags
+			a.verbatimFrom = len(a.result.Unused)
+			a.result.Unused = append(a.result.Unused, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			if a.stopAt != nil && a.stopAt(arg) {
+				a.remain = args[i:]
+				break
+			}
+			a.result.Unused = append(a.result.Unused, arg)
+			continue
+		}
+		// flags may have their value attached with an '=', e.g. --timeout=30s
+		flag, attached, hasAttached := strings.Cut(arg, "=")
+		fld := a.findFlagField(strings.TrimLeft(flag, "-"))
+		negated := false
+		count := 1
+		if fld == nil {
+			fld = a.findNegatedField(strings.TrimLeft(flag, "-"))
+			negated = fld != nil
+		}
+		if fld == nil {
+			fld, count = a.findCountRun(strings.TrimLeft(flag, "-"))
+		}
+		if letters, attachedValue := a.splitShortFlags(flag); fld == nil && letters != nil {
+			// apply all but the last of the combined flags, the last is applied as any other flag, taking any value.
+			if err := a.applyShortFlags(i, letters[:len(letters)-1]); err != nil {
+				errs = append(errs, err)
+			}
+			flag = "-" + letters[len(letters)-1]
+			fld = a.findFlagField(letters[len(letters)-1])
+			if attachedValue != "" {
+				// the value attached to the letter includes any '='
+				if hasAttached {
+					attachedValue = strings.Join([]string{attachedValue, attached}, "=")
+				}
+				attached, hasAttached = attachedValue, true
+			}
+		}
+		if fld == nil && a.isLongFlag(flag) && a.p.prefixMatching {
+			name, err := a.matchPrefix(flag)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if name != "" {
+				// the flag is known by its full name from here on
+				flag = "--" + name
+				fld = a.findFlagField(name)
+			}
+		}
+		if fld == nil && isHelpFlag(strings.TrimLeft(flag, "-")) {
+			return ErrHelp
+		}
+		if fld == nil && a.p.strict {
+			errs = append(errs, a.unknownFlag(flag))
+			continue
+		}
+		if fld == nil {
+			// no matching field for the flag, ignore it
+			a.result.Unused = append(a.result.Unused, arg)
+			continue
+		}
+		a.result.Instantiated = append(a.result.Instantiated, fld.instantiated...)
+		a.checkDeprecated(flag, fld)
+		if a.p.isGroup(fld.root.Type().FieldByIndex(fld.index)) {
+			if hasAttached {
+				errs = append(errs, a.failed(fld, ErrConversion{Flag: flag, Value: attached, Type: fld.Type(), Err: fmt.Errorf("takes no value")}))
+				continue
+			}
+			if err := a.startGroup(flag, fld); err != nil {
+				errs = append(errs, a.failed(fld, err))
+			}
+			a.setApplied(flag, fld)
+			a.occurred(i, flag, "", fld)
+			continue
+		}
+		if fld.isCount() && !hasAttached {
+			if err := addCount(fld.fldValue, count); err != nil {
+				errs = append(errs, a.failed(fld, ErrConversion{Flag: flag, Type: fld.Type(), Err: err}))
+				continue
+			}
+			a.setApplied(flag, fld)
+			a.occurred(i, flag, "", fld)
+			continue
+		}
+		flagIndex := i
+		var argValue string
+		if negated {
+			if hasAttached {
+				errs = append(errs, a.failed(fld, ErrConversion{Flag: flag, Value: attached, Type: fld.Type(), Err: fmt.Errorf("takes no value")}))
+				continue
+			}
+			argValue = strconv.FormatBool(false)
+		} else if hasAttached {
+			argValue = attached
+			if fld.isSecret() {
+				a.secretValues[i] = strings.Join([]string{flag, secretMask}, "=")
+			}
+		} else {
+			vals := args[i+1:]
+			v, remain, err := findFlagValue(vals, fld.Type())
+			if err != nil {
+				errs = append(errs, a.failed(fld, ErrMissingValue{Flag: flag}))
+				continue
+			}
+			argValue = v
+			if len(remain) < len(vals) && fld.isSecret() {
+				a.secretValues[i+1] = secretMask
+			}
+			// move along args, past any value found (can be zero movement)
+			i += len(vals) - len(remain)
+		}
+		setFunc := a.p.setValue
+		if a.isApplied[keyOfField(fld.fldValue)] && !fld.isReplaced() {
+			// flag given again, add to the values it has already set
+			setFunc = a.p.appendValue
+		}
+		if fs := asFlagSetter(fld.fldValue); fs != nil {
+			name := strings.TrimLeft(flag, "-")
+			setFunc = func(value string, _ reflect.Value) error {
+				return fs.SetFlag(name, value)
+			}
+		}
+		if pm, err := parseMethod(fld.root, fld.index); err != nil {
+			errs = append(errs, a.failed(fld, ErrConversion{Flag: flag, Value: argValue, Type: fld.Type(), Err: err}))
+			continue
+		} else if pm != nil {
+			setFunc = pm
+		}
+		if err := a.p.setTagged(argValue, fld.root.Type().FieldByIndex(fld.index), fld.fldValue, setFunc); err != nil && !a.warned(flag, argValue, fld, err) {
+			errs = append(errs, a.failed(fld, ErrConversion{Flag: flag, Value: argValue, Type: fld.Type(), Err: err}))
+			continue
+		}
+		a.setApplied(flag, fld)
+		a.occurred(flagIndex, flag, argValue, fld)
+	}
+	errs = append(errs, a.bindPositional())
+	a.result.applied = a.applied
+	errs = append(errs, a.p.checkActivations(a.applied))
+	// when stopped, the remaining arguments may yet set any required or defaulted flags
+	if len(a.remain) == 0 {
+		errs = append(errs, a.applyConfig(), a.applyEnv(), a.checkRequired(), a.checkFlagGroups(), a.applyDefaults())
+	}
+	errs = append(errs, validateFields(a.applied))
+	if err := errors.Join(errs...); err != nil || len(a.remain) > 0 {
+		return err
+	}
+	for _, target := range a.targets {
+		if err := a.p.afterApply(target.value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// occurred records the given flag, at the given argument index, as having set the given field to the given value.
+func (a *applier) occurred(argIndex int, flag, value string, fld *flagField) {
+	if value != "" && fld.isSecret() {
+		value = secretMask
+	}
+	a.result.Occurrences = append(a.result.Occurrences, Occurrence{
+		Ordinal: len(a.result.Occurrences),
+		Arg:     argIndex,
+		Flag:    flag,
+		Value:   value,
+		Field:   fld.path(),
+	})
+	a.setFrom(SourceFlag, flag, fld, value)
+}
+
+// setFrom records the given field as set from the given source, by the given name, with the given values.
+// Values are added to those the field has already been set with.
+func (a *applier) setFrom(source Source, name string, fld *flagField, values ...string) {
+	if fld.isSecret() {
+		for i := range values {
+			values[i] = secretMask
+		}
+	}
+	a.p.notifySet(name, strings.Join(values, a.p.delimiter), fld.fldValue)
+	path := fld.path()
+	for i, sf := range a.result.Fields {
+		if sf.Field == path {
+			a.result.Fields[i].Source, a.result.Fields[i].Name = source, name
+			a.result.Fields[i].Values = append(sf.Values, values...)
+			return
+		}
+	}
+	a.result.Fields = append(a.result.Fields, SetField{Field: path, Source: source, Name: name, Values: values})
+}
+
+// warned adds the element errors of a partially set slice to the warnings of the result, returning false for any other error.
+func (a *applier) warned(flag, value string, fld *flagField, err error) bool {
+	var partial ErrPartial
+	if !errors.As(err, &partial) {
+		return false
+	}
+	for _, e := range partial.Errs {
+		a.result.Warnings = append(a.result.Warnings, ErrConversion{Flag: flag, Value: value, Type: fld.Type(), Err: e})
+	}
+	return true
+}
+
+// failed records the given field as failing to be set by its flag, returning the given error.
+// Errors of fields in a grouped sub arg element are returned within an ErrElement, identifying the element.
+func (a *applier) failed(fld *flagField, err error) error {
+	a.isFailed[keyOfField(fld.fldValue)] = true
+	if fld.parent == "" {
+		return err
+	}
+	return ErrConversion{Flag: a.group.flag, Type: a.group.slice.Type(), Err: ErrElement{Index: a.group.index, Err: err}}
+}
+
+// findNegatedField finds the bool field negated by the given 'no-' prefixed flag name, e.g. 'no-verbose' for the 'verbose' field.
+// returns nil if the name is not prefixed with 'no-' or its field is not a bool.
+func (a *applier) findNegatedField(name string) *flagField {
+	base, ok := strings.CutPrefix(name, negatedPrefix)
+	if !ok {
+		return nil
+	}
+	if fld := a.findGroupField(base); fld != nil {
+		if !isBoolType(fld.Type()) {
+			return nil
+		}
+		return fld
+	}
+	for _, target := range a.targets {
+		index := a.p.findFieldIndex(base, target.value.Type(), nil)
+		if len(index) == 0 || target.hidden[indexKey(index)] {
+			continue
+		}
+		// check the type before finding the field, as finding it may instantiate a nil sub arg
+		if !isBoolType(target.value.Type().FieldByIndex(index).Type) {
+			return nil
+		}
+		if fld, err := a.p.newFlagField(base, target.value); err == nil {
+			return fld
+		}
+	}
+	return nil
+}
+
+// unknownFlag gets the error for a flag not found in any target, suggesting the closest flag name, if any are similar.
+func (a *applier) unknownFlag(flag string) error {
+	var names []string
+	for _, target := range a.targets {
+		for _, fd := range a.p.describeFlags(target.value.Type()) {
+			if !target.hidden[indexKey(fd.index)] {
+				names = append(names, fd.names...)
+			}
+		}
+	}
+	return ErrUnknownFlag{Name: flag, Suggestion: suggest(strings.TrimLeft(flag, "-"), names)}
+}
+
+// isVerbatim checks if the unused argument, at the given index, followed a '--' terminator.
+func (a *applier) isVerbatim(i int) bool {
+	return a.verbatimFrom >= 0 && i >= a.verbatimFrom
+}
+
+// findFlagField finds the field for the given flag name in the current grouped sub arg element,
+// or when not found there, in the first target containing it.
+// returns nil if no target has a matching field.
+func (a *applier) findFlagField(name string) *flagField {
+	if fld := a.findGroupField(name); fld != nil {
+		return fld
+	}
+	for _, target := range a.targets {
+		if target.hidden[indexKey(a.p.findFieldIndex(name, target.value.Type(), nil))] {
+			continue
+		}
+		if fld, err := a.p.newFlagField(name, target.value); err == nil {
+			return fld
+		}
+	}
+	return nil
+}
+
+// setApplied records the given field as having been set by the given flag.
+func (a *applier) setApplied(flag string, fld *flagField) {
+	key := keyOfField(fld.fldValue)
+	if a.isApplied[key] {
+		return
+	}
+	a.isApplied[key] = true
+	a.applied = append(a.applied, appliedField{flag: flag, root: fld.root, index: fld.index, field: fld.fldValue})
+}
+
+// indexKey gets a string key for the given field index, or an empty string if the index is empty.
+func indexKey(index []int) string {
+	if len(index) == 0 {
+		return ""
+	}
+	return fmt.Sprint(index)
+}
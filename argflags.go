@@ -16,22 +16,68 @@ import (
 // ColumnNames may be 'tagged' with a 'flag' tag, the value of which is a comma delimited list of flag names to match to.
 // e.g. MyNames []string `flag:"names,n"`    This will match to either the '-names' or '-n' flag value.
 // Slices should be given in the commandline as a quoted, comma delimited list
+// Maps, e.g. Labels map[string]string `flag:"label"`, are given as comma delimited key=value pairs, e.g. '-label app=web,tier=db'
+// A field may be given its own delimiter with a 'delim' tag, e.g. Queries []string `flag:"query" delim:";"`
+// and a delimiter within a value is escaped with a backslash, e.g. '-tag a\,b,c' sets 'a,b' and 'c'.
+// Slices of values containing commas themselves, such as Color, LatLng, CPUSet or LabelSelector, should be given a delim tag.
+// Slice and map flags may be given more than once, each adding its values to those already given.
+// e.g. '-tag a -tag b,c' sets ["a","b","c"] and '-label app=web -label tier=db' sets both labels.
+// To replace the value each time the flag is given, tag the field with the 'replace' option, e.g. `flag:"tag,replace"`
+// Other types may accumulate repeated flags by implementing the Accumulator interface, as Header does.
+// Flags tagged with the 'secret' option, e.g. Password string `flag:"password,secret"` have their values masked,
+// wherever flag values are recorded, such as in the invocation history.
+// Flags tagged with the 'required' option, e.g. Host string `flag:"host,required"` must be given in the arguments,
+// otherwise an error listing all the missing required flags is returned.
+// Fields may be given a default value with a 'default' tag, e.g. Port int `flag:"port" default:"8080"`
+// Defaults are set, in the same way as flag values, on every field whose flag is not given.
+// Fields may also fall back to an environment variable, named with an 'env' tag, e.g. Host string `flag:"host" env:"MYAPP_HOST"`
+// A flag given in the arguments takes precedence over the environment variable, which takes precedence over the default.
+// Fields may also be set from a config file, named by a field tagged with the 'config' option, e.g. Config string `flag:"config,config"`
+// The config file keys are the flag names, its values taking precedence over the default, but not the environment, see LoadConfig.
 // Sub Arguments
 // Subargs are ColumnNames which contain their own Flag fields.
 // When a struct wishes to expose one or more of its fields as flag structs, it uses the sugarg tag:
 // e.g. OtherData *MyStruct `flag:"+"`  Flags will also match with any flag fields in 'OtherData' assuming MyStruct has public fields.
 // Sub arg fields MUST be either a struct or a pointer to a struct.  nil pointers are instanciated when a matching flag is found.
+// A sub arg may be given a prefix, to keep its flags apart from those of other sub args, e.g. DB *DBOpts `flag:"+db"`
+// Its flags are then only matched with the prefix, joined with a '.' or '-', e.g. '-db.host' or '-db-host'.
+// A slice of structs tagged as a prefixed sub arg, e.g. Targets []Target `flag:"+target"`, is a grouped sub arg,
+// each '-target' adding an element, set by the flags following it, e.g. '-target -host a -target -host b'
+// Embedded structs are sub args without a tag, so the flags of an embedded LogOptions are matched as any other flag.
+// To leave a nil sub arg as nil, tag it with the 'preserve-nil' option: e.g. Cache *CacheOpts `flag:"+,preserve-nil"`
+// Flags belonging to a nil, preserve-nil sub arg are then ignored and returned as unused.
+// A sub arg may be activated by another flag, using the 'activatedby' tag, naming the activating flag.
+// e.g. Cache *CacheOpts `flag:"+" activatedby:"cache"`  The CacheOpts flags are then an error, unless -cache is also set.
+// Flags may be made mutually exclusive, or required together, by naming a group in an 'exclusive' or 'together' tag.
+// e.g. JSON bool `flag:"json" exclusive:"output"` and YAML bool `flag:"yaml" exclusive:"output"` can not both be given.
+// Fields may be bound to the non flag arguments, by their position, with an 'arg' tag, e.g. Source string `arg:"0"`
+// A slice field takes all the remaining arguments from its position. e.g. Files []string `arg:"1"`
+// Fields may be described with a 'help' tag, e.g. Port int `flag:"port" help:"the port to listen on"`, shown in the Usage of the struct.
+// Flags are applied with a default policy, for other policies, such as making unknown flags an error,
+// or using a different tag name or delimiter, use a Parser, see NewParser.
 type ArgFlags []string
 
-// String returns the existing arguments as a space delimited list
+// String returns the existing arguments as a space delimited list.
+// Arguments containing spaces, quotes or backslashes are quoted, so the list splits back into the same arguments,
+// with NewArgFlagsFromString.
 func (args ArgFlags) String() string {
-	return strings.Join(args, " ")
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteArg(arg)
+	}
+	return strings.Join(quoted, " ")
 }
 
-// FlagNames returns all the flag names, (arguments beginning with '-') found in the arguments.
+// argsTerminator ends the flags in the arguments. All the arguments following it are unused, even if they begin with a dash.
+const argsTerminator = "--"
+
+// FlagNames returns all the flag names, (arguments beginning with '-') found in the arguments, before any '--' terminator.
 func (args ArgFlags) FlagNames() []string {
 	var names []string
 	for _, arg := range args {
+		if arg == argsTerminator {
+			break
+		}
 		if !strings.HasPrefix(arg, "-") {
 			continue
 		}
@@ -45,52 +91,45 @@ func (args ArgFlags) FlagNames() []string {
 // Field names in the struct are matched to the named flags either directly to the field name or
 // with a tag of 'flags:"one,two,three"'.  Any tag name can match to a flag.
 // Fields should be base types, string, ints, floats, bools etc or slices of those.
+// time.Duration fields are parsed with time.ParseDuration, so take human friendly values such as '30s' or '1h30m'.
 // If a field contains an object supporting the TextUnmarshaler the argument value is passed to that interface.
+// Fields of a type with a parser registered, with RegisterParser, are set with that parser.
+// Fields implementing the standard library flag.Value interface are set with their Set method, called for each occurrence of the flag.
 // in the given arguments, named flags should always have a following argument for the value of the flag, except bool flags.
+// Negative numbers may follow a flag as its value, e.g. '-offset -5', when the field is numeric, or set as a whole value.
+// Alternatively, the value may be attached to the flag with an '=', e.g. '--timeout=30s' or '-name=foo'
+// A '--' argument ends the flags, the arguments following it are returned as unused, as given, and are never applied as flags.
 // Bool flags are defined by the Field in the strurct and can have optional values.
 // Bool flags default to true
 // If a bool flag has a value following it, it is tested to be a bool value (true or false), if not those, its ignored
+// Integer fields tagged with the 'count' option, e.g. Verbose int `flag:"v,count"`, count the times the flag is given,
+// so '-v -v -v' and '-vvv' both set 3.
+// Single letter flags, which are not bool or count flags, may have their value attached to the letter, e.g. '-n5' or '-ofile.txt'.
+// Single letter flags may be combined into one argument, e.g. '-xvf', when CombinedShortFlags is set.
+// Any bool flag may be set to false with its name prefixed with 'no-', e.g. '--no-verbose' sets the 'verbose' field to false.
+// Once all flags are applied, any field set which supports the Validator interface is validated.
+// Validators run concurrently and all their errors are returned together, in the order the flags were given.
+// A flag which fails to set its field does not stop the other flags being applied.
+// The errors of every failed flag are returned together, joined as with errors.Join, along with the unused arguments.
+// When -h or --help is given, and the struct has no field of that name, the Usage of the struct is written to stdout,
+// (or the help output of a Parser, see WithHelpOutput) and ErrHelp is returned.
 func (args ArgFlags) ApplyTo(str interface{}) ([]string, error) {
-	v, err := getStructValue(str)
-	if err != nil {
-		return nil, err
-	}
-	var unused []string
-	var i int
-	for ; i < len(args); i++ {
-		arg := args[i]
-		if !strings.HasPrefix(arg, "-") {
-			unused = append(unused, arg)
-			continue
-		}
-		fld, err := newFlagField(strings.TrimLeft(arg, "-"), *v)
-		if err != nil {
-			// no matching field for the flag, ignore it
-			unused = append(unused, arg)
-			continue
-		}
-		var argValue string
-		vals := args[i+1:]
-		if v, remain, err := findFlagValue(vals, fld.Type()); err != nil {
-			return nil, fmt.Errorf("%s  %v", arg, err)
-		} else {
-			argValue = v
-			// move along args, past any value found (can be zero movement)
-			i += len(vals) - len(remain)
-		}
-		if err := fld.SetValue(argValue); err != nil {
-			return nil, fmt.Errorf("'%s'  %v", arg, err)
-		}
-	}
-	return unused, nil
+	return NewParser().ApplyTo(args, str)
+}
+
+// Apply applies the argument flags to the given struct pointer, in the same way as ApplyTo,
+// returning a Result reporting what was done to the struct.
+// The arguments are applied with a default Parser, see NewParser.
+func (args ArgFlags) Apply(str interface{}) (*Result, error) {
+	return NewParser().Apply(args, str)
 }
 
 func findFlagValue(args []string, fldType reflect.Type) (value string, remain []string, err error) {
-	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+	if len(args) > 0 && (!strings.HasPrefix(args[0], "-") || isNegativeValue(args[0], fldType)) {
 		value = args[0]
 	}
 	// bool flags have optional value.  only used if parsable as bool, otherwise defaults to true and ignores next arg
-	if fldType.Kind() == reflect.Bool {
+	if isBoolType(fldType) {
 		// test if its parsable as bool
 		_, err := strconv.ParseBool(value)
 		if value == "" || err != nil {
@@ -105,6 +144,41 @@ func findFlagValue(args []string, fldType reflect.Type) (value string, remain []
 	return value, args[1:], nil
 }
 
+// negatedPrefix prefixes the name of a bool flag, to set the flag to false.
+const negatedPrefix = "no-"
+
+// isBoolType checks if the given field type is a bool, or a flag.Value which is a bool flag, or a pointer to either.
+func isBoolType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool || isBoolFlag(t)
+}
+
+// isNegativeValue checks if the given argument, beginning with a dash, is a negative number rather than a flag.
+// Arguments are only negative numbers when a digit follows the dash, (or a decimal point and a digit),
+// and the given field type is a number, or slice of numbers, or is set as a whole value, such as a Decimal or Quantity.
+func isNegativeValue(arg string, fldType reflect.Type) bool {
+	num := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), ".")
+	if len(num) == len(arg) || num == "" || num[0] < '0' || num[0] > '9' {
+		return false
+	}
+	t := fldType
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		if isWholeValue(reflect.New(t).Elem()) {
+			return true
+		}
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8,
+		reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8,
+		reflect.Float64, reflect.Float32:
+		return true
+	}
+	return isWholeValue(reflect.New(t).Elem())
+}
+
 func getStructValue(str interface{}) (*reflect.Value, error) {
 	if !isStructPointer(reflect.TypeOf(str)) {
 		return nil, fmt.Errorf("flags can only be applied to a struct pointer")
@@ -0,0 +1,95 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type basicFlags struct {
+	Name    string        `flag:"name,n"`
+	Offset  int           `flag:"offset"`
+	Ratio   float64       `flag:"ratio"`
+	Verbose bool          `flag:"verbose"`
+	Timeout time.Duration `flag:"timeout"`
+	Tags    []string      `flag:"tag"`
+}
+
+func TestAttachedValues(t *testing.T) {
+	var bf basicFlags
+	unused, err := ArgFlags{"--timeout=1m30s", "-name=a=b", "--verbose=false", "-tag=x,y"}.ApplyTo(&bf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := basicFlags{Name: "a=b", Timeout: 90 * time.Second, Tags: []string{"x", "y"}}
+	if !reflect.DeepEqual(bf, expect) || len(unused) != 0 {
+		t.Errorf("expected %+v, got %+v, unused %v", expect, bf, unused)
+	}
+}
+
+func TestArgsTerminator(t *testing.T) {
+	var bf basicFlags
+	unused, err := ArgFlags{"-name", "a", "file", "--", "-verbose", "--"}.ApplyTo(&bf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if bf.Verbose || bf.Name != "a" {
+		t.Errorf("expected flags after -- not to be applied, got %+v", bf)
+	}
+	if !reflect.DeepEqual(unused, []string{"file", "-verbose", "--"}) {
+		t.Errorf("expected the arguments after -- to be unused, got %v", unused)
+	}
+}
+
+func TestNegativeValues(t *testing.T) {
+	var bf basicFlags
+	unused, err := ArgFlags{"-offset", "-5", "-ratio", "-.5", "-name", "-verbose"}.ApplyTo(&bf)
+	if err == nil {
+		t.Errorf("expected -name to be missing its value")
+	}
+	if bf.Offset != -5 || bf.Ratio != -0.5 || !bf.Verbose {
+		t.Errorf("expected -5, -0.5 and verbose, got %+v, unused %v", bf, unused)
+	}
+}
+
+func TestBoolNegation(t *testing.T) {
+	bf := basicFlags{Verbose: true}
+	if _, err := (ArgFlags{"--no-verbose"}).ApplyTo(&bf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if bf.Verbose {
+		t.Errorf("expected --no-verbose to set false")
+	}
+	if _, err := (ArgFlags{"-verbose", "true"}).ApplyTo(&bf); err != nil || !bf.Verbose {
+		t.Errorf("expected -verbose true to set true, got %v  %v", bf.Verbose, err)
+	}
+}
+
+func TestArgFlagsString(t *testing.T) {
+	args := ArgFlags{"-name", "two words", `back\slash`, `"quoted"`}
+	parsed, err := NewArgFlagsFromString(args.String())
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(parsed, args) {
+		t.Errorf("expected %q, got %q from %s", args, parsed, args.String())
+	}
+}
+
+func TestDurations(t *testing.T) {
+	var flags struct {
+		Timeout  time.Duration   `flag:"timeout"`
+		Backoffs []time.Duration `flag:"backoff"`
+	}
+	if _, err := (ArgFlags{"-timeout", "1h30m", "-backoff", "100ms,2s"}).ApplyTo(&flags); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if flags.Timeout != 90*time.Minute || !reflect.DeepEqual(flags.Backoffs, []time.Duration{100 * time.Millisecond, 2 * time.Second}) {
+		t.Errorf("expected the durations to be parsed, got %+v", flags)
+	}
+	for _, s := range []string{"5", "1 hour", "1.5.s"} {
+		if _, err := (ArgFlags{"-timeout", s}).ApplyTo(&flags); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package argflags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Region is an AWS region name, such as 'us-east-1', 'eu-central-2' or 'us-gov-west-1'.
+// Regions are validated by their format, '<area>[-gov|-iso*]-<direction>-<number>', so new regions need no update.
+type Region string
+
+// Partition gets the partition the region belongs to, e.g. 'aws', 'aws-cn' or 'aws-us-gov'.
+func (r Region) Partition() string {
+	s := string(r)
+	switch {
+	case strings.HasPrefix(s, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(s, "us-gov-"):
+		return "aws-us-gov"
+	case strings.HasPrefix(s, "us-isob-"):
+		return "aws-iso-b"
+	case strings.HasPrefix(s, "us-iso-"):
+		return "aws-iso"
+	}
+	return "aws"
+}
+
+func (r Region) String() string {
+	return string(r)
+}
+
+func (r *Region) UnmarshalText(text []byte) error {
+	s := strings.ToLower(strings.TrimSpace(string(text)))
+	if !isRegionName(s) {
+		return fmt.Errorf("invalid region %q, expected a region such as 'us-east-1'", s)
+	}
+	*r = Region(s)
+	return nil
+}
+
+// isRegionName checks the given name is in the form of a region name.
+// A two letter area, an optional gov or iso qualifier, a direction and a number. e.g. 'us-gov-west-1'
+func isRegionName(s string) bool {
+	parts := strings.Split(s, "-")
+	if len(parts) < 3 || len(parts) > 4 {
+		return false
+	}
+	if len(parts[0]) != 2 || !isLowerAlpha(parts[0]) {
+		return false
+	}
+	if len(parts) == 4 && parts[1] != "gov" && !strings.HasPrefix(parts[1], "iso") {
+		return false
+	}
+	direction, number := parts[len(parts)-2], parts[len(parts)-1]
+	if direction == "" || !isLowerAlpha(direction) || number == "" {
+		return false
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isLowerAlpha(s string) bool {
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ARN is an Amazon Resource Name, 'arn:partition:service:region:account-id:resource'
+// The region and account may be empty, for global services, such as 'arn:aws:s3:::my-bucket'
+// The resource may contain its own colons or slashes, e.g. 'function:my-func:1' or 'role/admin'.
+type ARN struct {
+	Partition string
+	Service   string
+	Region    string
+	AccountID string
+	Resource  string
+}
+
+// ResourceType gets the type of the resource, the part of the resource before the first ':' or '/', if it has one.
+// e.g. 'role' from 'role/admin'.
+func (a ARN) ResourceType() string {
+	if i := strings.IndexAny(a.Resource, ":/"); i >= 0 {
+		return a.Resource[:i]
+	}
+	return ""
+}
+
+// ResourceID gets the resource, without its resource type. e.g. 'admin' from 'role/admin'.
+func (a ARN) ResourceID() string {
+	if i := strings.IndexAny(a.Resource, ":/"); i >= 0 {
+		return a.Resource[i+1:]
+	}
+	return a.Resource
+}
+
+func (a ARN) String() string {
+	if a == (ARN{}) {
+		return ""
+	}
+	return strings.Join([]string{"arn", a.Partition, a.Service, a.Region, a.AccountID, a.Resource}, ":")
+}
+
+func (a ARN) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *ARN) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	parts := strings.SplitN(s, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return fmt.Errorf("invalid ARN %q, expected 'arn:partition:service:region:account-id:resource'", s)
+	}
+	v := ARN{Partition: parts[1], Service: parts[2], Region: parts[3], AccountID: parts[4], Resource: parts[5]}
+	if v.Partition == "" || v.Service == "" || v.Resource == "" {
+		return fmt.Errorf("invalid ARN %q, partition, service and resource are required", s)
+	}
+	if v.Partition != "aws" && !strings.HasPrefix(v.Partition, "aws-") {
+		return fmt.Errorf("invalid ARN %q, unknown partition %q", s, v.Partition)
+	}
+	if v.Region != "" && !isRegionName(v.Region) {
+		return fmt.Errorf("invalid ARN %q, invalid region %q", s, v.Region)
+	}
+	if v.AccountID != "" && (len(v.AccountID) != 12 || strings.Trim(v.AccountID, "0123456789") != "") {
+		return fmt.Errorf("invalid ARN %q, account id must be 12 digits", s)
+	}
+	*a = v
+	return nil
+}
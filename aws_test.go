@@ -0,0 +1,47 @@
+package argflags
+
+import "testing"
+
+func TestRegion(t *testing.T) {
+	tests := map[string]string{
+		"us-east-1":     "aws",
+		"EU-Central-2":  "aws",
+		"cn-north-1":    "aws-cn",
+		"us-gov-west-1": "aws-us-gov",
+		"us-iso-east-1": "aws-iso",
+	}
+	for s, partition := range tests {
+		var r Region
+		if err := r.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if r.Partition() != partition {
+			t.Errorf("%s  expected partition %s, got %s", s, partition, r.Partition())
+		}
+	}
+	for _, s := range []string{"us-east", "useast-1", "us-foo-east-1", "us-east-x", "us-1-1"} {
+		var r Region
+		if err := r.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestARN(t *testing.T) {
+	var a ARN
+	if err := a.UnmarshalText([]byte("arn:aws:lambda:us-east-1:123456789012:function:my-func:1")); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if a.Service != "lambda" || a.AccountID != "123456789012" || a.ResourceType() != "function" || a.ResourceID() != "my-func:1" {
+		t.Errorf("expected the resource to keep its colons, got %+v", a)
+	}
+	if err := a.UnmarshalText([]byte("arn:aws:s3:::my-bucket")); err != nil || a.ResourceType() != "" || a.String() != "arn:aws:s3:::my-bucket" {
+		t.Errorf("expected a global resource, got %+v, %v", a, err)
+	}
+	for _, s := range []string{"aws:s3:::b", "arn:aws:s3:::", "arn:gcp:s3:::b", "arn:aws:s3:nowhere::b", "arn:aws:iam::123:role/x"} {
+		if err := a.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package argflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Request is a command invocation, in a form which can be sent as JSON.
+// It allows a daemon to accept the same operations over a socket, that the command accepts as command line arguments.
+// e.g. {"command": "remote add", "options": {"name": "origin", "fetch": true}, "args": ["https://example.com"]}
+type Request struct {
+	// Command is the space delimited path of the sub command to invoke, not including the root command name.
+	Command string `json:"command"`
+	// Options are the flag values, keyed by flag name.
+	// Values may be strings, numbers, bools or arrays of those, which are applied as lists delimited with the delimiter of their flag.
+	Options map[string]interface{} `json:"options,omitempty"`
+	// Args are the arguments following the flags.
+	Args []string `json:"args,omitempty"`
+}
+
+// Response is the outcome of executing a Request.
+type Response struct {
+	// Output is anything written by the command to the Output writer.
+	Output string `json:"output,omitempty"`
+	// Error is the error message of a failed command, empty when it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+type outputKey struct{}
+
+// Output gets the writer a command handler should write its output to.
+// When executing a Request, output is captured in the response, otherwise it is os.Stdout.
+func Output(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(outputKey{}).(io.Writer); ok {
+		return w
+	}
+	return os.Stdout
+}
+
+// ArgFlags gets the command line arguments equivalent to the request.
+// Options are given in name order, following the command, with their values attached, e.g. '-name=value', other than true bools.
+// Arrays are joined with the default delimiter, a comma.  ExecuteRequest joins them with the delimiter of their flag.
+func (r Request) ArgFlags() (ArgFlags, error) {
+	return r.argFlags(func(string) string {
+		return sliceDelimiter
+	})
+}
+
+// argFlags gets the command line arguments equivalent to the request, joining arrays with the delimiter of their option name.
+func (r Request) argFlags(delimiterOf func(name string) string) (ArgFlags, error) {
+	args := ArgFlags(strings.Fields(r.Command))
+	names := make([]string, 0, len(r.Options))
+	for name := range r.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, err := requestOptionValue(r.Options[name], delimiterOf(name))
+		if err != nil {
+			return nil, fmt.Errorf("option %s  %v", name, err)
+		}
+		if v, ok := r.Options[name].(bool); ok && v {
+			args = append(args, "-"+name)
+			continue
+		}
+		// attached, so empty values and values beginning with a dash are not mistaken for missing values or flags
+		args = append(args, strings.Join([]string{"-" + name, value}, "="))
+	}
+	return append(args, r.Args...), nil
+}
+
+func requestOptionValue(v interface{}, delim string) (string, error) {
+	switch vt := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return vt, nil
+	case json.Number:
+		return vt.String(), nil
+	case bool, float64:
+		return fmt.Sprint(vt), nil
+	case []interface{}:
+		ss := make([]string, len(vt))
+		for i, e := range vt {
+			s, err := requestOptionValue(e, delim)
+			if err != nil {
+				return "", err
+			}
+			ss[i] = s
+		}
+		return joinDelimited(ss, delim), nil
+	default:
+		return "", fmt.Errorf("unsupported option value %v", v)
+	}
+}
+
+// ExecuteRequest executes the given request on this command, capturing its output in the Response.
+func (c *Command) ExecuteRequest(ctx context.Context, req Request) Response {
+	args, err := req.argFlags(c.requestDelimiter(req.Command))
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	buf := &bytes.Buffer{}
+	err = c.Execute(context.WithValue(ctx, outputKey{}, buf), args)
+	resp := Response{Output: buf.String()}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+// requestDelimiter gets a func to find the delimiter of a flag name, for the sub command of the given path.
+// The flag is looked for in the sub command and its parents, using the delimiter of the sub commands parser,
+// or the delim tag of the flag.  Unknown flags use the default delimiter.
+func (c *Command) requestDelimiter(path string) func(name string) string {
+	cmd := c
+	for _, name := range strings.Fields(path) {
+		sub := cmd.Command(name)
+		if sub == nil {
+			break
+		}
+		cmd = sub
+	}
+	p := cmd.parser()
+	return func(name string) string {
+		for pc := cmd; pc != nil; pc = pc.parent {
+			if pc.Flags == nil || !isStructPointer(reflect.TypeOf(pc.Flags)) {
+				continue
+			}
+			t := reflect.TypeOf(pc.Flags).Elem()
+			if index := p.findFieldIndex(name, t, nil); len(index) > 0 {
+				return p.delimiterOf(t.FieldByIndex(index))
+			}
+		}
+		return sliceDelimiter
+	}
+}
+
+// ServeJSON accepts connections on the given listener, reading JSON Requests from each and executing them on this command.
+// Each request is answered with a JSON Response on the same connection.
+// Requests are executed one at a time, with the flags of every command reset to their initial values before each one.
+// ServeJSON returns when the context is done, or the listener fails.
+func (c *Command) ServeJSON(ctx context.Context, l net.Listener) error {
+	initial := c.flagSnapshots()
+	var mu sync.Mutex
+	execute := func(req Request) Response {
+		mu.Lock()
+		defer mu.Unlock()
+		initial.restore()
+		return c.ExecuteRequest(ctx, req)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		go serveJSONConn(conn, execute)
+	}
+}
+
+func serveJSONConn(conn net.Conn, execute func(req Request) Response) {
+	defer func(conn net.Conn) {
+		_ = conn.Close()
+	}(conn)
+	dec := json.NewDecoder(conn)
+	dec.UseNumber()
+	enc := json.NewEncoder(conn)
+	for {
+		var req Request
+		if err := dec.Decode(&req); err != nil {
+			if !errors.Is(err, io.EOF) {
+				_ = enc.Encode(Response{Error: err.Error()})
+			}
+			return
+		}
+		if err := enc.Encode(execute(req)); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package argflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestServeJSON(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("can not listen  %v", err)
+	}
+	sf := &serveFlags{}
+	root := &Command{Name: "app"}
+	root.AddCommand(&Command{Name: "serve", Flags: sf, Handler: func(ctx context.Context, inv *Invocation) error {
+		_, err := fmt.Fprintf(Output(ctx), "port %d %v", sf.Port, inv.Args)
+		return err
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- root.ServeJSON(ctx, l)
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(conn net.Conn) {
+		_ = conn.Close()
+	}(conn)
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+	for _, tc := range []struct {
+		req  string
+		want Response
+	}{
+		{`{"command":"serve","options":{"port":80},"args":["a"]}`, Response{Output: "port 80 [a]"}},
+		{`{"command":"serve"}`, Response{Output: "port 0 []"}},
+		{`{"command":"serv"}`, Response{Error: ErrUnknownCommand{Name: "serv", Suggestion: "serve"}.Error()}},
+	} {
+		if err := enc.Encode(json.RawMessage(tc.req)); err != nil {
+			t.Fatal(err)
+		}
+		var resp Response
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp != tc.want {
+			t.Errorf("%s expected %+v, got %+v", tc.req, tc.want, resp)
+		}
+	}
+	cancel()
+	if err := <-served; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the server to end with the context, got %v", err)
+	}
+}
+
+func TestRequestOptionValues(t *testing.T) {
+	type noteFlags struct {
+		Title   string   `flag:"title" default:"untitled"`
+		Note    string   `flag:"note"`
+		Verbose bool     `flag:"verbose"`
+		Quiet   bool     `flag:"quiet" default:"true"`
+		Tags    []string `flag:"tag"`
+		Offset  int      `flag:"offset"`
+	}
+	var req Request
+	if err := json.Unmarshal([]byte(`{"command":"note","options":{"title":"","note":"-x","verbose":true,"quiet":false,"tag":["a","-b"],"offset":-5}}`), &req); err != nil {
+		t.Fatal(err)
+	}
+	args, err := req.ArgFlags()
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if args.String() != "note -note=-x -offset=-5 -quiet=false -tag=a,-b -title= -verbose" {
+		t.Errorf("expected the values attached to their flags, got %v", args)
+	}
+	nf := &noteFlags{}
+	root := &Command{Name: "app"}
+	root.AddCommand(&Command{Name: "note", Flags: nf, Handler: func(ctx context.Context, inv *Invocation) error {
+		return nil
+	}})
+	if resp := root.ExecuteRequest(context.Background(), req); resp.Error != "" {
+		t.Fatalf("unexpected error  %s", resp.Error)
+	}
+	expect := noteFlags{Note: "-x", Verbose: true, Tags: []string{"a", "-b"}, Offset: -5}
+	if !reflect.DeepEqual(*nf, expect) {
+		t.Errorf("expected %+v, got %+v", expect, *nf)
+	}
+	req.Options = map[string]interface{}{"note": nil}
+	if resp := root.ExecuteRequest(context.Background(), req); resp.Error != "" || nf.Note != "" {
+		t.Errorf("expected a null option to set an empty value, got %+v, %q", resp, nf.Note)
+	}
+}
@@ -0,0 +1,282 @@
+package argflags
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Validate checks the flag tags of the given struct, or struct pointer, without applying any arguments, so mistakes in the
+// tags can be caught by a unit test, rather than when the flags are applied.
+// It checks for flag names declared by more than one field, including those in sub args, fields of unsupported types,
+// malformed flag, positional, range, min, max, activatedby and default tags, tag options on fields of the wrong type,
+// exclusive and together groups of a single flag, and sub arg fields which are not structs.  The structs of command fields are also checked.
+// All the mistakes found are returned together.
+func Validate(str interface{}) error {
+	return NewParser().Validate(str)
+}
+
+// Validate checks the flag tags of the given struct, or struct pointer, as named by the parser.  See Validate.
+func (p *Parser) Validate(str interface{}) error {
+	t := reflect.TypeOf(str)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("flags can only be validated on a struct or struct pointer")
+	}
+	return errors.Join(p.checkType(t, map[reflect.Type]bool{})...)
+}
+
+// checkType checks the given struct type, and the types of its command fields.
+// checked holds the types already checked, so command structs used more than once are only checked once.
+func (p *Parser) checkType(t reflect.Type, checked map[reflect.Type]bool) []error {
+	if checked[t] {
+		return nil
+	}
+	checked[t] = true
+	var errs []error
+	if _, err := p.Schema(t); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, p.checkFields(t, t, "", "", map[string]string{}, map[reflect.Type]bool{})...)
+	for _, tagName := range []string{ExclusiveTagName, TogetherTagName} {
+		for _, g := range p.flagGroups(t, tagName) {
+			if len(g.flags) < 2 {
+				errs = append(errs, fmt.Errorf("%s group %q in %s has the single flag -%s", tagName, g.name, t.String(), g.flags[0].names[0]))
+			}
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !isCommandField(f) {
+			continue
+		}
+		ct := f.Type
+		if ct.Kind() == reflect.Ptr {
+			ct = ct.Elem()
+		}
+		if ct.Kind() != reflect.Struct {
+			errs = append(errs, fmt.Errorf("command field %s in %s is not a struct or pointer to a struct", f.Name, t.String()))
+			continue
+		}
+		errs = append(errs, p.checkType(ct, checked)...)
+	}
+	return errs
+}
+
+// checkFields checks the fields of the given struct type, and of its sub args, within the given root struct.
+// path is the dot delimited path of field names to the struct, from the root.
+// prefix is the prefix, with its separator, of the prefixed sub args the struct is within.
+// declared maps the name key of each flag name, already declared, to the path of the field declaring it.
+func (p *Parser) checkFields(root, t reflect.Type, path, prefix string, declared map[string]string, visiting map[reflect.Type]bool) []error {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var errs []error
+	if _, err := positionalFields(t); err != nil {
+		errs = append(errs, err)
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || isCommandField(f) {
+			continue
+		}
+		fieldPath := f.Name
+		if path != "" {
+			fieldPath = strings.Join([]string{path, f.Name}, ".")
+		}
+		fieldErr := func(format string, args ...interface{}) {
+			errs = append(errs, fmt.Errorf("field %s in %s %s", fieldPath, root.String(), fmt.Sprintf(format, args...)))
+		}
+		tags := strings.Split(f.Tag.Get(p.tagName), ",")
+		if p.isGroup(f) {
+			// the flags of each element are apart from the other flags, only its sentinel flag is declared with them
+			name := subArgPrefix(tags)
+			if name == "" {
+				// reported by the schema
+				continue
+			}
+			if other, ok := declared[p.nameKey(prefix+name)]; ok {
+				fieldErr("declares the flag -%s, already declared by %s", prefix+name, other)
+			} else {
+				declared[p.nameKey(prefix+name)] = fieldPath
+			}
+			if st := groupElemType(f.Type); !visiting[st] {
+				errs = append(errs, p.checkFields(root, st, fieldPath, "", map[string]string{}, visiting)...)
+			}
+			continue
+		}
+		if p.isSubArg(f) {
+			if !isSubArgType(f.Type) {
+				// reported by the schema
+				continue
+			}
+			if activator, ok := f.Tag.Lookup(ActivationTagName); ok {
+				name, _, _ := strings.Cut(activator, "=")
+				if len(p.findFieldIndex(name, root, nil)) == 0 {
+					fieldErr("is activated by -%s, which is not a flag", name)
+				}
+			}
+			st := f.Type
+			if st.Kind() == reflect.Ptr {
+				st = st.Elem()
+			}
+			subPrefix := prefix
+			if sp := subArgPrefix(tags); sp != "" {
+				subPrefix = prefix + sp + subArgSeparators[0]
+			}
+			if !visiting[st] {
+				errs = append(errs, p.checkFields(root, st, fieldPath, subPrefix, declared, visiting)...)
+			}
+			continue
+		}
+		if _, hasParser := f.Tag.Lookup(ParserTagName); !hasParser && !isSupportedType(f.Type) {
+			fieldErr("has the unsupported type %s", f.Type.String())
+			continue
+		}
+		if p.isPositionalOnly(f) || tags[0] == "-" {
+			continue
+		}
+		var names []string
+		for _, tag := range tags {
+			if isTagOption(tag) {
+				continue
+			}
+			tag = strings.TrimSuffix(tag, deprecatedSuffix)
+			if strings.HasPrefix(tag, "-") || strings.ContainsAny(tag, "= \t") {
+				fieldErr("has an invalid flag name %q", tag)
+				continue
+			}
+			names = append(names, tag)
+		}
+		if len(names) == 0 {
+			names = []string{f.Name}
+		}
+		for _, name := range names {
+			name = prefix + name
+			key := p.nameKey(name)
+			if other, ok := declared[key]; ok {
+				fieldErr("declares the flag -%s, already declared by %s", name, other)
+				continue
+			}
+			declared[key] = fieldPath
+		}
+		p.checkFieldTags(f, fieldErr)
+		if err := checkParseMethod(t, f); err != nil {
+			fieldErr("%v", err)
+		}
+	}
+	return errs
+}
+
+// checkRangeTags checks the range, min and max tags of the given field are valid numbers, with the min no greater than the max.
+func (p *Parser) checkRangeTags(f reflect.StructField, fieldErr func(format string, args ...interface{})) {
+	if _, isNumber := ratOf(reflect.New(elemType(f.Type)).Elem()); !isNumber {
+		fieldErr("has a %s, %s or %s tag, but is not a number", RangeTagName, MinTagName, MaxTagName)
+		return
+	}
+	base := 10
+	if p.isOctal(f) {
+		base = 8
+	}
+	if rng, ok := f.Tag.Lookup(RangeTagName); ok {
+		if _, err := parseRange(rng, base); err != nil {
+			fieldErr("has an %v", err)
+		}
+	}
+	var bounds [2]*big.Rat
+	for i, name := range []string{MinTagName, MaxTagName} {
+		bound, ok := f.Tag.Lookup(name)
+		if !ok {
+			continue
+		}
+		b, err := parseBound(bound, base)
+		if err != nil {
+			fieldErr("has an invalid %s tag %q  %v", name, bound, err)
+			continue
+		}
+		bounds[i] = b
+	}
+	if bounds[0] != nil && bounds[1] != nil && bounds[0].Cmp(bounds[1]) > 0 {
+		fieldErr("has a %s tag greater than its %s tag", MinTagName, MaxTagName)
+	}
+}
+
+// checkFieldTags checks the tag options, range, env and default tags of the given flag field are valid for its type.
+// Mistakes found are reported to the given fieldErr.
+func (p *Parser) checkFieldTags(f reflect.StructField, fieldErr func(format string, args ...interface{})) {
+	if p.hasTagOption(f, optCount) && !isIntegerType(f.Type) {
+		fieldErr("is tagged as a count, but is not an integer")
+	}
+	for _, opt := range []string{optOctal, optUmask, optMode, optUlimit} {
+		if p.hasTagOption(f, opt) && !isIntegerType(f.Type) {
+			fieldErr("is tagged as %s, but is not an integer", opt)
+		}
+	}
+	if hasNumericRange(f) {
+		p.checkRangeTags(f, fieldErr)
+	}
+	if pattern, ok := f.Tag.Lookup(PatternTagName); ok {
+		if !isTransformable(f.Type) {
+			fieldErr("has a %s tag, but is not a string", PatternTagName)
+		} else if _, err := compilePattern(pattern); err != nil {
+			fieldErr("has an invalid %s tag  %v", PatternTagName, err)
+		}
+	}
+	if hasTransform(f) {
+		if !isTransformable(f.Type) {
+			fieldErr("has a %s tag, but is not a string", TransformTagName)
+		} else if _, err := transformsOf(f); err != nil {
+			fieldErr("has an %v", err)
+		}
+	}
+	if hasLengthLimit(f) {
+		if _, ok := lengthOf(reflect.New(f.Type).Elem()); !ok {
+			fieldErr("has a length limit, but is not a string, slice or map")
+		} else if min, max, err := lengthLimits(f); err != nil {
+			fieldErr("has an %v", err)
+		} else if max >= 0 && min > max {
+			fieldErr("has a %s of %d, greater than its %s of %d", MinLenTagName, min, MaxLenTagName, max)
+		}
+	}
+	if d, ok := f.Tag.Lookup(DelimTagName); ok {
+		if d == "" {
+			fieldErr("has an empty %s tag", DelimTagName)
+		} else if !isDelimitedType(f.Type) {
+			fieldErr("has a %s tag, but is not a slice or map", DelimTagName)
+		} else if strings.Contains(d, `\`) {
+			fieldErr("has a %s tag containing a backslash", DelimTagName)
+		}
+	}
+	if name, ok := f.Tag.Lookup(EnvTagName); ok && name == "" {
+		fieldErr("has an empty %s tag", EnvTagName)
+	}
+	if def, ok := f.Tag.Lookup(DefaultTagName); ok {
+		if err := p.setTagged(def, f, reflect.New(f.Type).Elem(), p.setValue); err != nil {
+			fieldErr("has an invalid default %q  %v", def, err)
+		}
+	}
+}
+
+// isSupportedType checks if the given type can be set from a flag value.
+func isSupportedType(t reflect.Type) bool {
+	if parserOf(t) != nil || t.Implements(textUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) ||
+		t.Implements(flagValueType) || reflect.PtrTo(t).Implements(flagValueType) || reflect.PtrTo(t).Implements(flagSetterType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		return isSupportedType(t.Elem())
+	case reflect.Map:
+		return isSupportedType(t.Key()) && isSupportedType(t.Elem())
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8,
+		reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8,
+		reflect.Float64, reflect.Float32:
+		return true
+	}
+	return false
+}
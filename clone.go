@@ -0,0 +1,83 @@
+package argflags
+
+import "reflect"
+
+// Clone gets a deep copy of the given value, usually an option struct, or a pointer to one.
+// Pointers, slices, maps and interfaces are copied along with what they refer to, so the copy shares nothing with the original
+// and either may be changed, or have flags applied to it, without changing the other.
+// Pointers shared within the value remain shared within the copy, including those which refer back to the value.
+// Unexported fields can not be copied deeply, so are copied as they are, sharing anything they refer to.
+// e.g. Clone a set of defaults for each profile: opts := argflags.Clone(defaults)
+func Clone[T any](v T) T {
+	cp := cloneValue(reflect.ValueOf(&v).Elem())
+	return *cp.Addr().Interface().(*T)
+}
+
+// cloneValue gets an addressable deep copy of the given value.
+func cloneValue(v reflect.Value) reflect.Value {
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(deepCopy(v, map[uintptr]reflect.Value{}))
+	return cp
+}
+
+// deepCopy gets a copy of the given value, copying what its pointers, slices, maps and interfaces refer to,
+// rather than sharing them with the given value.
+// Unexported struct fields are copied as they are, so anything they refer to is shared.
+// pointers maps the pointers already copied to their copy, so shared and cyclic pointers are copied once.
+func deepCopy(v reflect.Value, pointers map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if cp, ok := pointers[v.Pointer()]; ok && cp.Type() == v.Type() {
+			return cp
+		}
+		cp := reflect.New(v.Type().Elem())
+		pointers[v.Pointer()] = cp
+		cp.Elem().Set(deepCopy(v.Elem(), pointers))
+		return cp
+	case reflect.Struct:
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				cp.Field(i).Set(deepCopy(v.Field(i), pointers))
+			}
+		}
+		return cp
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Cap())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(deepCopy(v.Index(i), pointers))
+		}
+		return cp
+	case reflect.Array:
+		cp := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(deepCopy(v.Index(i), pointers))
+		}
+		return cp
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			cp.SetMapIndex(iter.Key(), deepCopy(iter.Value(), pointers))
+		}
+		return cp
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(deepCopy(v.Elem(), pointers))
+		return cp
+	}
+	return v
+}
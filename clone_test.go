@@ -0,0 +1,37 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+type cloneFlags struct {
+	Tags   []string          `flag:"tag"`
+	Labels map[string]string `flag:"label"`
+	DB     *dbOpts           `flag:"+db"`
+}
+
+func TestClone(t *testing.T) {
+	defaults := cloneFlags{Tags: []string{"a"}, Labels: map[string]string{"k": "v"}, DB: &dbOpts{Host: "h"}}
+	cp := Clone(defaults)
+	if !reflect.DeepEqual(cp, defaults) {
+		t.Fatalf("expected an equal copy, got %+v", cp)
+	}
+	if _, err := NewParser().Apply([]string{"-tag", "b", "-label", "x=y", "-db.host", "other"}, &cp); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if defaults.Tags[0] != "a" || len(defaults.Labels) != 1 || defaults.DB.Host != "h" {
+		t.Errorf("expected the original to be unchanged, got %+v, %+v", defaults, defaults.DB)
+	}
+}
+
+func TestAtomicApply(t *testing.T) {
+	cf := cloneFlags{Tags: []string{"a"}}
+	_, err := NewParser(WithAtomic(true)).Apply([]string{"-tag", "b", "-db-port", "x"}, &cf)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !reflect.DeepEqual(cf, cloneFlags{Tags: []string{"a"}}) {
+		t.Errorf("expected nothing to be set when a flag fails, got %+v", cf)
+	}
+}
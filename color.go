@@ -0,0 +1,165 @@
+package argflags
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// namedColors are the CSS named colors, as 0xRRGGBB.
+var namedColors = map[string]uint32{
+	"aliceblue": 0xf0f8ff, "antiquewhite": 0xfaebd7, "aqua": 0x00ffff, "aquamarine": 0x7fffd4,
+	"azure": 0xf0ffff, "beige": 0xf5f5dc, "bisque": 0xffe4c4, "black": 0x000000,
+	"blanchedalmond": 0xffebcd, "blue": 0x0000ff, "blueviolet": 0x8a2be2, "brown": 0xa52a2a,
+	"burlywood": 0xdeb887, "cadetblue": 0x5f9ea0, "chartreuse": 0x7fff00, "chocolate": 0xd2691e,
+	"coral": 0xff7f50, "cornflowerblue": 0x6495ed, "cornsilk": 0xfff8dc, "crimson": 0xdc143c,
+	"cyan": 0x00ffff, "darkblue": 0x00008b, "darkcyan": 0x008b8b, "darkgoldenrod": 0xb8860b,
+	"darkgray": 0xa9a9a9, "darkgreen": 0x006400, "darkgrey": 0xa9a9a9, "darkkhaki": 0xbdb76b,
+	"darkmagenta": 0x8b008b, "darkolivegreen": 0x556b2f, "darkorange": 0xff8c00, "darkorchid": 0x9932cc,
+	"darkred": 0x8b0000, "darksalmon": 0xe9967a, "darkseagreen": 0x8fbc8f, "darkslateblue": 0x483d8b,
+	"darkslategray": 0x2f4f4f, "darkslategrey": 0x2f4f4f, "darkturquoise": 0x00ced1, "darkviolet": 0x9400d3,
+	"deeppink": 0xff1493, "deepskyblue": 0x00bfff, "dimgray": 0x696969, "dimgrey": 0x696969,
+	"dodgerblue": 0x1e90ff, "firebrick": 0xb22222, "floralwhite": 0xfffaf0, "forestgreen": 0x228b22,
+	"fuchsia": 0xff00ff, "gainsboro": 0xdcdcdc, "ghostwhite": 0xf8f8ff, "gold": 0xffd700,
+	"goldenrod": 0xdaa520, "gray": 0x808080, "green": 0x008000, "greenyellow": 0xadff2f,
+	"grey": 0x808080, "honeydew": 0xf0fff0, "hotpink": 0xff69b4, "indianred": 0xcd5c5c,
+	"indigo": 0x4b0082, "ivory": 0xfffff0, "khaki": 0xf0e68c, "lavender": 0xe6e6fa,
+	"lavenderblush": 0xfff0f5, "lawngreen": 0x7cfc00, "lemonchiffon": 0xfffacd, "lightblue": 0xadd8e6,
+	"lightcoral": 0xf08080, "lightcyan": 0xe0ffff, "lightgoldenrodyellow": 0xfafad2, "lightgray": 0xd3d3d3,
+	"lightgreen": 0x90ee90, "lightgrey": 0xd3d3d3, "lightpink": 0xffb6c1, "lightsalmon": 0xffa07a,
+	"lightseagreen": 0x20b2aa, "lightskyblue": 0x87cefa, "lightslategray": 0x778899, "lightslategrey": 0x778899,
+	"lightsteelblue": 0xb0c4de, "lightyellow": 0xffffe0, "lime": 0x00ff00, "limegreen": 0x32cd32,
+	"linen": 0xfaf0e6, "magenta": 0xff00ff, "maroon": 0x800000, "mediumaquamarine": 0x66cdaa,
+	"mediumblue": 0x0000cd, "mediumorchid": 0xba55d3, "mediumpurple": 0x9370db, "mediumseagreen": 0x3cb371,
+	"mediumslateblue": 0x7b68ee, "mediumspringgreen": 0x00fa9a, "mediumturquoise": 0x48d1cc, "mediumvioletred": 0xc71585,
+	"midnightblue": 0x191970, "mintcream": 0xf5fffa, "mistyrose": 0xffe4e1, "moccasin": 0xffe4b5,
+	"navajowhite": 0xffdead, "navy": 0x000080, "oldlace": 0xfdf5e6, "olive": 0x808000,
+	"olivedrab": 0x6b8e23, "orange": 0xffa500, "orangered": 0xff4500, "orchid": 0xda70d6,
+	"palegoldenrod": 0xeee8aa, "palegreen": 0x98fb98, "paleturquoise": 0xafeeee, "palevioletred": 0xdb7093,
+	"papayawhip": 0xffefd5, "peachpuff": 0xffdab9, "peru": 0xcd853f, "pink": 0xffc0cb,
+	"plum": 0xdda0dd, "powderblue": 0xb0e0e6, "purple": 0x800080, "rebeccapurple": 0x663399,
+	"red": 0xff0000, "rosybrown": 0xbc8f8f, "royalblue": 0x4169e1, "saddlebrown": 0x8b4513,
+	"salmon": 0xfa8072, "sandybrown": 0xf4a460, "seagreen": 0x2e8b57, "seashell": 0xfff5ee,
+	"sienna": 0xa0522d, "silver": 0xc0c0c0, "skyblue": 0x87ceeb, "slateblue": 0x6a5acd,
+	"slategray": 0x708090, "slategrey": 0x708090, "snow": 0xfffafa, "springgreen": 0x00ff7f,
+	"steelblue": 0x4682b4, "tan": 0xd2b48c, "teal": 0x008080, "thistle": 0xd8bfd8,
+	"tomato": 0xff6347, "turquoise": 0x40e0d0, "violet": 0xee82ee, "wheat": 0xf5deb3,
+	"white": 0xffffff, "whitesmoke": 0xf5f5f5, "yellow": 0xffff00, "yellowgreen": 0x9acd32,
+}
+
+// Color is an RGBA color, with 8 bits per channel.  It implements the image/color Color interface.
+// It accepts hex colors, '#rgb', '#rgba', '#rrggbb' or '#rrggbbaa', the functional forms 'rgb(r, g, b)' and 'rgba(r, g, b, a)',
+// with channels of 0 to 255, or percentages, and alpha from 0 to 1, or the CSS named colors, such as 'teal' or 'transparent'.
+type Color struct {
+	R, G, B, A uint8
+}
+
+// RGBA implements the image/color Color interface, returning alpha-premultiplied channels.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	a = uint32(c.A)
+	a |= a << 8
+	r = uint32(c.R) * a / 0xff
+	g = uint32(c.G) * a / 0xff
+	b = uint32(c.B) * a / 0xff
+	return r, g, b, a
+}
+
+// String gets the hex form of the color, including the alpha channel only when it is not opaque.
+func (c Color) String() string {
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+func (c Color) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Color) UnmarshalText(text []byte) error {
+	s := strings.ToLower(strings.TrimSpace(string(text)))
+	var v Color
+	var err error
+	switch {
+	case strings.HasPrefix(s, "#"):
+		v, err = parseHexColor(s[1:])
+	case strings.HasPrefix(s, "rgb"):
+		v, err = parseFuncColor(s)
+	case s == "transparent":
+		v = Color{}
+	default:
+		rgb, ok := namedColors[s]
+		if !ok {
+			return fmt.Errorf("invalid color %q, expected a hex, rgb() or named color", s)
+		}
+		v = Color{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid color %q  %v", s, err)
+	}
+	*c = v
+	return nil
+}
+
+func parseHexColor(s string) (Color, error) {
+	// expand short forms, #rgb and #rgba, to #rrggbb and #rrggbbaa
+	if len(s) == 3 || len(s) == 4 {
+		var sb strings.Builder
+		for _, r := range s {
+			sb.WriteRune(r)
+			sb.WriteRune(r)
+		}
+		s = sb.String()
+	}
+	if len(s) == 6 {
+		s += "ff"
+	}
+	if len(s) != 8 {
+		return Color{}, fmt.Errorf("expected 3, 4, 6 or 8 hex digits")
+	}
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hex digits")
+	}
+	return Color{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, nil
+}
+
+func parseFuncColor(s string) (Color, error) {
+	name, args, ok := strings.Cut(s, "(")
+	if !ok || !strings.HasSuffix(args, ")") || (name != "rgb" && name != "rgba") {
+		return Color{}, fmt.Errorf("expected rgb(r, g, b) or rgba(r, g, b, a)")
+	}
+	parts := strings.Split(strings.TrimSuffix(args, ")"), ",")
+	if len(parts) != 3 && len(parts) != 4 {
+		return Color{}, fmt.Errorf("expected 3 or 4 values")
+	}
+	v := Color{A: 0xff}
+	channels := []*uint8{&v.R, &v.G, &v.B}
+	for i, ch := range channels {
+		n, err := parseColorChannel(strings.TrimSpace(parts[i]), 255)
+		if err != nil {
+			return Color{}, err
+		}
+		*ch = n
+	}
+	if len(parts) == 4 {
+		n, err := parseColorChannel(strings.TrimSpace(parts[3]), 1)
+		if err != nil {
+			return Color{}, err
+		}
+		v.A = n
+	}
+	return v, nil
+}
+
+// parseColorChannel parses a channel value in the range 0 to max, or a percentage, into 0 to 255
+func parseColorChannel(s string, max float64) (uint8, error) {
+	if pc, ok := strings.CutSuffix(s, "%"); ok {
+		s, max = pc, 100
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 || n > max {
+		return 0, fmt.Errorf("invalid channel value %q, expected 0 to %v", s, max)
+	}
+	return uint8(n/max*255 + 0.5), nil
+}
@@ -0,0 +1,47 @@
+package argflags
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestColor(t *testing.T) {
+	tests := map[string]Color{
+		"#f80":                    {R: 0xff, G: 0x88, B: 0x00, A: 0xff},
+		"#f808":                   {R: 0xff, G: 0x88, B: 0x00, A: 0x88},
+		"#FF8800":                 {R: 0xff, G: 0x88, B: 0x00, A: 0xff},
+		"#ff880080":               {R: 0xff, G: 0x88, B: 0x00, A: 0x80},
+		"rgb(255, 136, 0)":        {R: 0xff, G: 0x88, B: 0x00, A: 0xff},
+		"rgba(100%, 0%, 0%, 0.5)": {R: 0xff, A: 0x80},
+		"Teal":                    {G: 0x80, B: 0x80, A: 0xff},
+		"transparent":             {},
+	}
+	for s, expect := range tests {
+		var c Color
+		if err := c.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if c != expect {
+			t.Errorf("%s  expected %v, got %v", s, expect, c)
+		}
+	}
+	for _, s := range []string{"#ff", "#gggggg", "rgb(256,0,0)", "rgba(0,0,0,2)", "hsl(0,0,0)", "rgb(1,2)", "notacolor"} {
+		var c Color
+		if err := c.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestColorFormats(t *testing.T) {
+	c := Color{R: 0xff, G: 0x88, A: 0xff}
+	if c.String() != "#ff8800" || (Color{R: 0xff, A: 0x80}).String() != "#ff000080" {
+		t.Errorf("expected the alpha only when not opaque, got %s", c)
+	}
+	var _ color.Color = c
+	r, _, _, a := Color{R: 0xff, A: 0x80}.RGBA()
+	if a != 0x8080 || r != 0x8080 {
+		t.Errorf("expected alpha premultiplied channels, got %x, %x", r, a)
+	}
+}
@@ -0,0 +1,425 @@
+package argflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Handler performs a command, using the flags and arguments of the given invocation.
+type Handler func(ctx context.Context, inv *Invocation) error
+
+// Middleware wraps a Handler, returning a Handler which performs some additional work, before and/or after calling the given Handler.
+type Middleware func(next Handler) Handler
+
+// Invocation is a command being invoked, with its flags applied.
+type Invocation struct {
+	// Command is the command being invoked.
+	Command *Command
+	// Flags is the commands flag struct, with the argument flags applied.
+	Flags interface{}
+	// Args are the remaining arguments, not applied as flags.
+	Args []string
+	// UndoData is the data the commands Undo template is executed with.
+	// Handlers may set it to the outcome of the command, such as the ID of something created.
+	// When nil, the command Flags are used.
+	UndoData interface{}
+	// MaskedArgs are all the arguments the command was invoked with, from the root command,
+	// with the values of any secret flags masked.
+	MaskedArgs []string
+
+	// applied are the fields set by flags, in this command and its parents.
+	applied []appliedField
+	// secrets are the indexes of the MaskedArgs with a masked secret value, in order.
+	secrets []int
+}
+
+// Command is a named command, with its own flags and optional sub commands.
+// The first argument, (not a flag) matching the name of a sub command, selects that sub command
+// and the arguments which follow it are applied to the sub command.
+// Sub commands inherit the flags of their parent commands, so any parent flag may also be given after the sub command.
+// A sub command may hide inherited flags, so they are no longer accepted by that command (or its own sub commands)
+// and may override the default value of inherited flags, which are applied when the sub command is invoked,
+// unless the flag has been given explicitly.
+type Command struct {
+	// Name is the name used to invoke the command.
+	Name string
+	// Summary is a short, one line description of the command.
+	Summary string
+	// Flags is a pointer to the struct the commands flags are applied to, or nil if the command has no flags of its own.
+	Flags interface{}
+	// Handler performs the command. May be nil if the command only groups sub commands.
+	Handler Handler
+
+	// HideInherited names inherited flags which are not accepted by this command.
+	HideInherited []string
+	// InheritedDefaults overrides the default value of inherited flags, keyed by the flag name.
+	InheritedDefaults map[string]string
+
+	// Undo is a template of the arguments, for the root command, which undo a successful invocation of this command.
+	// e.g. the 'create' command may have the undo: 'delete --id {{.ID}}'
+	// The template is executed with the invocation UndoData, and the resulting arguments passed to the OnUndo function.
+	// Values which may contain spaces should be quoted with the 'quote' function. e.g. {{quote .Name}}
+	Undo string
+	// OnUndo is passed the undo arguments of each successful invocation, of this command or its sub commands, which have an Undo template.
+	// The nearest OnUndo, from the invoked command up through its parents, is used.  See PrintUndo.
+	OnUndo func(ctx context.Context, inv *Invocation, undo ArgFlags) error
+
+	// Parser, when set, is the parser the command flags are applied with.
+	// When nil, the Parser of the nearest parent command with one is used, or, if none have a parser, a default Parser.
+	Parser *Parser
+
+	// PluginPrefix, when set, enables external plugin commands.
+	// An unknown command is looked for on the PATH as an executable named '<PluginPrefix>-<command>',
+	// which is run with the raw arguments following the command.
+	PluginPrefix string
+
+	parent     *Command
+	commands   []*Command
+	middleware []Middleware
+}
+
+// Use adds the given middleware to the command.
+// Middleware wraps the handler of the command and the handlers of all its sub commands,
+// with access to the invocation, and its populated flags, before the handler is called.
+// Middleware of a parent command wraps that of its sub commands, and on each command,
+// the first middleware added is the outermost.
+func (c *Command) Use(mw ...Middleware) {
+	c.middleware = append(c.middleware, mw...)
+}
+
+// AddCommand adds the given commands as sub commands of this command.
+func (c *Command) AddCommand(cmds ...*Command) {
+	for _, cmd := range cmds {
+		cmd.parent = c
+		c.commands = append(c.commands, cmd)
+	}
+}
+
+// Commands gets the sub commands of this command.
+func (c *Command) Commands() []*Command {
+	return c.commands
+}
+
+// Command gets the sub command with the given name, or nil if no sub command has that name.
+func (c *Command) Command(name string) *Command {
+	for _, cmd := range c.commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// Parent gets the command this command is a sub command of, or nil if it is a root command.
+func (c *Command) Parent() *Command {
+	return c.parent
+}
+
+// Path gets the space delimited names of the command, from its root command.
+func (c *Command) Path() string {
+	if c.parent == nil || c.parent.Path() == "" {
+		return c.Name
+	}
+	return strings.Join([]string{c.parent.Path(), c.Name}, " ")
+}
+
+// Execute applies the given arguments to the command, and any sub command they select, then invokes the selected command handler.
+func (c *Command) Execute(ctx context.Context, args []string) error {
+	masked := make([]string, len(args))
+	copy(masked, args)
+	return c.execute(ctx, args, nil, &explicitFields{isSet: map[fieldKey]bool{}, masked: masked})
+}
+
+// explicitFields are the fields set by flags in the commands executed so far.
+type explicitFields struct {
+	isSet   map[fieldKey]bool
+	applied []appliedField
+	// masked are all the arguments being executed, with the secret values masked.
+	masked []string
+	// secrets are the indexes of the masked arguments.
+	secrets []int
+	// beforeHandler, when set, is called once all the flags are applied, before the handler is called.
+	beforeHandler func()
+}
+
+// execute applies the given arguments to this command and the given inherited targets.
+// explicit holds the fields already set by flags in the parent commands, which inherited defaults will not override.
+func (c *Command) execute(ctx context.Context, args []string, inherited []applyTarget, explicit *explicitFields) error {
+	inherited = c.hideInherited(inherited)
+	if err := c.applyInheritedDefaults(inherited, explicit); err != nil {
+		return err
+	}
+	targets, err := c.targets(inherited)
+	if err != nil {
+		return err
+	}
+	a := c.parser().newApplier(targets...)
+	a.preset = explicit.isSet
+	if len(c.commands) > 0 || c.PluginPrefix != "" {
+		a.stopAt = func(arg string) bool {
+			return c.Command(arg) != nil || c.pluginPath(arg) != ""
+		}
+	}
+	if err := a.apply(args); err != nil {
+		if errors.Is(err, ErrHelp) {
+			if _, werr := fmt.Fprint(c.parser().helpOutput(ctx), c.Help()); werr != nil {
+				return werr
+			}
+		}
+		return err
+	}
+	for k := range a.isApplied {
+		explicit.isSet[k] = true
+	}
+	explicit.applied = append(explicit.applied, a.applied...)
+	// args is always the tail of all the arguments, so offset secrets into the masked arguments
+	offset := len(explicit.masked) - len(args)
+	for i, masked := range a.secretValues {
+		explicit.masked[offset+i] = masked
+		explicit.secrets = append(explicit.secrets, offset+i)
+	}
+	sort.Ints(explicit.secrets)
+	if len(a.remain) > 0 {
+		if cmd := c.Command(a.remain[0]); cmd != nil {
+			return cmd.execute(ctx, a.remain[1:], inheritedTargets(targets), explicit)
+		}
+		return c.executePlugin(ctx, a.remain[0], a.remain[1:], explicit.masked)
+	}
+	if c.Handler == nil {
+		if len(a.result.Unused) > 0 {
+			return c.unknownCommand(a.result.Unused[0])
+		}
+		names := make([]string, len(c.commands))
+		for i, cmd := range c.commands {
+			names[i] = cmd.Name
+		}
+		names = append(names, c.Plugins()...)
+		if c.Path() == "" {
+			return fmt.Errorf("a command is required: %s", strings.Join(names, ", "))
+		}
+		return fmt.Errorf("%s requires a command: %s", c.Path(), strings.Join(names, ", "))
+	}
+	if explicit.beforeHandler != nil {
+		explicit.beforeHandler()
+	}
+	inv := &Invocation{Command: c, Flags: c.Flags, Args: a.result.Unused, MaskedArgs: explicit.masked, applied: explicit.applied, secrets: explicit.secrets}
+	if err := c.handler()(ctx, inv); err != nil {
+		return err
+	}
+	return c.undo(ctx, inv)
+}
+
+// parser gets the parser of the command, or of its nearest parent with one, or a default parser if none have one.
+func (c *Command) parser() *Parser {
+	for p := c; p != nil; p = p.parent {
+		if p.Parser != nil {
+			return p.Parser
+		}
+	}
+	return NewParser()
+}
+
+// unknownCommand gets the error for an unknown command name, suggesting the closest known command, if any are similar.
+func (c *Command) unknownCommand(name string) error {
+	names := c.Plugins()
+	for _, cmd := range c.commands {
+		names = append(names, cmd.Name)
+	}
+	return ErrUnknownCommand{Name: name, Suggestion: suggest(name, names)}
+}
+
+// handler gets the commands Handler, wrapped in the middleware of the command and all its parents.
+func (c *Command) handler() Handler {
+	h := c.Handler
+	for p := c; p != nil; p = p.parent {
+		for i := len(p.middleware) - 1; i >= 0; i-- {
+			h = p.middleware[i](h)
+		}
+	}
+	return h
+}
+
+// targets gets the commands own flags, followed by the given inherited flags.
+func (c *Command) targets(inherited []applyTarget) ([]applyTarget, error) {
+	if c.Flags == nil {
+		return inherited, nil
+	}
+	v, err := getStructValue(c.Flags)
+	if err != nil {
+		return nil, fmt.Errorf("command %s  %v", c.Path(), err)
+	}
+	return append([]applyTarget{{value: *v}}, inherited...), nil
+}
+
+// inheritedTargets gets a copy of the given targets, marked as inherited, for the sub commands of the command they belong to.
+func inheritedTargets(targets []applyTarget) []applyTarget {
+	inherited := make([]applyTarget, len(targets))
+	for i, target := range targets {
+		inherited[i] = applyTarget{value: target.value, hidden: target.hidden, inherited: true}
+	}
+	return inherited
+}
+
+// hideInherited gets a copy of the given inherited targets, with the commands hidden flags added to them.
+func (c *Command) hideInherited(inherited []applyTarget) []applyTarget {
+	if len(c.HideInherited) == 0 {
+		return inherited
+	}
+	hidden := make([]applyTarget, len(inherited))
+	for i, target := range inherited {
+		hidden[i] = applyTarget{value: target.value, hidden: map[string]bool{}, inherited: target.inherited}
+		for k := range target.hidden {
+			hidden[i].hidden[k] = true
+		}
+		for _, name := range c.HideInherited {
+			if key := indexKey(c.parser().findFieldIndex(name, target.value.Type(), nil)); key != "" {
+				hidden[i].hidden[key] = true
+			}
+		}
+	}
+	return hidden
+}
+
+// applyInheritedDefaults sets the commands inherited defaults, on the inherited fields which have not already been set explicitly.
+func (c *Command) applyInheritedDefaults(inherited []applyTarget, explicit *explicitFields) error {
+	a := c.parser().newApplier(inherited...)
+	for _, name := range sortedKeys(c.InheritedDefaults) {
+		fld := a.findFlagField(name)
+		if fld == nil {
+			return fmt.Errorf("command %s  inherited flag -%s not found", c.Path(), name)
+		}
+		if explicit.isSet[keyOfField(fld.fldValue)] {
+			continue
+		}
+		if err := fld.SetValue(c.InheritedDefaults[name]); err != nil {
+			return fmt.Errorf("command %s  default for -%s  %v", c.Path(), name, err)
+		}
+		// inherited defaults take the place of any default tag on the field
+		explicit.isSet[keyOfField(fld.fldValue)] = true
+	}
+	return nil
+}
+
+// Help gets the help text for the command, listing its sub commands, its own flags and the flags it inherits.
+func (c *Command) Help() string {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "Usage: %s", c.Path())
+	if len(c.commands) > 0 || c.PluginPrefix != "" {
+		buf.WriteString(" <command>")
+	}
+	buf.WriteString(" [flags]\n")
+	if c.Summary != "" {
+		fmt.Fprintf(buf, "\n%s\n", c.Summary)
+	}
+	if len(c.commands) > 0 {
+		buf.WriteString("\nCommands:\n")
+		for _, cmd := range c.commands {
+			fmt.Fprintf(buf, "  %-16s %s\n", cmd.Name, cmd.Summary)
+		}
+	}
+	if plugins := c.Plugins(); len(plugins) > 0 {
+		buf.WriteString("\nPlugin commands:\n")
+		for _, name := range plugins {
+			fmt.Fprintf(buf, "  %s\n", name)
+		}
+	}
+	if c.Flags != nil {
+		buf.WriteString("\nFlags:\n")
+		c.parser().writeFlagList(buf, reflect.TypeOf(c.Flags), nil, nil)
+	}
+
+	// collect the inherited flags, hiding any hidden by this command or any command between it and the parent
+	var hiddenBy []*Command
+	for p := c; p.parent != nil; p = p.parent {
+		hiddenBy = append(hiddenBy, p)
+		if p.parent.Flags == nil {
+			continue
+		}
+		t := reflect.TypeOf(p.parent.Flags)
+		hidden := map[string]bool{}
+		for _, hc := range hiddenBy {
+			for _, name := range hc.HideInherited {
+				if key := indexKey(c.parser().findFieldIndex(name, t, nil)); key != "" {
+					hidden[key] = true
+				}
+			}
+		}
+		if path := p.parent.Path(); path != "" {
+			fmt.Fprintf(buf, "\nInherited flags (%s):\n", path)
+		} else {
+			buf.WriteString("\nInherited flags:\n")
+		}
+		c.parser().writeFlagList(buf, t, hidden, c.InheritedDefaults)
+	}
+	return buf.String()
+}
+
+// writeFlagList writes a line for each flag field in the given struct type, excluding the hidden fields.
+// Each line shows the flag names, type and the description in the help tag, followed by any default value.
+// Any overridden default values, keyed by flag name, are shown in place of the default tag of the flag which they apply to.
+func (p *Parser) writeFlagList(buf *strings.Builder, t reflect.Type, hidden map[string]bool, defaults map[string]string) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	group := ""
+	for _, fd := range p.describeFlags(t) {
+		if hidden[indexKey(fd.index)] {
+			continue
+		}
+		if fd.group != group {
+			// flags of prefixed sub args are listed together, under their prefix
+			group = fd.group
+			buf.WriteString("\n")
+			if group != "" {
+				fmt.Fprintf(buf, "  %s:\n", group)
+			}
+		}
+		line := fmt.Sprintf("  %-24s %-16s", "-"+strings.Join(fd.names, ", -"), fd.field.Type.String())
+		if help := fd.field.Tag.Get(HelpTagName); help != "" {
+			line = strings.Join([]string{line, help}, " ")
+		}
+		def, hasDefault := fd.field.Tag.Lookup(DefaultTagName)
+		for _, name := range sortedKeys(defaults) {
+			if indexKey(p.findFieldIndex(name, t, nil)) == indexKey(fd.index) {
+				def, hasDefault = defaults[name], true
+				break
+			}
+		}
+		if hasDefault {
+			line = fmt.Sprintf("%s (default %s)", line, def)
+		}
+		if msg, ok := fd.field.Tag.Lookup(DeprecatedTagName); ok {
+			if msg != "" {
+				msg = ": " + msg
+			}
+			line = fmt.Sprintf("%s (deprecated%s)", line, msg)
+		}
+		buf.WriteString(strings.TrimRight(line, " "))
+		buf.WriteString("\n")
+		if p.isGroup(fd.field) {
+			// the flags of each element follow its sentinel flag, indented beneath it
+			elems := &strings.Builder{}
+			p.writeFlagList(elems, groupElemType(fd.field.Type), nil, nil)
+			for _, l := range strings.Split(strings.TrimRight(elems.String(), "\n"), "\n") {
+				if l != "" {
+					buf.WriteString("  ")
+				}
+				buf.WriteString(l)
+				buf.WriteString("\n")
+			}
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package argflags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type serveFlags struct {
+	Port int `flag:"port" help:"the port to listen on"`
+}
+
+func TestCommandHelp(t *testing.T) {
+	root := &Command{}
+	called := false
+	root.AddCommand(&Command{Name: "serve", Flags: &serveFlags{}, Handler: func(ctx context.Context, inv *Invocation) error {
+		called = true
+		return nil
+	}})
+	buf := &bytes.Buffer{}
+	err := root.Execute(context.WithValue(context.Background(), outputKey{}, buf), []string{"serve", "-h"})
+	if !errors.Is(err, ErrHelp) {
+		t.Errorf("expected ErrHelp, got %v", err)
+	}
+	if called {
+		t.Errorf("expected the handler not to be called")
+	}
+	if !strings.Contains(buf.String(), "-port") {
+		t.Errorf("expected the help to be written, got %q", buf.String())
+	}
+}
+
+func TestExecuteRequestHelp(t *testing.T) {
+	root := &Command{Flags: &serveFlags{}, Handler: func(ctx context.Context, inv *Invocation) error {
+		return nil
+	}}
+	resp := root.ExecuteRequest(context.Background(), Request{Options: map[string]interface{}{"help": true}})
+	if resp.Error != ErrHelp.Error() || !strings.Contains(resp.Output, "-port") {
+		t.Errorf("expected the help and ErrHelp, got %+v", resp)
+	}
+}
+
+type rootFlags struct {
+	Verbose bool   `flag:"verbose"`
+	Region  string `flag:"region" default:"eu"`
+}
+
+// newServeCommand gets a root command, with a 'serve' sub command recording the invocations of its handler.
+func newServeCommand(invs *[]*Invocation) (*Command, *rootFlags, *serveFlags) {
+	rf := &rootFlags{}
+	sf := &serveFlags{}
+	root := &Command{Name: "app", Flags: rf}
+	root.AddCommand(&Command{Name: "serve", Flags: sf, Handler: func(ctx context.Context, inv *Invocation) error {
+		*invs = append(*invs, inv)
+		return nil
+	}})
+	return root, rf, sf
+}
+
+func TestInheritedFlags(t *testing.T) {
+	var invs []*Invocation
+	root, rf, sf := newServeCommand(&invs)
+	if err := root.Execute(context.Background(), []string{"serve", "-port", "80", "-verbose"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !rf.Verbose || sf.Port != 80 || len(invs) != 1 {
+		t.Errorf("expected the parent flag to be applied after the sub command, got %+v, %+v", rf, sf)
+	}
+	if rf.Region != "eu" {
+		t.Errorf("expected the inherited flag default, got %q", rf.Region)
+	}
+}
+
+func TestHideInherited(t *testing.T) {
+	var invs []*Invocation
+	root, rf, _ := newServeCommand(&invs)
+	root.Command("serve").HideInherited = []string{"verbose"}
+	if err := root.Execute(context.Background(), []string{"serve", "-verbose"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if rf.Verbose || len(invs) != 1 || !reflect.DeepEqual(invs[0].Args, []string{"-verbose"}) {
+		t.Errorf("expected the hidden flag to be unused, got %+v", invs)
+	}
+	if err := root.Execute(context.Background(), []string{"-verbose", "serve"}); err != nil || !rf.Verbose {
+		t.Errorf("expected the hidden flag to be accepted before the sub command, got %v", err)
+	}
+}
+
+func TestInheritedDefaults(t *testing.T) {
+	var invs []*Invocation
+	root, rf, _ := newServeCommand(&invs)
+	root.Command("serve").InheritedDefaults = map[string]string{"region": "us"}
+	if err := root.Execute(context.Background(), []string{"serve"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if rf.Region != "us" {
+		t.Errorf("expected the inherited default to override the default tag, got %q", rf.Region)
+	}
+	rf.Region = ""
+	if err := root.Execute(context.Background(), []string{"-region", "ap", "serve"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if rf.Region != "ap" {
+		t.Errorf("expected the explicit flag to override the inherited default, got %q", rf.Region)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, inv *Invocation) error {
+				calls = append(calls, name)
+				return next(ctx, inv)
+			}
+		}
+	}
+	root := &Command{Name: "app"}
+	sub := &Command{Name: "serve", Handler: func(ctx context.Context, inv *Invocation) error {
+		calls = append(calls, "handler")
+		return nil
+	}}
+	root.AddCommand(sub)
+	root.Use(mw("a"), mw("b"))
+	sub.Use(mw("c"))
+	if err := root.Execute(context.Background(), []string{"serve"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"a", "b", "c", "handler"}) {
+		t.Errorf("expected the parent middleware outermost, in the order added, got %v", calls)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	var invs []*Invocation
+	root, _, _ := newServeCommand(&invs)
+	err := root.Execute(context.Background(), []string{"serv"})
+	var uc ErrUnknownCommand
+	if !errors.As(err, &uc) || uc.Name != "serv" || uc.Suggestion != "serve" {
+		t.Errorf("expected an unknown command suggesting serve, got %v", err)
+	}
+	if err := root.Execute(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "serve") {
+		t.Errorf("expected a command to be required, got %v", err)
+	}
+}
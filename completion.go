@@ -0,0 +1,407 @@
+package argflags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CompleteTagName is the tag hinting how the value of a flag is completed, by the shell completion scripts.
+// 'file' completes file paths, 'dir' completes directories, otherwise the tag is a '|' delimited list of the choices of value.
+// e.g. Config string `flag:"config" complete:"file"` or Format string `flag:"format" complete:"json|yaml|text"`
+// Fields without the tag offer the Choices of their type, if it has any.
+const CompleteTagName = "complete"
+
+// Completion hints of the complete tag.
+const (
+	completeFile = "file"
+	completeDir  = "dir"
+)
+
+// Choices is implemented by flag values with a fixed set of values, offered by the shell completion scripts.
+type Choices interface {
+	Choices() []string
+}
+
+var choicesType = reflect.TypeOf((*Choices)(nil)).Elem()
+
+// Shells which completion scripts may be generated for.
+const (
+	ShellBash = "bash"
+	ShellZsh  = "zsh"
+	ShellFish = "fish"
+)
+
+// completionFlag is a flag offered by the completion scripts.
+type completionFlag struct {
+	names []string
+	help  string
+	// value is true when the flag takes a value, in the following argument.
+	value   bool
+	file    bool
+	dir     bool
+	choices []string
+}
+
+// completionCommand is a command, or sub command, offered by the completion scripts, with the flags it accepts.
+type completionCommand struct {
+	// path is the space delimited names of the command, below the root command, or empty for the root.
+	path     string
+	summary  string
+	flags    []completionFlag
+	commands []*completionCommand
+}
+
+// Completion gets the completion script, for the given shell, of a program with the given name, taking the flags of the given struct pointer.
+// The shell is one of ShellBash, ShellZsh or ShellFish.
+// The script is sourced by the shell, e.g. 'source <(myapp completion bash)'.
+func Completion(shell, name string, str interface{}) (string, error) {
+	return NewParser().Completion(shell, name, str)
+}
+
+// Completion gets the completion script, for the given shell, of a program with the given name, as its flags are named by the parser.
+func (p *Parser) Completion(shell, name string, str interface{}) (string, error) {
+	if !isStructPointer(reflect.TypeOf(str)) {
+		return "", fmt.Errorf("completion can only be made for a struct pointer")
+	}
+	flags, err := p.completionFlags(reflect.TypeOf(str), nil)
+	if err != nil {
+		return "", err
+	}
+	return completionScript(shell, name, &completionCommand{flags: flags})
+}
+
+// Completion gets the completion script, for the given shell, of the program with this root command, named by the command name.
+// Each command completes its sub commands, its own flags and the flags it inherits.
+func (c *Command) Completion(shell string) (string, error) {
+	cc, err := c.completionCommand("")
+	if err != nil {
+		return "", err
+	}
+	return completionScript(shell, c.Name, cc)
+}
+
+// completionCommand describes the command, at the given path, and its sub commands, for the completion scripts.
+func (c *Command) completionCommand(path string) (*completionCommand, error) {
+	cc := &completionCommand{path: path, summary: c.Summary}
+	if c.Flags != nil {
+		flags, err := c.parser().completionFlags(reflect.TypeOf(c.Flags), nil)
+		if err != nil {
+			return nil, err
+		}
+		cc.addFlags(flags)
+	}
+	// the inherited flags, less those hidden by this command or any command between it and the parent
+	var hiddenBy []*Command
+	for p := c; p.parent != nil; p = p.parent {
+		hiddenBy = append(hiddenBy, p)
+		if p.parent.Flags == nil {
+			continue
+		}
+		t := reflect.TypeOf(p.parent.Flags)
+		hidden := map[string]bool{}
+		for _, hc := range hiddenBy {
+			for _, name := range hc.HideInherited {
+				if key := indexKey(c.parser().findFieldIndex(name, t, nil)); key != "" {
+					hidden[key] = true
+				}
+			}
+		}
+		flags, err := c.parser().completionFlags(t, hidden)
+		if err != nil {
+			return nil, err
+		}
+		cc.addFlags(flags)
+	}
+	for _, cmd := range c.commands {
+		sub, err := cmd.completionCommand(strings.TrimSpace(strings.Join([]string{path, cmd.Name}, " ")))
+		if err != nil {
+			return nil, err
+		}
+		cc.commands = append(cc.commands, sub)
+	}
+	return cc, nil
+}
+
+// addFlags adds the given flags to the command, other than those with a name already added, which shadows them.
+func (cc *completionCommand) addFlags(flags []completionFlag) {
+	names := map[string]bool{}
+	for _, cf := range cc.flags {
+		for _, name := range cf.names {
+			names[name] = true
+		}
+	}
+	for _, cf := range flags {
+		if !names[cf.names[0]] {
+			cc.flags = append(cc.flags, cf)
+		}
+	}
+}
+
+// completionFlags describes the flags of the given struct type, excluding the hidden fields, for the completion scripts.
+func (p *Parser) completionFlags(t reflect.Type, hidden map[string]bool) ([]completionFlag, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, err := p.Schema(t); err != nil {
+		return nil, err
+	}
+	var flags []completionFlag
+	for _, fd := range p.describeFlags(t) {
+		if hidden[indexKey(fd.index)] {
+			continue
+		}
+		cf := completionFlag{
+			names: fd.names,
+			help:  fd.field.Tag.Get(HelpTagName),
+			value: !isBoolType(fd.field.Type) && !p.hasTagOption(fd.field, optCount) && !p.isGroup(fd.field),
+		}
+		if cf.value {
+			switch hint := fd.field.Tag.Get(CompleteTagName); hint {
+			case completeFile:
+				cf.file = true
+			case completeDir:
+				cf.dir = true
+			case "":
+				cf.choices = choicesOf(fd.field.Type)
+			default:
+				cf.choices = strings.Split(hint, "|")
+			}
+		}
+		flags = append(flags, cf)
+		if p.isGroup(fd.field) {
+			// the flags of the elements are given following the sentinel flag, so are offered with the other flags
+			elems, err := p.completionFlags(groupElemType(fd.field.Type), nil)
+			if err != nil {
+				return nil, err
+			}
+			flags = append(flags, elems...)
+		}
+	}
+	return flags, nil
+}
+
+// choicesOf gets the Choices of the given type, or of its pointer, or nil if it has no choices.
+func choicesOf(t reflect.Type) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(choicesType) {
+		return reflect.New(t).Interface().(Choices).Choices()
+	}
+	return nil
+}
+
+// flagArgNames gets the flag names as given in the arguments, single letters with one dash, longer names with two.
+func flagArgNames(names []string) []string {
+	args := make([]string, len(names))
+	for i, name := range names {
+		if len(name) == 1 {
+			args[i] = "-" + name
+		} else {
+			args[i] = "--" + name
+		}
+	}
+	return args
+}
+
+// completionScript generates the completion script of the given command tree, for the given shell.
+func completionScript(shell, name string, root *completionCommand) (string, error) {
+	fn := "_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+	buf := &strings.Builder{}
+	switch shell {
+	case ShellBash:
+		writeBashCompletion(buf, fn, name, root)
+	case ShellZsh:
+		writeZshCompletion(buf, fn, name, root)
+	case ShellFish:
+		writeFishCompletion(buf, fn, name, root)
+	default:
+		return "", fmt.Errorf("unknown shell %q, expected %s, %s or %s", shell, ShellBash, ShellZsh, ShellFish)
+	}
+	return buf.String(), nil
+}
+
+// walk calls the given func with the command and each of its sub commands, parents first.
+func (cc *completionCommand) walk(fn func(cc *completionCommand)) {
+	fn(cc)
+	for _, sub := range cc.commands {
+		sub.walk(fn)
+	}
+}
+
+// subCommandPatterns gets the 'path:name' shell patterns, of every sub command, matched when working out the command path.
+func subCommandPatterns(root *completionCommand) []string {
+	var patterns []string
+	root.walk(func(cc *completionCommand) {
+		for _, sub := range cc.commands {
+			names := strings.Fields(sub.path)
+			patterns = append(patterns, shellQuote(cc.path+":"+names[len(names)-1]))
+		}
+	})
+	return patterns
+}
+
+// flagPatterns gets the 'path:flag' shell patterns of the given flag, of the given command, both with one and two dashes.
+func flagPatterns(cc *completionCommand, cf completionFlag) []string {
+	var patterns []string
+	for _, name := range cf.names {
+		patterns = append(patterns, shellQuote(cc.path+":-"+name), shellQuote(cc.path+":--"+name))
+	}
+	return patterns
+}
+
+// words gets the flags and sub command names offered for the command.
+func (cc *completionCommand) words() []string {
+	var words []string
+	for _, cf := range cc.flags {
+		words = append(words, flagArgNames(cf.names)...)
+	}
+	for _, sub := range cc.commands {
+		names := strings.Fields(sub.path)
+		words = append(words, names[len(names)-1])
+	}
+	return words
+}
+
+// shellQuote single quotes the given string for a shell script.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func writeBashCompletion(buf *strings.Builder, fn, name string, root *completionCommand) {
+	fmt.Fprintf(buf, "# bash completion for %s\n", name)
+	fmt.Fprintf(buf, "%s() {\n", fn)
+	buf.WriteString("\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\" cmd=\"\" i\n")
+	buf.WriteString("\tfor ((i = 1; i < COMP_CWORD; i++)); do\n")
+	buf.WriteString("\t\tcase \"$cmd:${COMP_WORDS[i]}\" in\n")
+	if patterns := subCommandPatterns(root); len(patterns) > 0 {
+		fmt.Fprintf(buf, "\t\t%s) cmd=\"${cmd:+$cmd }${COMP_WORDS[i]}\" ;;\n", strings.Join(patterns, "|"))
+	}
+	buf.WriteString("\t\tesac\n\tdone\n")
+	buf.WriteString("\tcase \"$cmd:$prev\" in\n")
+	root.walk(func(cc *completionCommand) {
+		for _, cf := range cc.flags {
+			if !cf.value {
+				continue
+			}
+			reply := "COMPREPLY=()"
+			switch {
+			case cf.file:
+				reply = "COMPREPLY=($(compgen -f -- \"$cur\"))"
+			case cf.dir:
+				reply = "COMPREPLY=($(compgen -d -- \"$cur\"))"
+			case len(cf.choices) > 0:
+				reply = fmt.Sprintf("COMPREPLY=($(compgen -W %s -- \"$cur\"))", shellQuote(strings.Join(cf.choices, " ")))
+			}
+			fmt.Fprintf(buf, "\t%s) %s; return ;;\n", strings.Join(flagPatterns(cc, cf), "|"), reply)
+		}
+	})
+	buf.WriteString("\tesac\n")
+	buf.WriteString("\tcase \"$cmd\" in\n")
+	root.walk(func(cc *completionCommand) {
+		fmt.Fprintf(buf, "\t%s) COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n", shellQuote(cc.path), shellQuote(strings.Join(cc.words(), " ")))
+	})
+	buf.WriteString("\tesac\n}\n")
+	fmt.Fprintf(buf, "complete -F %s %s\n", fn, name)
+}
+
+func writeZshCompletion(buf *strings.Builder, fn, name string, root *completionCommand) {
+	fmt.Fprintf(buf, "#compdef %s\n", name)
+	fmt.Fprintf(buf, "%s() {\n", fn)
+	buf.WriteString("\tlocal cmd=\"\" i\n")
+	buf.WriteString("\tfor ((i = 2; i < CURRENT; i++)); do\n")
+	buf.WriteString("\t\tcase \"$cmd:${words[i]}\" in\n")
+	if patterns := subCommandPatterns(root); len(patterns) > 0 {
+		fmt.Fprintf(buf, "\t\t%s) cmd=\"${cmd:+$cmd }${words[i]}\" ;;\n", strings.Join(patterns, "|"))
+	}
+	buf.WriteString("\t\tesac\n\tdone\n")
+	buf.WriteString("\tcase \"$cmd:${words[CURRENT-1]}\" in\n")
+	root.walk(func(cc *completionCommand) {
+		for _, cf := range cc.flags {
+			if !cf.value {
+				continue
+			}
+			reply := "_message value"
+			switch {
+			case cf.file:
+				reply = "_files"
+			case cf.dir:
+				reply = "_files -/"
+			case len(cf.choices) > 0:
+				reply = "compadd -- " + strings.Join(quoteAll(cf.choices), " ")
+			}
+			fmt.Fprintf(buf, "\t%s) %s; return ;;\n", strings.Join(flagPatterns(cc, cf), "|"), reply)
+		}
+	})
+	buf.WriteString("\tesac\n")
+	buf.WriteString("\tcase \"$cmd\" in\n")
+	root.walk(func(cc *completionCommand) {
+		fmt.Fprintf(buf, "\t%s) compadd -- %s ;;\n", shellQuote(cc.path), strings.Join(quoteAll(cc.words()), " "))
+	})
+	buf.WriteString("\tesac\n}\n")
+	fmt.Fprintf(buf, "compdef %s %s\n", fn, name)
+}
+
+func writeFishCompletion(buf *strings.Builder, fn, name string, root *completionCommand) {
+	fmt.Fprintf(buf, "# fish completion for %s\n", name)
+	// fish conditions test the command path, worked out from the words before the cursor
+	fmt.Fprintf(buf, "function %s_is\n", fn)
+	buf.WriteString("\tset -l words (commandline -opc)\n\tset -e words[1]\n\tset -l cmd ''\n")
+	buf.WriteString("\tfor w in $words\n\t\tswitch \"$cmd:$w\"\n")
+	if patterns := subCommandPatterns(root); len(patterns) > 0 {
+		fmt.Fprintf(buf, "\t\t\tcase %s\n\t\t\t\tset cmd (string trim -- \"$cmd $w\")\n", strings.Join(patterns, " "))
+	}
+	buf.WriteString("\t\tend\n\tend\n\ttest \"$cmd\" = \"$argv[1]\"\nend\n")
+	fmt.Fprintf(buf, "complete -c %s -f\n", name)
+	root.walk(func(cc *completionCommand) {
+		cond := shellQuote(fmt.Sprintf("%s_is %s", fn, shellQuote(cc.path)))
+		for _, sub := range cc.commands {
+			names := strings.Fields(sub.path)
+			fmt.Fprintf(buf, "complete -c %s -n %s -a %s", name, cond, shellQuote(names[len(names)-1]))
+			if sub.summary != "" {
+				fmt.Fprintf(buf, " -d %s", shellQuote(sub.summary))
+			}
+			buf.WriteString("\n")
+		}
+		for _, cf := range cc.flags {
+			fmt.Fprintf(buf, "complete -c %s -n %s", name, cond)
+			for _, n := range cf.names {
+				if len(n) == 1 {
+					fmt.Fprintf(buf, " -s %s", shellQuote(n))
+				} else {
+					fmt.Fprintf(buf, " -l %s", shellQuote(n))
+				}
+			}
+			switch {
+			case !cf.value:
+			case cf.file:
+				buf.WriteString(" -r -F")
+			case cf.dir:
+				buf.WriteString(" -r -a '(__fish_complete_directories)'")
+			case len(cf.choices) > 0:
+				fmt.Fprintf(buf, " -r -a %s", shellQuote(strings.Join(cf.choices, " ")))
+			default:
+				buf.WriteString(" -r")
+			}
+			if cf.help != "" {
+				fmt.Fprintf(buf, " -d %s", shellQuote(cf.help))
+			}
+			buf.WriteString("\n")
+		}
+	})
+}
+
+// quoteAll single quotes each of the given strings for a shell script.
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = shellQuote(v)
+	}
+	return quoted
+}
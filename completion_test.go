@@ -0,0 +1,109 @@
+package argflags
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+type logLevel string
+
+func (ll logLevel) Choices() []string {
+	return []string{"debug", "info", "warn"}
+}
+
+type completeFlags struct {
+	Output string   `flag:"output,o" complete:"file" help:"the file to write"`
+	Dir    string   `flag:"dir" complete:"dir"`
+	Format string   `flag:"format" complete:"json|yaml"`
+	Level  logLevel `flag:"level"`
+	Quiet  bool     `flag:"quiet,q"`
+}
+
+// newCompleteCommand gets a root command, with a 'serve' sub command, and a 'db migrate' sub command.
+func newCompleteCommand() *Command {
+	noop := func(ctx context.Context, inv *Invocation) error { return nil }
+	root := &Command{Name: "app", Flags: &completeFlags{}}
+	root.AddCommand(&Command{Name: "serve", Summary: "serve the app", Flags: &serveFlags{}, Handler: noop})
+	db := &Command{Name: "db"}
+	db.AddCommand(&Command{Name: "migrate", Handler: noop})
+	root.AddCommand(db)
+	return root
+}
+
+// completeBash runs the bash completion script, for the given words, with the cursor on the last word, and returns the replies.
+func completeBash(t *testing.T, script string, words ...string) []string {
+	t.Helper()
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+	src := script + "COMP_WORDS=(" + strings.Join(quoteAll(words), " ") + ")\n" +
+		"COMP_CWORD=" + string(rune('0'+len(words)-1)) + "\n_app\nprintf '%s\\n' \"${COMPREPLY[@]}\"\n"
+	out, err := exec.Command(bash, "--norc", "-c", src).CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash completion failed  %v  %s", err, out)
+	}
+	return strings.Fields(string(out))
+}
+
+func TestBashCompletion(t *testing.T) {
+	script, err := newCompleteCommand().Completion(ShellBash)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !strings.Contains(script, "complete -F _app app") {
+		t.Fatalf("expected the completion to be registered, got %s", script)
+	}
+	tests := []struct {
+		words  []string
+		expect string
+	}{
+		{[]string{"app", "s"}, "serve"},
+		{[]string{"app", "--fo"}, "--format"},
+		{[]string{"app", "--format", "y"}, "yaml"},
+		{[]string{"app", "-level", "d"}, "debug"},
+		{[]string{"app", "serve", "--p"}, "--port"},
+		{[]string{"app", "serve", "--q"}, "--quiet"},
+		{[]string{"app", "db", "m"}, "migrate"},
+	}
+	for _, test := range tests {
+		reply := completeBash(t, script, test.words...)
+		if len(reply) != 1 || reply[0] != test.expect {
+			t.Errorf("%v  expected %s, got %v", test.words, test.expect, reply)
+		}
+	}
+	if reply := completeBash(t, script, "app", "--p"); len(reply) != 0 {
+		t.Errorf("expected -port not to be offered outside serve, got %v", reply)
+	}
+}
+
+func TestCompletionShells(t *testing.T) {
+	zsh, err := newCompleteCommand().Completion(ShellZsh)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	for _, s := range []string{"#compdef app", "_files;", "_files -/;", "compadd -- 'debug' 'info' 'warn'", "compdef _app app"} {
+		if !strings.Contains(zsh, s) {
+			t.Errorf("expected the zsh script to contain %q, got %s", s, zsh)
+		}
+	}
+	fish, err := newCompleteCommand().Completion(ShellFish)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	for _, s := range []string{
+		"-a 'serve' -d 'serve the app'",
+		"-l 'output' -s 'o' -r -F -d 'the file to write'",
+		"-l 'format' -r -a 'json yaml'",
+		"-l 'quiet' -s 'q'\n",
+	} {
+		if !strings.Contains(fish, s) {
+			t.Errorf("expected the fish script to contain %q, got %s", s, fish)
+		}
+	}
+	if _, err := Completion("pwsh", "app", &completeFlags{}); err == nil || !strings.Contains(err.Error(), "unknown shell") {
+		t.Errorf("expected an unknown shell error, got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package argflags
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// optConfig marks a string field as naming a config file, which sets the fields of the struct not given as flags.
+// e.g. Config string `flag:"config,config" env:"MYAPP_CONFIG" default:"/etc/myapp.json"`
+// The config file is the lowest precedence of the values given for a field,
+// a flag takes precedence over the environment variable, which takes precedence over the config file,
+// which takes precedence over the default.
+// When the file is only named by the default tag, it is ignored if it does not exist.
+const optConfig = "config"
+
+// configFormats holds the decoder of each config file format, keyed by its file extension, including the dot.
+var configFormats sync.Map
+
+func init() {
+	RegisterConfigFormat(".json", decodeJSON)
+}
+
+// RegisterConfigFormat registers the given function to decode config files with the given file extension, e.g. ".yaml"
+// decode must unmarshal the file into a map[string]interface{}, as json.Unmarshal does.
+// JSON is registered by default. Other formats are registered with their own unmarshal function,
+// e.g. RegisterConfigFormat(".yaml", yaml.Unmarshal) or RegisterConfigFormat(".toml", toml.Unmarshal)
+// Registering an extension already registered replaces the previous decoder.
+func RegisterConfigFormat(ext string, decode func(data []byte, v interface{}) error) {
+	configFormats.Store(strings.ToLower(ext), decode)
+}
+
+// decodeJSON decodes JSON, keeping numbers as given, so large integers are not rounded as floats.
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// WithConfigFile sets a config file, which sets the fields not given by a flag, or the environment, each time arguments are applied.
+// The file is ignored if it does not exist. A field tagged with the 'config' option, when given, names the file in its place.
+func WithConfigFile(path string) Option {
+	return func(p *Parser) {
+		p.configFile = path
+	}
+}
+
+// LoadConfig sets the fields of the given struct pointer from the given config file, using the default Parser.
+// See Parser.LoadConfig
+func LoadConfig(path string, str interface{}) error {
+	return NewParser().LoadConfig(path, str)
+}
+
+// LoadConfig sets the fields of the given struct pointer from the given config file.
+// The format of the file is found by its extension, see RegisterConfigFormat.
+// Each key in the file is the name of a flag, as it would be given in the arguments, without the dash.
+// The flags of a prefixed sub arg may be given as an object, keyed by the prefix, e.g. {"db": {"host": "localhost"}}
+// Arrays set each of their elements, in the same way as a flag given more than once, and objects set map fields.
+// Keys which do not match a flag are an error.
+// To have flags override the values in the file, apply the arguments with a config file, see WithConfigFile,
+// rather than loading the file first, as flag defaults would replace the values from the file.
+func (p *Parser) LoadConfig(path string, str interface{}) error {
+	v, err := getStructValue(str)
+	if err != nil {
+		return err
+	}
+	if _, err := p.Schema(v.Type()); err != nil {
+		return err
+	}
+	return p.newApplier(applyTarget{value: *v}).loadConfig(path)
+}
+
+// applyConfig sets the fields not set by a flag from the config file named by the config field, or the parser.
+func (a *applier) applyConfig() error {
+	path, explicit := a.configPath()
+	if path == "" {
+		return nil
+	}
+	err := a.loadConfig(path)
+	if !explicit && errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// configPath gets the path of the config file, and if it was given, rather than being a default.
+// The first config field found is used, taking its flag value, then its environment variable, then its default,
+// then the config file of the parser.
+func (a *applier) configPath() (string, bool) {
+	for _, target := range a.targets {
+		for _, fd := range a.p.describeFlags(target.value.Type()) {
+			if !a.p.hasTagOption(fd.field, optConfig) || target.hidden[indexKey(fd.index)] {
+				continue
+			}
+			fld, ok := a.p.fieldInUse(target.value, fd.index)
+			if !ok {
+				continue
+			}
+			if a.isApplied[keyOfField(fld)] {
+				return fmt.Sprint(fld.Interface()), true
+			}
+			if name, ok := fd.field.Tag.Lookup(EnvTagName); ok && name != "" {
+				if value, ok := os.LookupEnv(a.p.envPrefix + name); ok {
+					return value, true
+				}
+			}
+			if def := fd.field.Tag.Get(DefaultTagName); def != "" {
+				return def, false
+			}
+		}
+	}
+	return a.p.configFile, false
+}
+
+// loadConfig reads and decodes the given config file, setting the fields it names, which have not been set by a flag.
+func (a *applier) loadConfig(path string) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	decode, ok := configFormats.Load(ext)
+	if !ok {
+		return fmt.Errorf("config %s  unknown format %q", path, ext)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config %s  %w", path, err)
+	}
+	values := map[string]interface{}{}
+	if err := decode.(func(data []byte, v interface{}) error)(data, &values); err != nil {
+		return fmt.Errorf("config %s  %v", path, err)
+	}
+	return a.applyConfigValues(path, "", values)
+}
+
+// applyConfigValues sets the fields named by the keys of the given config values, each prefixed with the given prefix.
+// Keys which do not name a flag, but hold an object, are the prefix of the keys within that object.
+func (a *applier) applyConfigValues(path, prefix string, values map[string]interface{}) error {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, k := range keys {
+		name := prefix + k
+		fld := a.findFlagField(name)
+		if fld != nil && a.p.isSubArg(fld.root.Type().FieldByIndex(fld.index)) {
+			fld = nil
+		}
+		if fld == nil {
+			if obj, ok := configObject(values[k]); ok {
+				errs = append(errs, a.applyConfigValues(path, name+".", obj))
+				continue
+			}
+			errs = append(errs, fmt.Errorf("config %s  %w", path, a.unknownFlag(name)))
+			continue
+		}
+		key := keyOfField(fld.fldValue)
+		if a.isApplied[key] || a.preset[key] || a.p.hasTagOption(fld.root.Type().FieldByIndex(fld.index), optConfig) {
+			continue
+		}
+		delim := ""
+		if sf := fld.root.Type().FieldByIndex(fld.index); isDelimitedType(sf.Type) {
+			delim = a.p.delimiterOf(sf)
+		}
+		strs, err := configStrings(values[k], delim)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("config %s  '%s'  %v", path, name, err))
+			continue
+		}
+		if err := a.setConfigValues(fld, strs); err != nil && !a.warned(name, strings.Join(strs, a.p.delimiter), fld, err) {
+			errs = append(errs, fmt.Errorf("config %s  %w", path, ErrConversion{Flag: name, Value: strings.Join(strs, a.p.delimiter), Type: fld.Type(), Err: err}))
+			continue
+		}
+		a.result.Instantiated = append(a.result.Instantiated, fld.instantiated...)
+		a.isFallback[key] = true
+		a.setFrom(SourceConfig, name, fld, strs...)
+	}
+	return errors.Join(errs...)
+}
+
+// setConfigValues sets the given field to the first of the given values, adding each following value, as a repeated flag would.
+// The element errors of each value partially set are returned together.
+func (a *applier) setConfigValues(fld *flagField, values []string) error {
+	sf := fld.root.Type().FieldByIndex(fld.index)
+	pm, err := parseMethod(fld.root, fld.index)
+	if err != nil {
+		return err
+	}
+	setFunc := a.p.setValue
+	if pm != nil {
+		// the parser method is given each value
+		setFunc = pm
+	}
+	var partial ErrPartial
+	for _, value := range values {
+		var pe ErrPartial
+		if err := a.p.setTagged(value, sf, fld.fldValue, setFunc); errors.As(err, &pe) {
+			partial.Errs = append(partial.Errs, pe.Errs...)
+		} else if err != nil {
+			return err
+		}
+		if pm == nil {
+			setFunc = a.p.appendValue
+		}
+	}
+	if len(partial.Errs) > 0 {
+		return partial
+	}
+	return nil
+}
+
+// configObject gets the given config value as an object, if it is one.
+// Some decoders, such as for YAML, give objects keyed by interface{}, these have their keys formatted as strings.
+func configObject(v interface{}) (map[string]interface{}, bool) {
+	switch obj := v.(type) {
+	case map[string]interface{}:
+		return obj, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(obj))
+		for k, v := range obj {
+			m[fmt.Sprint(k)] = v
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+// configStrings gets the given config value as the flag values setting it.
+// Arrays are a value for each element and objects a 'key=value' value for each of their keys, in key order.
+// When given a delimiter, the values of arrays and objects have any delimiters within them escaped.
+func configStrings(v interface{}, delim string) ([]string, error) {
+	if obj, ok := configObject(v); ok {
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]string, 0, len(keys))
+		for _, k := range keys {
+			s, err := configString(obj[k])
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, escapeConfigString(strings.Join([]string{k, s}, "="), delim))
+		}
+		return values, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		values := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			s, err := configString(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, escapeConfigString(s, delim))
+		}
+		return values, nil
+	}
+	s, err := configString(v)
+	if err != nil {
+		return nil, err
+	}
+	return []string{s}, nil
+}
+
+// escapeConfigString escapes the given delimiter within an array element or object entry, or not, if the delimiter is empty.
+func escapeConfigString(s, delim string) string {
+	if delim == "" {
+		return s
+	}
+	return escapeDelimited(s, delim)
+}
+
+// configString formats a single config value as a flag value.
+func configString(v interface{}) (string, error) {
+	switch tv := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return tv, nil
+	case float64:
+		return strconv.FormatFloat(tv, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(tv), 'f', -1, 32), nil
+	case time.Time:
+		return tv.Format(time.RFC3339Nano), nil
+	case bool, int, int64, int32, uint, uint64, uint32, json.Number:
+		return fmt.Sprint(tv), nil
+	}
+	return "", fmt.Errorf("unsupported config value %v", v)
+}
@@ -0,0 +1,47 @@
+package argflags
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type configFlags struct {
+	Config string   `flag:"config,config"`
+	Host   string   `flag:"host" default:"localhost"`
+	Port   int      `flag:"port" env:"ARGFLAGS_TEST_PORT"`
+	Tags   []string `flag:"tag"`
+	DB     *dbOpts  `flag:"+db"`
+}
+
+func TestConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"host": "file", "port": 1, "tag": ["a,b", "c"], "db": {"host": "dbhost"}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ARGFLAGS_TEST_PORT", "2")
+	var cf configFlags
+	res, err := NewParser().Apply([]string{"-config", path, "-host", "flag"}, &cf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if cf.Host != "flag" || cf.Port != 2 || !reflect.DeepEqual(cf.Tags, []string{"a,b", "c"}) || cf.DB == nil || cf.DB.Host != "dbhost" {
+		t.Errorf("unexpected flags %+v, %+v", cf, cf.DB)
+	}
+	if sf, _ := res.Lookup("Tags"); sf.Source != SourceConfig {
+		t.Errorf("expected Tags set from the config file, got %+v", sf)
+	}
+}
+
+func TestConfigFileUnknownKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"hots": "x"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var cf configFlags
+	if err := LoadConfig(path, &cf); err == nil {
+		t.Errorf("expected an error for an unknown key")
+	}
+}
@@ -0,0 +1,52 @@
+package argflags
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// ContentType is a MIME media type, such as 'text/html; charset=utf-8', validated with mime.ParseMediaType.
+// The media type is held in lower case, with any parameters preserved.
+// As parameters are separated by semicolons, slices of ContentType may use the default comma delimiter.
+type ContentType struct {
+	MediaType string
+	Params    map[string]string
+}
+
+// Type gets the top level type of the media type, e.g. 'text' of 'text/html'.
+func (ct ContentType) Type() string {
+	t, _, _ := strings.Cut(ct.MediaType, "/")
+	return t
+}
+
+// SubType gets the sub type of the media type, e.g. 'html' of 'text/html'.
+func (ct ContentType) SubType() string {
+	_, st, _ := strings.Cut(ct.MediaType, "/")
+	return st
+}
+
+// String gets the content type, formatted with its parameters, as used in a Content-Type header.
+func (ct ContentType) String() string {
+	return mime.FormatMediaType(ct.MediaType, ct.Params)
+}
+
+func (ct ContentType) MarshalText() ([]byte, error) {
+	return []byte(ct.String()), nil
+}
+
+func (ct *ContentType) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	mt, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		return fmt.Errorf("invalid content type %q  %v", s, err)
+	}
+	if t, st, ok := strings.Cut(mt, "/"); !ok || t == "" || st == "" {
+		return fmt.Errorf("invalid content type %q, expected type/subtype", s)
+	}
+	if len(params) == 0 {
+		params = nil
+	}
+	*ct = ContentType{MediaType: mt, Params: params}
+	return nil
+}
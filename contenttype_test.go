@@ -0,0 +1,37 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContentType(t *testing.T) {
+	var ct ContentType
+	if err := ct.UnmarshalText([]byte(" Text/HTML; Charset=utf-8 ")); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if ct.MediaType != "text/html" || ct.Type() != "text" || ct.SubType() != "html" ||
+		!reflect.DeepEqual(ct.Params, map[string]string{"charset": "utf-8"}) {
+		t.Errorf("expected the lower case media type and its parameters, got %+v", ct)
+	}
+	if ct.String() != "text/html; charset=utf-8" {
+		t.Errorf("expected the header form, got %s", ct)
+	}
+	for _, s := range []string{"", "text", "text/", "/html", "text/html; charset"} {
+		if err := ct.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestContentTypeSlice(t *testing.T) {
+	var flags struct {
+		Accept []ContentType `flag:"accept"`
+	}
+	if _, err := NewParser().Apply([]string{"-accept", "application/json;q=0.9,text/plain"}, &flags); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if len(flags.Accept) != 2 || flags.Accept[0].Params["q"] != "0.9" || flags.Accept[1].MediaType != "text/plain" {
+		t.Errorf("expected both content types, got %+v", flags.Accept)
+	}
+}
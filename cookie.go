@@ -0,0 +1,79 @@
+package argflags
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Cookie is an HTTP cookie, given in the form of a Set-Cookie header, e.g. 'session=abc123; Path=/; Secure'
+type Cookie struct {
+	http.Cookie
+}
+
+func (c Cookie) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Cookie) UnmarshalText(text []byte) error {
+	hc, err := parseCookie(string(text))
+	if err != nil {
+		return err
+	}
+	c.Cookie = *hc
+	return nil
+}
+
+// Cookies are HTTP cookies, each given in the form of a Set-Cookie header.
+// A cookies flag given more than once accumulates each cookie.
+// e.g. -cookie 'session=abc123; Path=/; Secure' -cookie 'theme=dark'
+// As cookie attributes, such as Expires, may contain commas, each flag value is a single cookie.
+type Cookies []*http.Cookie
+
+// String gets the cookies as a Cookie header, of their names and values, e.g. 'session=abc123; theme=dark'
+func (cs Cookies) String() string {
+	pairs := make([]string, len(cs))
+	for i, c := range cs {
+		pairs[i] = (&http.Cookie{Name: c.Name, Value: c.Value}).String()
+	}
+	return strings.Join(pairs, "; ")
+}
+
+func (cs Cookies) MarshalText() ([]byte, error) {
+	return []byte(cs.String()), nil
+}
+
+// MarshalArgs gets a flag for each cookie, in the form of a Set-Cookie header, with the first of the given names.
+func (cs Cookies) MarshalArgs(names []string) ([]string, error) {
+	args := make([]string, len(cs))
+	for i, c := range cs {
+		args[i] = fmt.Sprintf("-%s=%s", names[0], c.String())
+	}
+	return args, nil
+}
+
+func (cs *Cookies) UnmarshalText(text []byte) error {
+	*cs = nil
+	return cs.AddText(text)
+}
+
+// AddText adds the given cookie to the existing cookies.
+func (cs *Cookies) AddText(text []byte) error {
+	c, err := parseCookie(string(text))
+	if err != nil {
+		return err
+	}
+	*cs = append(*cs, c)
+	return nil
+}
+
+// parseCookie parses the given Set-Cookie header value, with the parsing of an http.Response.
+func parseCookie(s string) (*http.Cookie, error) {
+	s = strings.TrimSpace(s)
+	resp := http.Response{Header: http.Header{"Set-Cookie": {s}}}
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		return nil, fmt.Errorf("invalid cookie %q, expected 'name=value' followed by any '; attributes'", s)
+	}
+	return cookies[0], nil
+}
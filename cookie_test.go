@@ -0,0 +1,35 @@
+package argflags
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCookies(t *testing.T) {
+	var flags struct {
+		Session Cookie  `flag:"session"`
+		Cookies Cookies `flag:"cookie"`
+	}
+	args := []string{"-session", "id=abc; Path=/; Secure", "-cookie", "a=1", "-cookie", "b=2; Expires=Wed, 21 Oct 2026 07:28:00 GMT"}
+	if _, err := ArgFlags(args).ApplyTo(&flags); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if flags.Session.Name != "id" || flags.Session.Value != "abc" || flags.Session.Path != "/" || !flags.Session.Secure {
+		t.Errorf("expected the cookie attributes, got %+v", flags.Session)
+	}
+	if len(flags.Cookies) != 2 || flags.Cookies[1].Expires.Year() != 2026 {
+		t.Errorf("expected each flag to be a whole cookie, got %v", flags.Cookies)
+	}
+	if s := flags.Cookies.String(); s != "a=1; b=2" {
+		t.Errorf("expected a Cookie header, got %q", s)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	req.AddCookie(&flags.Session.Cookie)
+	if c, err := req.Cookie("id"); err != nil || c.Value != "abc" {
+		t.Errorf("expected the cookie to be usable as an http.Cookie, got %v", err)
+	}
+	var cs Cookies
+	if err := cs.UnmarshalText([]byte("; Path=/")); err == nil {
+		t.Errorf("expected a cookie without a name to be an error")
+	}
+}
@@ -0,0 +1,50 @@
+package argflags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// optCount marks an integer field as a count of the times its flag is given, e.g. Verbose int `flag:"v,count"`
+// Count flags take no value, each occurrence adds one to the field, so '-v -v -v' sets 3.
+// Single letter count flags may also be repeated in one argument, so '-vvv' also sets 3.
+// A count may be set directly, by attaching a value with an '=', e.g. '-v=3'
+const optCount = "count"
+
+// isCount checks if the field is tagged with the count option.
+func (ff flagField) isCount() bool {
+	return ff.p.hasTagOption(ff.root.Type().FieldByIndex(ff.index), optCount)
+}
+
+// findCountRun finds the count field for a flag name made of a single letter flag repeated, e.g. 'vvv'.
+// returns the field and the number of times its letter is repeated, or nil if the name is not a repeated count flag.
+func (a *applier) findCountRun(name string) (*flagField, int) {
+	if len(name) < 2 || strings.Repeat(name[:1], len(name)) != name {
+		return nil, 0
+	}
+	fld := a.findFlagField(name[:1])
+	if fld == nil || !fld.isCount() {
+		return nil, 0
+	}
+	return fld, len(name)
+}
+
+// addCount adds the given count to the given integer field.
+func addCount(fld reflect.Value, count int) error {
+	if fld.Kind() == reflect.Ptr {
+		if fld.IsNil() {
+			fld.Set(reflect.New(fld.Type().Elem()))
+		}
+		fld = fld.Elem()
+	}
+	switch fld.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		fld.SetInt(fld.Int() + int64(count))
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		fld.SetUint(fld.Uint() + uint64(count))
+	default:
+		return fmt.Errorf("count flags must be an integer field, not %s", fld.Type().String())
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package argflags
+
+import "testing"
+
+type countFlags struct {
+	Verbose int  `flag:"v,verbose,count"`
+	Quiet   bool `flag:"q"`
+}
+
+func TestCountFlags(t *testing.T) {
+	tests := map[string]struct {
+		args   []string
+		expect int
+	}{
+		"repeated":   {args: []string{"-v", "-v", "--verbose"}, expect: 3},
+		"run":        {args: []string{"-vvv"}, expect: 3},
+		"set":        {args: []string{"-v=5"}, expect: 5},
+		"with value": {args: []string{"-v", "2"}, expect: 1},
+	}
+	for name, test := range tests {
+		var cf countFlags
+		if _, err := NewParser().Apply(test.args, &cf); err != nil {
+			t.Errorf("%s  unexpected error  %v", name, err)
+			continue
+		}
+		if cf.Verbose != test.expect {
+			t.Errorf("%s  expected %d, got %d", name, test.expect, cf.Verbose)
+		}
+	}
+}
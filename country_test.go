@@ -0,0 +1,32 @@
+package argflags
+
+import "testing"
+
+func TestCountry(t *testing.T) {
+	tests := map[string]Country{
+		"gb":  "GB",
+		"GBR": "GB",
+		"uk":  "GB",
+		"nld": "NL",
+		"US":  "US",
+	}
+	for s, expect := range tests {
+		var c Country
+		if err := c.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if c != expect {
+			t.Errorf("%s  expected %s, got %s", s, expect, c)
+		}
+	}
+	if c := Country("NL"); c.Alpha2() != "NL" || c.Alpha3() != "NLD" {
+		t.Errorf("expected NL and NLD, got %s, %s", c.Alpha2(), c.Alpha3())
+	}
+	for _, s := range []string{"", "XX", "XXX", "United Kingdom"} {
+		var c Country
+		if err := c.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package argflags
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// secretValue is implemented by the flag types which are secret by default, masked as if tagged with the 'secret' option.
+type secretValue interface {
+	secretValue()
+}
+
+// Credentials are a username and password, given as 'user:pass', as with curl's -u flag.
+// The password follows the first colon, so may contain colons itself.  When only the user is given, the password is empty.
+// Credentials flags are always secret, their values are masked wherever flag values are recorded, without a 'secret' tag option.
+type Credentials struct {
+	Username string
+	Password string
+	// HasPassword is set when the credentials were given with a password, even an empty one, e.g. 'user:'
+	HasPassword bool
+}
+
+// BasicAuth gets the value of an Authorization header for the credentials, using basic authentication.
+func (c Credentials) BasicAuth() string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password))
+}
+
+// String gets the username, with the password masked.
+func (c Credentials) String() string {
+	if !c.HasPassword {
+		return c.Username
+	}
+	return strings.Join([]string{c.Username, secretMask}, ":")
+}
+
+func (c *Credentials) UnmarshalText(text []byte) error {
+	user, pass, hasPass := strings.Cut(string(text), ":")
+	if user == "" {
+		return fmt.Errorf("invalid credentials, expected user:password")
+	}
+	*c = Credentials{Username: user, Password: pass, HasPassword: hasPass}
+	return nil
+}
+
+func (c Credentials) secretValue() {}
@@ -0,0 +1,41 @@
+package argflags
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestCredentials(t *testing.T) {
+	var flags struct {
+		User Credentials `flag:"u"`
+	}
+	res, err := NewParser().Apply([]string{"-u", "bob:pa:ss"}, &flags)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if flags.User != (Credentials{Username: "bob", Password: "pa:ss", HasPassword: true}) {
+		t.Errorf("expected the password to follow the first colon, got %+v", flags.User)
+	}
+	if res.Occurrences[0].Value != secretMask {
+		t.Errorf("expected credentials to be secret without a tag option, got %q", res.Occurrences[0].Value)
+	}
+	if flags.User.String() != "bob:"+secretMask {
+		t.Errorf("expected the password to be masked, got %s", flags.User)
+	}
+	if flags.User.BasicAuth() != "Basic "+base64.StdEncoding.EncodeToString([]byte("bob:pa:ss")) {
+		t.Errorf("expected basic authentication, got %s", flags.User.BasicAuth())
+	}
+}
+
+func TestCredentialsWithoutPassword(t *testing.T) {
+	var c Credentials
+	if err := c.UnmarshalText([]byte("bob")); err != nil || c.HasPassword || c.String() != "bob" {
+		t.Errorf("expected only the user, got %+v, %v", c, err)
+	}
+	if err := c.UnmarshalText([]byte("bob:")); err != nil || !c.HasPassword || c.Password != "" {
+		t.Errorf("expected an empty password, got %+v, %v", c, err)
+	}
+	if err := c.UnmarshalText([]byte(":pass")); err == nil {
+		t.Errorf("expected credentials without a user to be an error")
+	}
+}
@@ -0,0 +1,41 @@
+package argflags
+
+import (
+	"fmt"
+)
+
+// DefaultTagName is the tag giving the default value of a flag field, applied when the flag is not given.
+// e.g. Port int `flag:"port" default:"8080"`
+const DefaultTagName = "default"
+
+// applyDefaults sets the default value, of every field with a default tag, which has not been set by a flag.
+// Fields within a nil sub arg, or a sub arg which has not been activated, are not set.
+func (a *applier) applyDefaults() error {
+	for _, target := range a.targets {
+		for _, fd := range a.p.describeFlags(target.value.Type()) {
+			def, ok := fd.field.Tag.Lookup(DefaultTagName)
+			if !ok || target.hidden[indexKey(fd.index)] {
+				continue
+			}
+			fld, ok := a.p.fieldInUse(target.value, fd.index)
+			if !ok {
+				continue
+			}
+			key := keyOfField(fld)
+			if a.isApplied[key] || a.preset[key] || a.isFallback[key] {
+				continue
+			}
+			setFunc := a.p.setValue
+			if pm, err := parseMethod(target.value, fd.index); err != nil {
+				return fmt.Errorf("default for -%s  %v", fd.names[0], err)
+			} else if pm != nil {
+				setFunc = pm
+			}
+			if err := a.p.setTagged(def, fd.field, fld, setFunc); err != nil {
+				return fmt.Errorf("default for -%s  %v", fd.names[0], err)
+			}
+			a.setFrom(SourceDefault, fd.field.Name, &flagField{p: a.p, fldValue: fld, root: target.value, index: fd.index}, def)
+		}
+	}
+	return nil
+}
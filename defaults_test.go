@@ -0,0 +1,60 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type defaultFlags struct {
+	Port    int               `flag:"port" default:"8080"`
+	Tags    []string          `flag:"tag" default:"a,b"`
+	Timeout time.Duration     `flag:"timeout" default:"30s"`
+	Labels  map[string]string `flag:"label" default:"app=web"`
+	Host    string            `flag:"host" env:"ARGFLAGS_TEST_HOST" default:"localhost"`
+}
+
+func TestDefaults(t *testing.T) {
+	var df defaultFlags
+	res, err := NewParser().Apply([]string{"-port", "9000"}, &df)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := defaultFlags{Port: 9000, Tags: []string{"a", "b"}, Timeout: 30 * time.Second,
+		Labels: map[string]string{"app": "web"}, Host: "localhost"}
+	if !reflect.DeepEqual(df, expect) {
+		t.Errorf("expected %+v, got %+v", expect, df)
+	}
+	if sf, ok := res.Lookup("Tags"); !ok || sf.Source != SourceDefault || res.IsSet("Tags") {
+		t.Errorf("expected Tags to be set from its default, got %+v", sf)
+	}
+}
+
+func TestEnvFallback(t *testing.T) {
+	t.Setenv("ARGFLAGS_TEST_HOST", "example.com")
+	var df defaultFlags
+	res, err := NewParser().Apply(nil, &df)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if df.Host != "example.com" {
+		t.Errorf("expected the environment to take precedence over the default, got %q", df.Host)
+	}
+	if sf, _ := res.Lookup("Host"); sf.Source != SourceEnv || sf.Name != "$ARGFLAGS_TEST_HOST" {
+		t.Errorf("expected Host set from the environment, got %+v", sf)
+	}
+	if _, err := NewParser().Apply([]string{"-host", "flag.com"}, &df); err != nil || df.Host != "flag.com" {
+		t.Errorf("expected the flag to take precedence over the environment, got %q  %v", df.Host, err)
+	}
+}
+
+func TestEnvFrom(t *testing.T) {
+	df := defaultFlags{Host: "example.com"}
+	envs, err := EnvFrom(&df)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(envs, []string{"ARGFLAGS_TEST_HOST=example.com"}) {
+		t.Errorf("expected the host variable, got %v", envs)
+	}
+}
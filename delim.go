@@ -0,0 +1,99 @@
+package argflags
+
+import (
+	"reflect"
+	"strings"
+)
+
+// DelimTagName is the tag giving the delimiter of the values of a slice or map field, in place of the parser's delimiter.
+// e.g. Queries []string `flag:"query" delim:";"` sets two queries from '-query "a,b;c"', being 'a,b' and 'c'.
+// In any delimited value, a delimiter preceded by a backslash is part of the value, rather than a delimiter,
+// e.g. '-tag a\,b,c' sets 'a,b' and 'c'.  Other backslashes are kept as they are, so '-share \\server\share' is unchanged.
+// ToArgs, EnvFrom and Sprint escape the delimiters within values in this way.
+const DelimTagName = "delim"
+
+// delimiterOf gets the delimiter of the given field, from its delim tag, or the parser's delimiter.
+func (p *Parser) delimiterOf(f reflect.StructField) string {
+	if d, ok := f.Tag.Lookup(DelimTagName); ok && d != "" {
+		return d
+	}
+	return p.delimiter
+}
+
+// isDelimitedType checks if the given type is set from delimited values, being a slice or map, not set as a whole.
+func isDelimitedType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Map {
+		return false
+	}
+	return !isWholeValue(reflect.New(t).Elem())
+}
+
+// redelimit converts the given value, delimited with the delim tag of the given field, to be delimited with the parser's delimiter.
+// Values of fields without a delim tag are returned as they are.
+func (p *Parser) redelimit(f reflect.StructField, value string) string {
+	d := p.delimiterOf(f)
+	if _, hasParser := f.Tag.Lookup(ParserTagName); hasParser || d == p.delimiter || !isDelimitedType(f.Type) {
+		return value
+	}
+	return joinDelimited(splitDelimited(value, d), p.delimiter)
+}
+
+// cutDelimited cuts the given value at its first delimiter, not escaped with a backslash,
+// returning the value before it, with its escapes removed, and the value after it.
+func cutDelimited(value, delim string) (before, after string, found bool) {
+	if !strings.Contains(value, `\`) {
+		return strings.Cut(value, delim)
+	}
+	buf := &strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && strings.HasPrefix(value[i+1:], delim):
+			buf.WriteString(delim)
+			i += len(delim)
+		case strings.HasPrefix(value[i:], delim):
+			return buf.String(), value[i+len(delim):], true
+		default:
+			buf.WriteByte(value[i])
+		}
+	}
+	return buf.String(), "", false
+}
+
+// countDelimited counts the delimiters, not escaped with a backslash, in the given value.
+func countDelimited(value, delim string) int {
+	count := 0
+	for found := true; found; count++ {
+		_, value, found = cutDelimited(value, delim)
+	}
+	return count - 1
+}
+
+// splitDelimited splits the given value at each delimiter not escaped with a backslash, removing the escapes.
+func splitDelimited(value, delim string) []string {
+	var values []string
+	for {
+		s, rest, found := cutDelimited(value, delim)
+		values = append(values, s)
+		if !found {
+			return values
+		}
+		value = rest
+	}
+}
+
+// escapeDelimited escapes, with a backslash, each delimiter in the given value.
+func escapeDelimited(value, delim string) string {
+	return strings.ReplaceAll(value, delim, `\`+delim)
+}
+
+// joinDelimited joins the given values with the given delimiter, escaping the delimiters within them.
+func joinDelimited(values []string, delim string) string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escapeDelimited(value, delim)
+	}
+	return strings.Join(escaped, delim)
+}
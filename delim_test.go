@@ -0,0 +1,89 @@
+package argflags
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type delimFlags struct {
+	Tags    []string          `flag:"tag"`
+	Shares  []string          `flag:"share"`
+	Queries []string          `flag:"query" delim:";"`
+	Labels  map[string]string `flag:"label"`
+}
+
+func TestEscapedDelimiter(t *testing.T) {
+	var df delimFlags
+	if _, err := NewParser().Apply([]string{"-tag", `a\,b,c`}, &df); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(df.Tags, []string{"a,b", "c"}) {
+		t.Errorf("expected [a,b c], got %q", df.Tags)
+	}
+}
+
+func TestBackslashesKept(t *testing.T) {
+	var df delimFlags
+	if _, err := NewParser().Apply([]string{"-share", `\\server\share,c:\temp\`, "-label", `dir=c:\\x`}, &df); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(df.Shares, []string{`\\server\share`, `c:\temp\`}) {
+		t.Errorf("expected backslashes kept, got %q", df.Shares)
+	}
+	if df.Labels["dir"] != `c:\\x` {
+		t.Errorf("expected backslashes kept, got %q", df.Labels["dir"])
+	}
+}
+
+func TestDelimTag(t *testing.T) {
+	var df delimFlags
+	if _, err := NewParser().Apply([]string{"-query", `a,b;c\;d`}, &df); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(df.Queries, []string{"a,b", "c;d"}) {
+		t.Errorf("expected [a,b c;d], got %q", df.Queries)
+	}
+}
+
+func TestJoinDelimitedRoundTrip(t *testing.T) {
+	values := []string{`\\server\share`, "a,b"}
+	for _, delim := range []string{",", ";", "::"} {
+		joined := joinDelimited(values, delim)
+		if got := splitDelimited(joined, delim); !reflect.DeepEqual(got, values) {
+			t.Errorf("delimiter %q  expected %q, got %q", delim, values, got)
+		}
+	}
+}
+
+func TestRequestArraysUseFlagDelimiter(t *testing.T) {
+	var df delimFlags
+	cmd := &Command{Flags: &df, Handler: func(ctx context.Context, inv *Invocation) error {
+		return nil
+	}}
+	resp := cmd.ExecuteRequest(context.Background(), Request{Options: map[string]interface{}{
+		"query": []interface{}{"a,b", "c"},
+		"tag":   []interface{}{"d;e", "f,g"},
+	}})
+	if resp.Error != "" {
+		t.Fatalf("unexpected error  %s", resp.Error)
+	}
+	if !reflect.DeepEqual(df.Queries, []string{"a,b", "c"}) {
+		t.Errorf("expected [a,b c], got %q", df.Queries)
+	}
+	if !reflect.DeepEqual(df.Tags, []string{"d;e", "f,g"}) {
+		t.Errorf("expected [d;e f,g], got %q", df.Tags)
+	}
+}
+
+func TestDelimTagOnCommaValues(t *testing.T) {
+	var v struct {
+		Sets []CPUSet `flag:"cpus" delim:";"`
+	}
+	if _, err := NewParser().Apply([]string{"-cpus", "0-3,8;1,5"}, &v); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if len(v.Sets) != 2 || !reflect.DeepEqual(v.Sets[0].CPUs(), []int{0, 1, 2, 3, 8}) || !reflect.DeepEqual(v.Sets[1].CPUs(), []int{1, 5}) {
+		t.Errorf("expected two cpu sets, got %v", v.Sets)
+	}
+}
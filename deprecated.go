@@ -0,0 +1,100 @@
+package argflags
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// DeprecatedTagName is the tag marking every name of a flag as deprecated, giving the message warned when it is given.
+// e.g. Legacy bool `flag:"legacy" deprecated:"it is always on"`
+// A single name of a flag may be deprecated, whilst its other names are not, by suffixing it with a '!' in the flag tag.
+// e.g. Timeout time.Duration `flag:"timeout,old-timeout!"` warns '-old-timeout' is deprecated, use -timeout instead.
+// Deprecated flags continue to be applied, with a warning written to the warning output of the parser,
+// and added to the Warnings of the Result, once for each deprecated name given.
+// Deprecated names are not listed in the usage of the flags, and flags with a deprecated tag are shown as deprecated.
+const DeprecatedTagName = "deprecated"
+
+// deprecatedSuffix suffixes a deprecated name in a flag tag.
+const deprecatedSuffix = "!"
+
+// WithWarningOutput sets where warnings, such as for deprecated flags, are written, in place of stderr.
+// A nil writer discards the warnings, which are still reported in the Result.
+func WithWarningOutput(w io.Writer) Option {
+	return func(p *Parser) {
+		p.warnings = w
+		if w == nil {
+			p.warnings = io.Discard
+		}
+	}
+}
+
+// warningOutput gets the writer warnings are written to.
+func (p *Parser) warningOutput() io.Writer {
+	if p.warnings == nil {
+		return os.Stderr
+	}
+	return p.warnings
+}
+
+// isDeprecatedName checks if the given name, in a flag tag, is marked as deprecated.
+func isDeprecatedName(tag string) bool {
+	return strings.HasSuffix(tag, deprecatedSuffix)
+}
+
+// checkDeprecated warns if the given flag name, matched to the given field, is deprecated.
+// Each deprecated name is only warned once.
+func (a *applier) checkDeprecated(flag string, fld *flagField) {
+	name := strings.TrimLeft(flag, "-")
+	f := fld.root.Type().FieldByIndex(fld.index)
+	msg, deprecated := f.Tag.Lookup(DeprecatedTagName)
+	if !deprecated {
+		if !a.p.isDeprecatedAlias(name, f) {
+			return
+		}
+		if use := a.p.replacementName(fld); use != "" {
+			msg = fmt.Sprintf("use -%s instead", use)
+		}
+	}
+	key := a.p.nameKey(name)
+	for _, w := range a.result.Warnings {
+		if d, ok := w.(ErrDeprecated); ok && a.p.nameKey(strings.TrimLeft(d.Flag, "-")) == key {
+			return
+		}
+	}
+	err := ErrDeprecated{Flag: flag, Message: msg}
+	a.result.Warnings = append(a.result.Warnings, err)
+	fmt.Fprintf(a.p.warningOutput(), "warning: %v\n", err)
+}
+
+// isDeprecatedAlias checks if the given flag name, including any sub arg prefix, is a deprecated name of the given field.
+func (p *Parser) isDeprecatedAlias(name string, f reflect.StructField) bool {
+	key := p.nameKey(name)
+	for _, tag := range strings.Split(f.Tag.Get(p.tagName), ",") {
+		if !isDeprecatedName(tag) {
+			continue
+		}
+		alias := p.nameKey(strings.TrimSuffix(tag, deprecatedSuffix))
+		if key == alias {
+			return true
+		}
+		for _, sep := range subArgSeparators {
+			if strings.HasSuffix(key, sep+alias) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// replacementName gets the first name of the given field which is not deprecated, or empty if it has none.
+func (p *Parser) replacementName(fld *flagField) string {
+	for _, fd := range p.describeFlags(fld.root.Type()) {
+		if indexKey(fd.index) == indexKey(fld.index) {
+			return fd.names[0]
+		}
+	}
+	return ""
+}
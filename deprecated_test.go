@@ -0,0 +1,35 @@
+package argflags
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type deprecatedFlags struct {
+	Color string `flag:"color,colour!"`
+	Old   bool   `flag:"old" deprecated:"it has no effect"`
+}
+
+func TestDeprecatedFlags(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var df deprecatedFlags
+	res, err := NewParser(WithWarningOutput(buf)).Apply([]string{"-colour", "red", "-colour", "blue", "-old", "-color", "green"}, &df)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if df.Color != "green" || !df.Old {
+		t.Errorf("expected deprecated flags to still be set, got %+v", df)
+	}
+	if len(res.Warnings) != 2 || strings.Count(buf.String(), "warning:") != 2 {
+		t.Fatalf("expected a warning for each deprecated flag, once, got %v and %q", res.Warnings, buf.String())
+	}
+	var de ErrDeprecated
+	if !errors.As(res.Warnings[0], &de) || de.Flag != "-colour" || de.Message != "use -color instead" {
+		t.Errorf("unexpected warning %v", res.Warnings[0])
+	}
+	if !strings.Contains(Usage(&df), "deprecated: it has no effect") || strings.Contains(Usage(&df), "colour") {
+		t.Errorf("expected the usage to show the deprecation, without the alias, got\n%s", Usage(&df))
+	}
+}
@@ -0,0 +1,214 @@
+package argflags
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldDiff is a flag field whose value differs between two option structs, as found by Diff.
+type FieldDiff struct {
+	// Field is the dot delimited path of field names, from the struct, to the field. e.g. "Database.Host"
+	// Fields of the elements of grouped sub args include the index of the element, e.g. "Targets.1.Host"
+	Field string
+	// Flag is the first name of the flag, with a leading dash, or empty for a positional field.
+	Flag string
+	// A and B are the values of the field in each struct, formatted as they are given by ToArgs.
+	// A value for each element of a slice, or entry of a map.  A sub arg which is not in use, such as a nil one, has no values.
+	// For a grouped sub arg, with a different number of elements in each struct, they are the number of its elements.
+	A []string
+	B []string
+	// Secret is set for a secret field, whose values in A and B are masked.
+	Secret bool
+}
+
+func (d FieldDiff) String() string {
+	name := d.Field
+	if d.Flag != "" {
+		name = fmt.Sprintf("%s (%s)", d.Field, d.Flag)
+	}
+	return fmt.Sprintf("%s: %s -> %s", name, strings.Join(d.A, ","), strings.Join(d.B, ","))
+}
+
+// Equal checks if the two given option structs, or struct pointers, of the same type, have the same flag values.
+// It compares what Diff compares, so is true when Diff finds no differences.
+// Structs of different types are never equal.
+func Equal(a, b interface{}) bool {
+	return NewParser().Equal(a, b)
+}
+
+// Equal checks if the two given option structs have the same flag values, as named by the parser.  See Equal.
+func (p *Parser) Equal(a, b interface{}) bool {
+	va, vb, ok := diffValues(a, b)
+	return ok && len(p.diffStruct(va, vb, "")) == 0
+}
+
+// Diff gets the flag fields whose values differ between the two given option structs, or struct pointers, of the same type.
+// e.g. to report drift of the options in use from those in a config file.
+// Fields are compared by their formatted values, as ToArgs gives them, in the order they are described in the usage.
+// Fields tagged as `flag:"-"` are ignored, and fields of sub args not in use are compared as having no value.
+// Secret fields are compared by their actual values, but their values are masked in the differences returned.
+// returns an error if the structs are not of the same type.
+func Diff(a, b interface{}) ([]FieldDiff, error) {
+	return NewParser().Diff(a, b)
+}
+
+// Diff gets the flag fields whose values differ between the two given option structs, as named by the parser.  See Diff.
+func (p *Parser) Diff(a, b interface{}) ([]FieldDiff, error) {
+	va, vb, ok := diffValues(a, b)
+	if !ok {
+		return nil, fmt.Errorf("can not diff %T with %T, flags can only be compared between structs of the same type", a, b)
+	}
+	return p.diffStruct(va, vb, ""), nil
+}
+
+// diffValues gets the struct values of the given structs, or struct pointers, and if they are of the same type.
+func diffValues(a, b interface{}) (reflect.Value, reflect.Value, bool) {
+	va, vb := reflect.Indirect(reflect.ValueOf(a)), reflect.Indirect(reflect.ValueOf(b))
+	if !va.IsValid() || !vb.IsValid() || va.Kind() != reflect.Struct || va.Type() != vb.Type() {
+		return va, vb, false
+	}
+	return va, vb, true
+}
+
+// diffStruct gets the differences between the flag and positional fields of the given structs.
+// parent is the path to the structs, when they are elements of a grouped sub arg, or empty.
+func (p *Parser) diffStruct(a, b reflect.Value, parent string) []FieldDiff {
+	var diffs []FieldDiff
+	for _, fd := range p.describeFlags(a.Type()) {
+		fa, inUseA := p.fieldInUse(a, fd.index)
+		fb, inUseB := p.fieldInUse(b, fd.index)
+		path := fieldNamePath(a.Type(), fd.index)
+		if parent != "" {
+			path = strings.Join([]string{parent, path}, ".")
+		}
+		if p.isGroup(fd.field) {
+			diffs = append(diffs, p.diffGroup(fa, fb, path, "-"+fd.names[0])...)
+			continue
+		}
+		d := FieldDiff{Field: path, Flag: "-" + fd.names[0], Secret: p.isSecretField(fd.field, fa, fb)}
+		if inUseA {
+			d.A = p.diffFormat(fd.field, fa)
+		}
+		if inUseB {
+			d.B = p.diffFormat(fd.field, fb)
+		}
+		if !equalValues(d.A, d.B) || (inUseA && inUseB && !equalFormatted(fa, fb)) {
+			diffs = append(diffs, d.masked())
+		}
+	}
+	fields, _ := positionalFields(a.Type())
+	for _, pf := range fields {
+		if !p.isPositionalOnly(pf.field) {
+			// compared as a flag
+			continue
+		}
+		path := pf.field.Name
+		if parent != "" {
+			path = strings.Join([]string{parent, path}, ".")
+		}
+		fa, fb := a.Field(pf.index), b.Field(pf.index)
+		d := FieldDiff{Field: path, A: p.diffFormat(pf.field, fa), B: p.diffFormat(pf.field, fb), Secret: p.isSecretField(pf.field, fa, fb)}
+		if !equalValues(d.A, d.B) || !equalFormatted(fa, fb) {
+			diffs = append(diffs, d.masked())
+		}
+	}
+	return diffs
+}
+
+// diffGroup gets the differences between the elements of the given grouped sub arg fields,
+// either of which may be invalid, when not in use.
+func (p *Parser) diffGroup(a, b reflect.Value, path, flag string) []FieldDiff {
+	var lenA, lenB int
+	if a.IsValid() {
+		lenA = a.Len()
+	}
+	if b.IsValid() {
+		lenB = b.Len()
+	}
+	var diffs []FieldDiff
+	if lenA != lenB {
+		diffs = append(diffs, FieldDiff{Field: path, Flag: flag, A: []string{strconv.Itoa(lenA)}, B: []string{strconv.Itoa(lenB)}})
+	}
+	zero := reflect.New(groupElemType(a.Type())).Elem()
+	for i := 0; i < lenA || i < lenB; i++ {
+		ea, eb := zero, zero
+		if i < lenA {
+			ea = reflect.Indirect(a.Index(i))
+		}
+		if i < lenB {
+			eb = reflect.Indirect(b.Index(i))
+		}
+		if !ea.IsValid() {
+			ea = zero
+		}
+		if !eb.IsValid() {
+			eb = zero
+		}
+		diffs = append(diffs, p.diffStruct(ea, eb, strings.Join([]string{path, strconv.Itoa(i)}, "."))...)
+	}
+	return diffs
+}
+
+// isSecretField checks if the given field is tagged with the secret option, or either of its values is of a secret type.
+func (p *Parser) isSecretField(f reflect.StructField, values ...reflect.Value) bool {
+	if p.hasTagOption(f, optSecret) {
+		return true
+	}
+	for _, v := range values {
+		if !v.IsValid() {
+			continue
+		}
+		if _, ok := v.Interface().(secretValue); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// masked gets the difference with its values masked, if it is secret.
+func (d FieldDiff) masked() FieldDiff {
+	if !d.Secret {
+		return d
+	}
+	d.A, d.B = maskValues(d.A), maskValues(d.B)
+	return d
+}
+
+func maskValues(values []string) []string {
+	masked := make([]string, len(values))
+	for i := range masked {
+		masked[i] = secretMask
+	}
+	return masked
+}
+
+// diffFormat formats the given field as its flag values, or as printed, should it not be formattable as a flag value.
+func (p *Parser) diffFormat(f reflect.StructField, fld reflect.Value) []string {
+	values, err := p.formatFieldValues(f, fld)
+	if err != nil {
+		return []string{fmt.Sprint(fld.Interface())}
+	}
+	return values
+}
+
+// equalFormatted checks the given fields are equal, when they can not be compared by their formatted values.
+func equalFormatted(a, b reflect.Value) bool {
+	if _, err := formatValues(a); err == nil {
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+type diffFlags struct {
+	Host     string   `flag:"host"`
+	Tags     []string `flag:"tag"`
+	Password string   `flag:"password,secret"`
+}
+
+func TestDiff(t *testing.T) {
+	a := diffFlags{Host: "a", Tags: []string{"x,y"}, Password: "one"}
+	b := diffFlags{Host: "a", Tags: []string{"x", "y"}, Password: "two"}
+	diffs, err := Diff(&a, b)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if len(diffs) != 2 || diffs[0].Flag != "-tag" || diffs[1].Flag != "-password" {
+		t.Fatalf("expected the tag and password to differ, got %v", diffs)
+	}
+	if !diffs[1].Secret || reflect.DeepEqual(diffs[1].A, []string{"one"}) {
+		t.Errorf("expected the password to be masked, got %+v", diffs[1])
+	}
+	if Equal(a, b) || !Equal(a, &a) {
+		t.Errorf("expected a to equal only itself")
+	}
+}
+
+func TestDiffDifferentTypes(t *testing.T) {
+	a := diffFlags{Host: "a"}
+	b := serveFlags{Port: 1}
+	if diffs, err := Diff(a, b); err == nil {
+		t.Errorf("expected an error, got %v", diffs)
+	}
+	if _, err := Diff(a, nil); err == nil {
+		t.Errorf("expected an error diffing with nil")
+	}
+	if Equal(a, b) {
+		t.Errorf("expected structs of different types not to be equal")
+	}
+}
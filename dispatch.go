@@ -0,0 +1,137 @@
+package argflags
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// CommandTagName is the tag naming a field as a sub command, selected by the first non flag argument matching its name.
+// Command fields must be a struct or a pointer to a struct, which the arguments following the command name are applied to.
+// e.g. Serve *ServeOpts `command:"serve"`  is selected with the argument 'serve'.
+// As with sub commands, the flags of the containing struct are inherited by the command field.
+// Command fields are not flags and never match a flag name.
+const CommandTagName = "command"
+
+// Runner is implemented by command structs, to perform their command with the arguments remaining after the flags are applied.
+type Runner interface {
+	Run(ctx context.Context, args []string) error
+}
+
+// Dispatch is the command selected by the arguments applied to a struct with command fields.
+type Dispatch struct {
+	// Command is the space delimited names of the command fields selected, or empty if no command was given.
+	Command string
+	// Flags is a pointer to the selected command struct, or the root struct when no command was given.
+	Flags interface{}
+	// Args are the arguments remaining after the command flags were applied.
+	Args []string
+}
+
+// Run runs the selected command, when its struct implements Runner.
+func (d *Dispatch) Run(ctx context.Context) error {
+	r, ok := d.Flags.(Runner)
+	if !ok {
+		if d.Command == "" {
+			return fmt.Errorf("no command given")
+		}
+		return fmt.Errorf("command %s can not be run", d.Command)
+	}
+	return r.Run(ctx, d.Args)
+}
+
+// Dispatch applies the arguments to the given struct pointer, selecting a command field with the first non flag argument,
+// which the remaining arguments are then applied to.  Command fields may have command fields of their own.
+// A struct with command fields requires one of its commands to be given.
+// Unknown commands produce an error, suggesting the closest command name.
+// Selected command fields which are nil pointers are instantiated.
+// When -h or --help is given, the help of the selected command is written to Output, (stdout by default) and ErrHelp is returned.
+func (args ArgFlags) Dispatch(str interface{}) (*Dispatch, error) {
+	var selected *Dispatch
+	root, err := newStructCommand("", str, func(ctx context.Context, inv *Invocation) error {
+		selected = &Dispatch{Command: inv.Command.Path(), Flags: inv.Flags, Args: inv.Args}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := root.Execute(context.Background(), args); err != nil {
+		return nil, err
+	}
+	return selected, nil
+}
+
+// NewStructCommand creates a Command from the given struct pointer, with a sub command for each of its command fields.
+// The handler of each command calls the Run method of its struct, if it implements Runner.
+func NewStructCommand(name string, str interface{}) (*Command, error) {
+	return newStructCommand(name, str, func(ctx context.Context, inv *Invocation) error {
+		d := &Dispatch{Command: inv.Command.Path(), Flags: inv.Flags, Args: inv.Args}
+		return d.Run(ctx)
+	})
+}
+
+func newStructCommand(name string, str interface{}, handler Handler) (*Command, error) {
+	v, err := getStructValue(str)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &Command{Name: name, Flags: str, Handler: handler}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || !isCommandField(f) {
+			continue
+		}
+		sub, err := newCommandField(v.Field(i), f, handler)
+		if err != nil {
+			return nil, fmt.Errorf("%s  %v", t.String(), err)
+		}
+		cmd.AddCommand(sub)
+	}
+	if len(cmd.commands) > 0 {
+		// a struct with commands requires one to be selected
+		cmd.Handler = nil
+	}
+	return cmd, nil
+}
+
+// newCommandField creates the sub command for the given command field.
+// A nil pointer field is given a new instance, which is only set in the field if the command is invoked.
+func newCommandField(fld reflect.Value, f reflect.StructField, handler Handler) (*Command, error) {
+	name := f.Tag.Get(CommandTagName)
+	var str interface{}
+	var setField func()
+	switch {
+	case f.Type.Kind() == reflect.Struct:
+		str = fld.Addr().Interface()
+	case isStructPointer(f.Type) && fld.IsNil():
+		nv := reflect.New(f.Type.Elem())
+		str = nv.Interface()
+		setField = func() {
+			fld.Set(nv)
+		}
+	case isStructPointer(f.Type):
+		str = fld.Interface()
+	default:
+		return nil, fmt.Errorf("command field %s is not a struct or pointer to a struct", f.Name)
+	}
+	sub, err := newStructCommand(name, str, handler)
+	if err != nil {
+		return nil, err
+	}
+	if setField != nil {
+		// set the field before the command is handled, so the selected command is not nil.
+		sub.Use(func(next Handler) Handler {
+			return func(ctx context.Context, inv *Invocation) error {
+				setField()
+				return next(ctx, inv)
+			}
+		})
+	}
+	return sub, nil
+}
+
+func isCommandField(f reflect.StructField) bool {
+	name, ok := f.Tag.Lookup(CommandTagName)
+	return ok && name != ""
+}
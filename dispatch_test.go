@@ -0,0 +1,75 @@
+package argflags
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type serveCmd struct {
+	Port int `flag:"port"`
+	args []string
+}
+
+func (s *serveCmd) Run(ctx context.Context, args []string) error {
+	s.args = args
+	return nil
+}
+
+type appCmds struct {
+	Verbose bool      `flag:"verbose"`
+	Serve   *serveCmd `command:"serve"`
+	Config  struct {
+		Show struct{} `command:"show"`
+	} `command:"config"`
+}
+
+func TestDispatch(t *testing.T) {
+	var app appCmds
+	d, err := ArgFlags{"serve", "-port", "80", "-verbose", "x"}.Dispatch(&app)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if d.Command != "serve" || d.Flags != app.Serve || !reflect.DeepEqual(d.Args, []string{"x"}) {
+		t.Fatalf("expected the serve command, got %+v", d)
+	}
+	if app.Serve.Port != 80 || !app.Verbose {
+		t.Errorf("expected the command and inherited flags to be set, got %+v, %+v", app, app.Serve)
+	}
+	if err := d.Run(context.Background()); err != nil || !reflect.DeepEqual(app.Serve.args, []string{"x"}) {
+		t.Errorf("expected the command to be run with its arguments, got %v, %v", app.Serve.args, err)
+	}
+}
+
+func TestDispatchNested(t *testing.T) {
+	var app appCmds
+	d, err := ArgFlags{"config", "show"}.Dispatch(&app)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if d.Command != "config show" || d.Flags != &app.Config.Show || app.Serve != nil {
+		t.Errorf("expected the nested command, without instantiating others, got %+v", d)
+	}
+	if err := d.Run(context.Background()); err == nil {
+		t.Errorf("expected a command without a Run method not to run")
+	}
+}
+
+func TestDispatchErrors(t *testing.T) {
+	var app appCmds
+	_, err := ArgFlags{"serv"}.Dispatch(&app)
+	var uc ErrUnknownCommand
+	if !errors.As(err, &uc) || uc.Suggestion != "serve" {
+		t.Errorf("expected an unknown command suggesting serve, got %v", err)
+	}
+	if _, err := (ArgFlags{"-verbose"}).Dispatch(&app); err == nil {
+		t.Errorf("expected a command to be required")
+	}
+	var bad struct {
+		Serve int `command:"serve"`
+	}
+	if _, err := (ArgFlags{"serve"}).Dispatch(&bad); err == nil {
+		t.Errorf("expected a command field which is not a struct to be an error")
+	}
+}
@@ -0,0 +1,122 @@
+package argflags
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvTagName is the tag naming the environment variable a field falls back to, when its flag is not given.
+// e.g. Host string `flag:"host" env:"MYAPP_HOST"`
+// Values from the environment take precedence over any default tag value.
+const EnvTagName = "env"
+
+// EnvPrefix, when set, is prefixed to every environment variable name given in an env tag.
+// e.g. with an EnvPrefix of "MYAPP_", the tag env:"HOST" reads the MYAPP_HOST variable.
+var EnvPrefix string
+
+// applyEnv sets the value, of every field with an env tag, which has not been set by a flag, from its environment variable.
+// Fields within a nil sub arg, or a sub arg which has not been activated, are not set.
+func (a *applier) applyEnv() error {
+	for _, target := range a.targets {
+		for _, fd := range a.p.describeFlags(target.value.Type()) {
+			name, ok := fd.field.Tag.Lookup(EnvTagName)
+			if !ok || name == "" || target.hidden[indexKey(fd.index)] {
+				continue
+			}
+			value, ok := os.LookupEnv(a.p.envPrefix + name)
+			if !ok {
+				continue
+			}
+			fld, ok := a.p.fieldInUse(target.value, fd.index)
+			if !ok {
+				continue
+			}
+			key := keyOfField(fld)
+			if a.isApplied[key] || a.preset[key] {
+				continue
+			}
+			ff := &flagField{p: a.p, fldValue: fld, root: target.value, index: fd.index}
+			setFunc := a.p.setValue
+			if pm, err := parseMethod(target.value, fd.index); err != nil {
+				return fmt.Errorf("$%s%s  %v", a.p.envPrefix, name, err)
+			} else if pm != nil {
+				setFunc = pm
+			}
+			if err := a.p.setTagged(value, fd.field, fld, setFunc); err != nil && !a.warned("$"+a.p.envPrefix+name, value, ff, err) {
+				return fmt.Errorf("$%s%s  %v", a.p.envPrefix, name, err)
+			}
+			a.isFallback[key] = true
+			a.setFrom(SourceEnv, "$"+a.p.envPrefix+name, ff, value)
+		}
+	}
+	return nil
+}
+
+// EnvFrom gets the environment variables, as KEY=VALUE, which would set the current values of the given struct pointer.
+// It is the mirror of the env tags, so a process can hand its options to a child process through its environment.
+// e.g. cmd.Env = append(os.Environ(), envs...)
+// Every field with an env tag, and a non zero value or a default tag, is given, prefixed with the EnvPrefix.
+// Slices and maps are given as delimited values, with any delimiters within them escaped.
+// As with ToArgs, secret fields are given with their actual values.
+func EnvFrom(str interface{}) ([]string, error) {
+	return NewParser().EnvFrom(str)
+}
+
+// EnvFrom gets the environment variables which would set the current values of the given struct pointer,
+// with the env prefix and delimiter of the parser.  See EnvFrom.
+func (p *Parser) EnvFrom(str interface{}) ([]string, error) {
+	evs, err := p.envValues(str)
+	if err != nil {
+		return nil, err
+	}
+	envs := make([]string, len(evs))
+	for i, ev := range evs {
+		envs[i] = strings.Join([]string{ev.name, ev.value}, "=")
+	}
+	return envs, nil
+}
+
+// envValue is an environment variable, setting a field of a struct.
+type envValue struct {
+	// name is the variable name, including the env prefix.
+	name  string
+	value string
+	// secret is set when the field is secret.
+	secret bool
+}
+
+// envValues gets the environment variables which would set the current values of the given struct pointer, see EnvFrom.
+func (p *Parser) envValues(str interface{}) ([]envValue, error) {
+	v, err := getStructValue(str)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.Schema(v.Type()); err != nil {
+		return nil, err
+	}
+	var evs []envValue
+	for _, fd := range p.describeFlags(v.Type()) {
+		name, ok := fd.field.Tag.Lookup(EnvTagName)
+		if !ok || name == "" {
+			continue
+		}
+		fld, ok := p.fieldInUse(*v, fd.index)
+		if !ok {
+			continue
+		}
+		if _, hasDefault := fd.field.Tag.Lookup(DefaultTagName); fld.IsZero() && !hasDefault {
+			continue
+		}
+		values, err := p.formatFieldValues(fd.field, fld)
+		if err != nil {
+			return nil, fmt.Errorf("$%s%s  %v", p.envPrefix, name, err)
+		}
+		value := strings.Join(values, p.delimiter)
+		if isDelimitedType(fd.field.Type) {
+			value = joinDelimited(values, p.delimiterOf(fd.field))
+		}
+		evs = append(evs, envValue{name: p.envPrefix + name, value: value, secret: p.isSecretField(fd.field, fld)})
+	}
+	return evs, nil
+}
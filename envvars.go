@@ -0,0 +1,72 @@
+package argflags
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvVars are environment variables for a child process, as 'KEY=VALUE' strings, suitable for exec.Cmd.Env.
+// As with docker's -e flag, each flag value is a single 'KEY=VALUE', or a 'KEY' alone, which inherits the
+// value of KEY from the environment of this process, or is left out if KEY is not set.
+// A flag given more than once accumulates each variable, a variable given again replaces its earlier value.
+// e.g. -e DEBUG=1 -e HOME -e 'GREETING=hello, world'
+type EnvVars []string
+
+// Lookup gets the value of the given variable, and if it is set.
+func (ev EnvVars) Lookup(key string) (string, bool) {
+	for _, kv := range ev {
+		if k, v, _ := strings.Cut(kv, "="); k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Merge gets the given environment, such as os.Environ(), with the variables added, replacing any of the same name.
+func (ev EnvVars) Merge(environ []string) []string {
+	merged := append(EnvVars{}, environ...)
+	for _, kv := range ev {
+		merged.set(kv)
+	}
+	return merged
+}
+
+func (ev EnvVars) String() string {
+	return strings.Join(ev, " ")
+}
+
+func (ev *EnvVars) UnmarshalText(text []byte) error {
+	*ev = EnvVars{}
+	return ev.AddText(text)
+}
+
+// AddText adds the given 'KEY=VALUE', or inherited 'KEY', to the existing variables.
+func (ev *EnvVars) AddText(text []byte) error {
+	s := string(text)
+	key, value, hasValue := strings.Cut(s, "=")
+	if key == "" || strings.ContainsAny(key, " \t\r\n\x00") {
+		return fmt.Errorf("invalid environment variable %q, expected 'KEY=VALUE' or 'KEY'", s)
+	}
+	if !hasValue {
+		v, ok := os.LookupEnv(key)
+		if !ok {
+			return nil
+		}
+		value = v
+	}
+	ev.set(strings.Join([]string{key, value}, "="))
+	return nil
+}
+
+// set sets the given 'KEY=VALUE', replacing any existing variable with the same key.
+func (ev *EnvVars) set(kv string) {
+	key, _, _ := strings.Cut(kv, "=")
+	for i, existing := range *ev {
+		if k, _, _ := strings.Cut(existing, "="); k == key {
+			(*ev)[i] = kv
+			return
+		}
+	}
+	*ev = append(*ev, kv)
+}
@@ -0,0 +1,37 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvVars(t *testing.T) {
+	t.Setenv("ARGFLAGS_TEST_HOME", "/home/a")
+	var flags struct {
+		Env EnvVars `flag:"e"`
+	}
+	args := []string{"-e", "DEBUG=1", "-e", "ARGFLAGS_TEST_HOME", "-e", "ARGFLAGS_TEST_UNSET", "-e", "GREETING=hello, world", "-e", "DEBUG=2"}
+	if _, err := ArgFlags(args).ApplyTo(&flags); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := EnvVars{"DEBUG=2", "ARGFLAGS_TEST_HOME=/home/a", "GREETING=hello, world"}
+	if !reflect.DeepEqual(flags.Env, expect) {
+		t.Errorf("expected %q, got %q", expect, flags.Env)
+	}
+	if v, ok := flags.Env.Lookup("GREETING"); !ok || v != "hello, world" {
+		t.Errorf("expected the whole value, got %q", v)
+	}
+	if _, ok := flags.Env.Lookup("ARGFLAGS_TEST_UNSET"); ok {
+		t.Errorf("expected an unset inherited variable to be left out")
+	}
+	merged := flags.Env.Merge([]string{"PATH=/bin", "DEBUG=0"})
+	if !reflect.DeepEqual(merged, []string{"PATH=/bin", "DEBUG=2", "ARGFLAGS_TEST_HOME=/home/a", "GREETING=hello, world"}) {
+		t.Errorf("expected the variables to replace those of the environment, got %q", merged)
+	}
+	for _, s := range []string{"", "=x", "A B=1"} {
+		var ev EnvVars
+		if err := ev.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
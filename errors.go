@@ -0,0 +1,176 @@
+package argflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrUnknownFlag is returned, by a strict Parser, for a flag which matches no field.
+type ErrUnknownFlag struct {
+	// Name is the flag as given, including its dashes.
+	Name string
+	// Suggestion is the closest known flag name, without dashes, or empty if none are similar.
+	Suggestion string
+}
+
+func (e ErrUnknownFlag) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("unknown flag '%s', did you mean '-%s'?", e.Name, e.Suggestion)
+	}
+	return fmt.Sprintf("unknown flag '%s'", e.Name)
+}
+
+// ErrMissingValue is returned for a flag, which is not a bool or count, given without a value.
+type ErrMissingValue struct {
+	Flag string
+	// Reason, when set, explains why the flag has no value.
+	Reason string
+}
+
+func (e ErrMissingValue) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("'%s'  %s", e.Flag, e.Reason)
+	}
+	return fmt.Sprintf("'%s'  no value found", e.Flag)
+}
+
+// ErrConversion is returned for a flag whose value could not be set into its field.
+// It wraps the error of the conversion, so the cause may be tested with errors.Is and errors.As
+type ErrConversion struct {
+	Flag  string
+	Value string
+	// Type is the type of the field the value was set into.
+	Type reflect.Type
+	Err  error
+}
+
+func (e ErrConversion) Error() string {
+	if ee, ok := e.Err.(ErrElement); ok {
+		return fmt.Sprintf("'%s%s'  %v", e.Flag, ee.position(), ee.Err)
+	}
+	return fmt.Sprintf("'%s'  %v", e.Flag, e.Err)
+}
+
+func (e ErrConversion) Unwrap() error {
+	return e.Err
+}
+
+// ErrElement is returned, wrapped in the error of its flag, for an element of a slice or map flag which could not be set.
+// It identifies the element by its index in the slice, or its key in the map, e.g. '-hosts[2]'  invalid port
+type ErrElement struct {
+	// Index is the index of the element in the slice, including the elements of any earlier flags, when Key is empty.
+	Index int
+	// Key is the key of the map entry, or empty for slice elements.
+	Key string
+	Err error
+}
+
+func (e ErrElement) Error() string {
+	return fmt.Sprintf("%s  %v", e.position(), e.Err)
+}
+
+func (e ErrElement) Unwrap() error {
+	return e.Err
+}
+
+// position gets the index or key of the element, in brackets, e.g. '[2]' or '[app]'
+func (e ErrElement) position() string {
+	if e.Key != "" {
+		return fmt.Sprintf("[%s]", e.Key)
+	}
+	return fmt.Sprintf("[%d]", e.Index)
+}
+
+// ErrPartial is returned, by a Parser with partial slices, for a slice which was set without the elements which failed.
+// The applier reports each of its errors as a warning, rather than failing the flag, see WithPartialSlices.
+type ErrPartial struct {
+	// Errs are the ErrElement of each element which failed.
+	Errs []error
+}
+
+func (e ErrPartial) Error() string {
+	return errors.Join(e.Errs...).Error()
+}
+
+func (e ErrPartial) Unwrap() []error {
+	return e.Errs
+}
+
+// ErrDeprecated is the warning, in the Result, for a deprecated flag name which was given.
+type ErrDeprecated struct {
+	// Flag is the deprecated flag, as given.
+	Flag string
+	// Message is the deprecated tag of the flag, or names the flag to use instead, or is empty.
+	Message string
+}
+
+func (e ErrDeprecated) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("'%s' is deprecated, %s", e.Flag, e.Message)
+	}
+	return fmt.Sprintf("'%s' is deprecated", e.Flag)
+}
+
+// ErrAmbiguousFlag is returned, by a Parser with prefix matching, for a flag which is the prefix of more than one flag.
+type ErrAmbiguousFlag struct {
+	// Name is the flag as given, including its dashes.
+	Name string
+	// Matches are the flags it is a prefix of, with a leading dash.
+	Matches []string
+}
+
+func (e ErrAmbiguousFlag) Error() string {
+	return fmt.Sprintf("ambiguous flag '%s', could be %s", e.Name, strings.Join(e.Matches, ", "))
+}
+
+// ErrMissingRequired is returned when flags tagged as required are not given.
+type ErrMissingRequired struct {
+	// Flags are the names of every required flag missing, with a leading dash.
+	Flags []string
+}
+
+func (e ErrMissingRequired) Error() string {
+	return fmt.Sprintf("missing required flags: %s", strings.Join(e.Flags, ", "))
+}
+
+// ErrNotActivated is returned for a flag in a sub arg which is given without the flag activating the sub arg.
+type ErrNotActivated struct {
+	Flag string
+	// Activator is the activating flag, as given in the activatedby tag, e.g. 'store=redis'
+	Activator string
+}
+
+func (e ErrNotActivated) Error() string {
+	return fmt.Sprintf("'%s'  can only be used when -%s is set", e.Flag, e.Activator)
+}
+
+// ErrUnknownCommand is returned for a command name which matches no command or plugin.
+type ErrUnknownCommand struct {
+	Name string
+	// Suggestion is the closest known command name, or empty if none are similar.
+	Suggestion string
+}
+
+func (e ErrUnknownCommand) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("unknown command '%s', did you mean '%s'?", e.Name, e.Suggestion)
+	}
+	return fmt.Sprintf("unknown command '%s'", e.Name)
+}
+
+// ErrValidation is returned for a flag whose field failed its Validator, once set.
+// It wraps the error of the Validator.
+type ErrValidation struct {
+	Flag string
+	Err  error
+}
+
+func (e ErrValidation) Error() string {
+	return fmt.Sprintf("'%s'  %v", e.Flag, e.Err)
+}
+
+func (e ErrValidation) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,69 @@
+package argflags
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type errorFlags struct {
+	Total int    `flag:"total"`
+	Ports []int  `flag:"port"`
+	Name  string `flag:"name"`
+}
+
+func TestAllErrorsReturned(t *testing.T) {
+	var ef errorFlags
+	_, err := NewParser().Apply([]string{"-total", "x", "-name", "n", "-port", "1,y"}, &ef)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	var ce ErrConversion
+	if !errors.As(err, &ce) || ce.Flag != "-total" {
+		t.Errorf("expected the -total conversion error first, got %v", err)
+	}
+	if ef.Name != "n" {
+		t.Errorf("expected a failed flag not to stop the others, got %+v", ef)
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Errorf("expected both errors, got %v", err)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	var ef errorFlags
+	_, err := NewParser(WithStrict(true)).Apply([]string{"-nme", "n", "-total"}, &ef)
+	var uf ErrUnknownFlag
+	if !errors.As(err, &uf) || uf.Name != "-nme" || uf.Suggestion != "name" {
+		t.Errorf("expected an unknown flag suggesting name, got %v", err)
+	}
+	var mv ErrMissingValue
+	if !errors.As(err, &mv) || mv.Flag != "-total" {
+		t.Errorf("expected -total to be missing its value, got %v", err)
+	}
+	var ne *strconv.NumError
+	if _, err := NewParser().Apply([]string{"-total", "x"}, &ef); !errors.As(err, &ne) {
+		t.Errorf("expected the cause of the conversion error to be wrapped, got %v", err)
+	}
+}
+
+func TestElementErrors(t *testing.T) {
+	var ef errorFlags
+	_, err := NewParser().Apply([]string{"-port", "1", "-port", "2,y"}, &ef)
+	var ee ErrElement
+	if !errors.As(err, &ee) || ee.Index != 2 {
+		t.Errorf("expected the third element to fail, got %v", err)
+	}
+}
+
+func TestPartialSlices(t *testing.T) {
+	var ef errorFlags
+	res, err := NewParser(WithPartialSlices(true)).Apply([]string{"-port", "1,y,3"}, &ef)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if len(ef.Ports) != 2 || ef.Ports[1] != 3 || len(res.Warnings) != 1 {
+		t.Errorf("expected the valid ports and a warning, got %v, %v", ef.Ports, res.Warnings)
+	}
+}
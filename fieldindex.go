@@ -0,0 +1,200 @@
+package argflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// fieldIndex maps the case folded flag names of a struct type to the index of the field they match.
+// Each index is the path of field indexes from the struct down to the field, as used by reflect.Value.FieldByIndex.
+type fieldIndex map[string][]int
+
+// typeIndexOf gets the fieldIndex for the given struct type, from its cached Schema.
+func (p *Parser) typeIndexOf(t reflect.Type) fieldIndex {
+	return p.schemaOf(t).index
+}
+
+// buildFieldIndex walks the given struct type, mapping every field name and tag name to its field index.
+// Names of fields directly in the given type take precedence over those found in its subargs,
+// and earlier subargs take precedence over later ones, so the first match, in field order, wins.
+// visiting holds the types currently being walked to prevent recursive subarg types looping forever.
+// Subarg fields are not flags, so are not indexed by their own name, only by the flags within them.
+// Fields tagged as subargs, which are not structs, are left out of the index and returned as errors.
+// The flags of a prefixed subarg, e.g. `flag:"+db"`, are only indexed with the prefix, joined with either a '.' or '-',
+// so its 'host' field is matched by 'db.host' or 'db-host', and not by 'host'.
+func (p *Parser) buildFieldIndex(t reflect.Type, visiting map[reflect.Type]bool) (fieldIndex, error) {
+	fi := fieldIndex{}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var errs []error
+	var subArgIndexes []int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || isCommandField(f) || p.isPositionalOnly(f) {
+			continue
+		}
+		tags := strings.Split(f.Tag.Get(p.tagName), ",")
+		if p.isSubArg(f) && !p.isGroup(f) {
+			// a sub arg is not a flag itself, so its name does not hide a flag within it, e.g. the -proxy of Proxy ProxyOpts `flag:"+"`
+			if !isSubArgType(f.Type) {
+				errs = append(errs, fmt.Errorf("field %s in %s is tagged as a sub argument field '+', but is not a struct or pointer to a struct", f.Name, t.String()))
+				continue
+			}
+			subArgIndexes = append(subArgIndexes, i)
+			continue
+		}
+		if p.match != MatchExactTag || !hasFlagNames(tags) {
+			fi.add(p.nameKey(f.Name), []int{i})
+		}
+		for _, tag := range tags {
+			if isTagOption(tag) {
+				continue
+			}
+			fi.add(p.nameKey(strings.TrimSuffix(tag, deprecatedSuffix)), []int{i})
+		}
+		if p.isGroup(f) {
+			prefix := subArgPrefix(tags)
+			if prefix == "" {
+				errs = append(errs, fmt.Errorf("field %s in %s is a grouped sub argument, tagged '+', without a prefix naming its flag", f.Name, t.String()))
+				continue
+			}
+			fi.add(p.nameKey(prefix), []int{i})
+		}
+	}
+	// Add the subarg fields (tag:+) not already named in given type
+	for _, i := range subArgIndexes {
+		st := t.Field(i).Type
+		if st.Kind() == reflect.Ptr {
+			st = st.Elem()
+		}
+		if visiting[st] {
+			continue
+		}
+		sfi, err := p.buildFieldIndex(st, visiting)
+		errs = append(errs, err)
+		prefix := subArgPrefix(strings.Split(t.Field(i).Tag.Get(p.tagName), ","))
+		for key, index := range sfi {
+			index = append([]int{i}, index...)
+			if prefix == "" {
+				fi.add(key, index)
+				continue
+			}
+			for _, sep := range subArgSeparators {
+				fi.add(p.nameKey(prefix+sep)+key, index)
+			}
+		}
+	}
+	return fi, errors.Join(errs...)
+}
+
+// hasFlagNames checks if the given flag tag values include any flag names, rather than only options.
+func hasFlagNames(tags []string) bool {
+	for _, tag := range tags {
+		if !isTagOption(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// subArgSeparators join the prefix of a prefixed subarg to the names of its flags.
+// The first is used when describing the flags.
+var subArgSeparators = []string{".", "-"}
+
+// isSubArgType checks if the given type may be a sub arg, a struct or pointer to a struct.
+func isSubArgType(t reflect.Type) bool {
+	return isStructPointer(t) || t.Kind() == reflect.Struct
+}
+
+// add maps the given name key to the given index, unless the key has already been mapped.
+func (fi fieldIndex) add(key string, index []int) {
+	if _, ok := fi[key]; ok {
+		return
+	}
+	fi[key] = index
+}
+
+// flagDescription describes a flag field and the flag names which match to it.
+type flagDescription struct {
+	names []string
+	index []int
+	field reflect.StructField
+	// group is the prefix of the prefixed subarg the field is within, without the trailing separator, or empty if not prefixed.
+	group string
+}
+
+// describeFlags describes each flag field in the given struct type, including those in its sub args, in field order.
+// Only the names which match to each field are given, names shadowed by another field are omitted.
+func (p *Parser) describeFlags(t reflect.Type) []flagDescription {
+	return p.schemaOf(t).flags
+}
+
+// prefix is the prefix, with its separator, of the prefixed subargs the struct is within.
+func (p *Parser) describeFields(t reflect.Type, parents []int, prefix string, fi fieldIndex, visiting map[reflect.Type]bool) []flagDescription {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var fds []flagDescription
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || isCommandField(f) || p.isPositionalOnly(f) {
+			continue
+		}
+		index := append(append([]int{}, parents...), i)
+		tags := strings.Split(f.Tag.Get(p.tagName), ",")
+		if p.isGroup(f) {
+			// the flags of a grouped sub arg are described by its element type, only its sentinel flag is described here
+			name := subArgPrefix(tags)
+			if name != "" && indexKey(fi[p.nameKey(prefix+name)]) == indexKey(index) {
+				fds = append(fds, flagDescription{names: []string{prefix + name}, index: index, field: f, group: strings.TrimSuffix(prefix, subArgSeparators[0])})
+			}
+			continue
+		}
+		if p.isSubArg(f) {
+			if !isSubArgType(f.Type) {
+				continue
+			}
+			st := f.Type
+			if st.Kind() == reflect.Ptr {
+				st = st.Elem()
+			}
+			subPrefix := prefix
+			if sp := subArgPrefix(tags); sp != "" {
+				subPrefix = prefix + sp + subArgSeparators[0]
+			}
+			if !visiting[st] {
+				fds = append(fds, p.describeFields(st, index, subPrefix, fi, visiting)...)
+			}
+			continue
+		}
+		if tags[0] == "-" {
+			continue
+		}
+		var names []string
+		for _, tag := range tags {
+			// deprecated names are matched, but not described
+			if !isTagOption(tag) && !isDeprecatedName(tag) {
+				names = append(names, tag)
+			}
+		}
+		if len(names) == 0 {
+			names = []string{strings.ToLower(f.Name)}
+			if p.caseSensitive() {
+				names = []string{f.Name}
+			}
+		}
+		fd := flagDescription{index: index, field: f, group: strings.TrimSuffix(prefix, subArgSeparators[0])}
+		for _, name := range names {
+			if indexKey(fi[p.nameKey(prefix+name)]) == indexKey(index) {
+				fd.names = append(fd.names, prefix+name)
+			}
+		}
+		if len(fd.names) > 0 {
+			fds = append(fds, fd)
+		}
+	}
+	return fds
+}
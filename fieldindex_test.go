@@ -0,0 +1,82 @@
+package argflags
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type indexDBOpts struct {
+	Host string `flag:"host"`
+	Name string `flag:"name"`
+}
+
+type indexCacheOpts struct {
+	Host string `flag:"host"`
+	Size int    `flag:"size"`
+}
+
+type indexFlags struct {
+	Name  string         `flag:"name,n"`
+	DB    indexDBOpts    `flag:"+"`
+	Cache indexCacheOpts `flag:"+"`
+	Store indexDBOpts    `flag:"+store"`
+}
+
+func TestFieldIndexPrecedence(t *testing.T) {
+	p := NewParser()
+	typ := reflect.TypeOf(indexFlags{})
+	tests := map[string][]int{
+		"name":       {0},
+		"N":          {0},
+		"HOST":       {1, 0},
+		"size":       {2, 1},
+		"store.host": {3, 0},
+		"Store-Name": {3, 1},
+		"db":         nil,
+	}
+	for name, expect := range tests {
+		if index := p.findFieldIndex(name, typ, nil); !reflect.DeepEqual(index, expect) {
+			t.Errorf("%s  expected index %v, got %v", name, expect, index)
+		}
+	}
+	if index := NewParser(WithMatchPolicy(MatchCase)).findFieldIndex("HOST", typ, nil); index != nil {
+		t.Errorf("expected no match in another case, got %v", index)
+	}
+}
+
+// largeStructType gets a struct type with n int fields, F0 to Fn-1, each tagged with the flag name 'fN'.
+func largeStructType(n int) reflect.Type {
+	fields := make([]reflect.StructField, n)
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: "F" + strconv.Itoa(i),
+			Type: reflect.TypeOf(0),
+			Tag:  reflect.StructTag(`flag:"f` + strconv.Itoa(i) + `"`),
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+func TestLargeStructFlags(t *testing.T) {
+	str := reflect.New(largeStructType(1000))
+	if _, err := NewParser().Apply([]string{"-f999", "9", "-F500", "5"}, str.Interface()); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if str.Elem().Field(999).Int() != 9 || str.Elem().Field(500).Int() != 5 {
+		t.Errorf("expected the last and middle fields set, got %d, %d", str.Elem().Field(999).Int(), str.Elem().Field(500).Int())
+	}
+}
+
+func BenchmarkFieldLookup1k(b *testing.B) {
+	typ := largeStructType(1000)
+	p := NewParser()
+	p.findFieldIndex("f0", typ, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if p.findFieldIndex("F999", typ, nil) == nil {
+			b.Fatal("expected F999 to be found")
+		}
+	}
+}
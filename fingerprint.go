@@ -0,0 +1,56 @@
+package argflags
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Fingerprint gets an anonymous fingerprint of which flags were applied, for opt-in usage analytics.
+// The fingerprint is a hash of the names of the fields which were set, never their values,
+// so the same combination of flags always gives the same fingerprint, whatever their values, order, or alias used.
+func (r *Result) Fingerprint() string {
+	return fingerprint("", r.applied)
+}
+
+// Fingerprint gets an anonymous fingerprint of the command invoked and which flags were applied, including inherited flags.
+// As with Result.Fingerprint, only names are used, never the flag values.
+func (inv *Invocation) Fingerprint() string {
+	return fingerprint(inv.Command.Path(), inv.applied)
+}
+
+func fingerprint(command string, applied []appliedField) string {
+	names := make([]string, len(applied))
+	for i, af := range applied {
+		names[i] = fieldPath(af.root.Type(), af.index)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	h.Write([]byte(command))
+	for _, name := range names {
+		h.Write([]byte{0})
+		h.Write([]byte(name))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// fieldPath gets the dot delimited names of the fields in the given index, prefixed with the name of the given struct type.
+func fieldPath(t reflect.Type, index []int) string {
+	return strings.Join([]string{t.String(), fieldNamePath(t, index)}, ".")
+}
+
+// fieldNamePath gets the dot delimited path of field names, from the given struct type, to the field at the given index.
+func fieldNamePath(t reflect.Type, index []int) string {
+	var names []string
+	for _, i := range index {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		f := t.Field(i)
+		names = append(names, f.Name)
+		t = f.Type
+	}
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,42 @@
+package argflags
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := func(args ...string) string {
+		var rf resultFlags
+		res, err := NewParser().Apply(args, &rf)
+		if err != nil {
+			t.Fatalf("unexpected error  %v", err)
+		}
+		return res.Fingerprint()
+	}
+	fp := fingerprint("-host", "a", "-tag", "x")
+	if fp != fingerprint("-tag", "y,z", "-host", "b") {
+		t.Errorf("expected the same fingerprint for the same flags, whatever their values and order")
+	}
+	if fp == fingerprint("-host", "a") || fp == fingerprint("-host", "a", "-tag", "x", "-v") {
+		t.Errorf("expected a different fingerprint for different flags")
+	}
+}
+
+func TestInvocationFingerprint(t *testing.T) {
+	var invs []*Invocation
+	root, _, _ := newServeCommand(&invs)
+	for _, args := range [][]string{{"-verbose", "serve", "-port", "1"}, {"serve", "-port", "2", "-verbose"}} {
+		if err := root.Execute(context.Background(), args); err != nil {
+			t.Fatalf("unexpected error  %v", err)
+		}
+	}
+	if invs[0].Fingerprint() != invs[1].Fingerprint() {
+		t.Errorf("expected the same fingerprint, with inherited flags given before or after the command")
+	}
+	var rf resultFlags
+	res, _ := NewParser().Apply(nil, &rf)
+	if invs[0].Fingerprint() == res.Fingerprint() {
+		t.Errorf("expected the command to be part of the fingerprint")
+	}
+}
@@ -2,17 +2,59 @@ package argflags
 
 import (
 	"encoding"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const FlagTagName = "flag"
 const sliceDelimiter = ","
 
+// optReplace marks a slice or map field as replacing its value each time its flag is given,
+// rather than accumulating the values of every occurrence of the flag.
+const optReplace = "replace"
+
+// optSecret marks a field as holding a secret value, which is masked wherever flag values are recorded or displayed.
+const optSecret = "secret"
+
+// secretMask replaces secret values wherever flag values are recorded or displayed.
+const secretMask = "*****"
+
+// Sub arg tag options, controlling if a nil sub arg is instantiated when one of its flags is found.
+// optNewOnSet is the default, creating a new instance of the sub arg to set the flag value on.
+// optPreserveNil leaves nil sub args as nil, ignoring their flags, for when nil means the sub arg is disabled.
+const (
+	optNewOnSet    = "newonset"
+	optPreserveNil = "preserve-nil"
+)
+
+// tagOptions are the values in a flag tag which are options, rather than flag names.
+var tagOptions = map[string]bool{
+	"":             true,
+	"omitempty":    true,
+	"-":            true,
+	"+":            true,
+	optNewOnSet:    true,
+	optPreserveNil: true,
+	optSecret:      true,
+	optReplace:     true,
+	optCount:       true,
+	optRequired:    true,
+	optOctal:       true,
+	optUmask:       true,
+	optMode:        true,
+	optUlimit:      true,
+	optConfig:      true,
+	optNoHash:      true,
+}
+
 var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var flagValueType = reflect.TypeOf((*flag.Value)(nil)).Elem()
+var durationType = reflect.TypeOf(time.Duration(0))
 
 // FlagField represents a Field in a struct which has been matched to a flag
 type FlagField interface {
@@ -21,7 +63,35 @@ type FlagField interface {
 }
 
 type flagField struct {
+	// p is the parser the field was found by.
+	p        *Parser
 	fldValue reflect.Value
+	// root is the struct the field was found in.
+	root reflect.Value
+	// index is the field index path, from the root struct to the field.
+	index []int
+	// instantiated lists the paths of any nil sub arg fields created to reach this field.
+	instantiated []string
+	// parent is the path to the root struct, when it is an element of a grouped sub arg, e.g. "Targets.1"
+	parent string
+}
+
+// appliedField is a field which has been set from a flag, and the flag it was set with.
+type appliedField struct {
+	flag  string
+	root  reflect.Value
+	index []int
+	field reflect.Value
+}
+
+// fieldKey identifies a single field within a struct by its address and type.
+type fieldKey struct {
+	addr uintptr
+	t    reflect.Type
+}
+
+func keyOfField(fld reflect.Value) fieldKey {
+	return fieldKey{addr: fld.Addr().Pointer(), t: fld.Type()}
 }
 
 func (ff flagField) Type() reflect.Type {
@@ -29,49 +99,221 @@ func (ff flagField) Type() reflect.Type {
 }
 
 func (ff flagField) SetValue(value string) error {
-	return setValue(value, ff.fldValue)
+	return ff.p.setValue(value, ff.fldValue)
+}
+
+// path gets the dot delimited path of field names to the field, including the path to its root struct, if any.
+func (ff flagField) path() string {
+	if ff.parent == "" {
+		return fieldNamePath(ff.root.Type(), ff.index)
+	}
+	return strings.Join([]string{ff.parent, fieldNamePath(ff.root.Type(), ff.index)}, ".")
+}
+
+// isReplaced checks if the field is tagged with the replace option.
+func (ff flagField) isReplaced() bool {
+	return ff.p.hasTagOption(ff.root.Type().FieldByIndex(ff.index), optReplace)
 }
 
-func setValue(value string, fld reflect.Value) error {
+// isSecret checks if the field is tagged with the secret option, or is of a type which is always secret.
+func (ff flagField) isSecret() bool {
+	if _, ok := ff.fldValue.Interface().(secretValue); ok {
+		return true
+	}
+	return ff.p.hasTagOption(ff.root.Type().FieldByIndex(ff.index), optSecret)
+}
+
+func (p *Parser) setValue(value string, fld reflect.Value) error {
+	if parse := parserOf(fld.Type()); parse != nil {
+		v, err := parse(value)
+		if err != nil {
+			return err
+		}
+		fld.Set(v)
+		return nil
+	}
 	if tm := asTextUnmarshaler(fld); tm != nil {
 		return tm.UnmarshalText([]byte(value))
 	}
+	if fv := asFlagValue(fld); fv != nil {
+		return fv.Set(value)
+	}
 	t := fld.Type()
 	switch fld.Type().Kind() {
 	case reflect.Ptr:
 		if fld.IsZero() || fld.IsNil() {
 			fld.Set(reflect.New(t.Elem()))
 		}
-		return setValue(value, fld.Elem())
+		return p.setValue(value, fld.Elem())
 	case reflect.Slice:
-		return setFieldSlice(strings.Split(value, sliceDelimiter), fld)
+		return p.setFieldSlice(value, fld, 0)
+	case reflect.Map:
+		inst := reflect.MakeMap(t)
+		if err := p.addMapEntries(value, inst); err != nil {
+			return err
+		}
+		fld.Set(inst)
+		return nil
 	}
+	return setBasicValue(value, fld)
+}
 
-	sv, err := stringToType(value, t)
-	if err != nil {
+// appendValue adds the given value to a slice or map field, rather than replacing it, for flags given more than once.
+// Fields which do not accumulate values have their value replaced, as with setValue.
+func (p *Parser) appendValue(value string, fld reflect.Value) error {
+	if acc := asAccumulator(fld); acc != nil {
+		return acc.AddText([]byte(value))
+	}
+	if isWholeValue(fld) {
+		return p.setValue(value, fld)
+	}
+	switch fld.Kind() {
+	case reflect.Ptr:
+		if !fld.IsNil() {
+			return p.appendValue(value, fld.Elem())
+		}
+	case reflect.Slice:
+		values := reflect.New(fld.Type()).Elem()
+		err := p.setFieldSlice(value, values, fld.Len())
+		if err != nil && !errors.As(err, &ErrPartial{}) {
+			return err
+		}
+		fld.Set(reflect.AppendSlice(fld, values))
 		return err
+	case reflect.Map:
+		if fld.IsNil() {
+			fld.Set(reflect.MakeMap(fld.Type()))
+		}
+		return p.addMapEntries(value, fld)
 	}
-	fld.Set(reflect.ValueOf(sv))
-	return nil
+	return p.setValue(value, fld)
 }
 
-func stringToType(s string, t reflect.Type) (interface{}, error) {
+// setBasicValue parses the given string into the base type of the given field, setting it directly.
+// Values are set using the typed setters, avoiding boxing each value into an interface.
+// time.Duration is checked before its int64 kind, so durations are parsed as durations, e.g. '1h30m', rather than integers.
+func setBasicValue(s string, fld reflect.Value) error {
+	t := fld.Type()
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fld.SetInt(int64(d))
+		return nil
+	}
 	switch t.Kind() {
 	case reflect.String:
-		return s, nil
+		fld.SetString(s)
 	case reflect.Bool:
-		return strconv.ParseBool(s)
-	case reflect.Int:
-		return strconv.Atoi(s)
-	case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
-		return strconv.ParseInt(s, 64, 64)
-	case reflect.Float64:
-		return strconv.ParseFloat(s, 64)
-	case reflect.Float32:
-		return strconv.ParseFloat(s, 32)
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fld.SetBool(b)
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		fld.SetInt(i)
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		fld.SetUint(u)
+	case reflect.Float64, reflect.Float32:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return err
+		}
+		fld.SetFloat(f)
 	default:
-		return nil, fmt.Errorf("%s is an unsupported field type", t.Name())
+		return fmt.Errorf("%s is an unsupported field type", t.Name())
+	}
+	return nil
+}
+
+// Accumulator is implemented by flag values which accumulate the values of a flag given more than once.
+// The first time the flag is given, its value is set as usual, with UnmarshalText or a registered parser.
+// Each following occurrence of the flag is then passed to AddText, to add to the value.
+type Accumulator interface {
+	AddText(text []byte) error
+}
+
+var accumulatorType = reflect.TypeOf((*Accumulator)(nil)).Elem()
+
+// asAccumulator gets the given value as an Accumulator, or nil if it does not support that interface.
+// As with asTextUnmarshaler, non pointer values are checked using their address.
+func asAccumulator(fld reflect.Value) Accumulator {
+	fldPtr := fld
+	if fld.Kind() != reflect.Ptr {
+		fldPtr = fld.Addr()
+	} else if fld.IsNil() {
+		return nil
 	}
+	if !fldPtr.Type().Implements(accumulatorType) {
+		return nil
+	}
+	return fldPtr.Interface().(Accumulator)
+}
+
+// FlagSetter is implemented by flag values set by more than one flag name, which need to know the name each value was given with.
+// Every occurrence of its flags is passed to SetFlag, in the order given, with the flag name as given, without its dashes.
+// e.g. a field tagged `flag:"include,exclude"` is given each '-include' and '-exclude', interleaved as they were given.
+// Values from the environment or a default tag are set as usual, with UnmarshalText or by their kind.
+type FlagSetter interface {
+	SetFlag(name, value string) error
+}
+
+var flagSetterType = reflect.TypeOf((*FlagSetter)(nil)).Elem()
+
+// asFlagSetter gets the given value as a FlagSetter, or nil if it does not support that interface.
+// As with asTextUnmarshaler, non pointer values are checked using their address.
+func asFlagSetter(fld reflect.Value) FlagSetter {
+	fldPtr := fld
+	if fld.Kind() != reflect.Ptr {
+		fldPtr = fld.Addr()
+	} else if fld.IsNil() {
+		return nil
+	}
+	if !fldPtr.Type().Implements(flagSetterType) {
+		return nil
+	}
+	return fldPtr.Interface().(FlagSetter)
+}
+
+// isWholeValue checks if the given field is set from a value as a whole, by a registered parser or its TextUnmarshaler,
+// rather than by its kind, such as the elements of a slice.
+func isWholeValue(fld reflect.Value) bool {
+	return parserOf(fld.Type()) != nil || asTextUnmarshaler(fld) != nil || asFlagValue(fld) != nil
+}
+
+// asFlagValue gets the given value as a standard library flag.Value, or nil if it does not support that interface.
+// Nil pointers are not returned, so they are instantiated before their Set method is called.
+// As with asTextUnmarshaler, non pointer values are checked using their address.
+func asFlagValue(fld reflect.Value) flag.Value {
+	fldPtr := fld
+	if fld.Kind() != reflect.Ptr {
+		fldPtr = fld.Addr()
+	} else if fld.IsNil() {
+		return nil
+	}
+	if !fldPtr.Type().Implements(flagValueType) {
+		return nil
+	}
+	return fldPtr.Interface().(flag.Value)
+}
+
+// isBoolFlag checks if the given type is a flag.Value with an IsBoolFlag method returning true,
+// which, as with the flag package, need not be given a value.
+func isBoolFlag(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	bf, ok := reflect.New(t).Interface().(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
 }
 
 // asTextUnmarshaler will return an instance of a textUnmarshaler if the given value supports that interface.
@@ -87,16 +329,59 @@ func asTextUnmarshaler(fld reflect.Value) encoding.TextUnmarshaler {
 	return fldPtr.Interface().(encoding.TextUnmarshaler)
 }
 
-func setFieldSlice(ss []string, fld reflect.Value) error {
+// setFieldSlice sets the given slice field to the delimited values in the given string.
+// The slice is sized once from the delimiter count and each element is set in place,
+// without first splitting the string into an intermediate slice of strings.
+// Delimiters escaped with a backslash are part of the element, see DelimTagName.
+// offset is the index, in the whole slice, of the first value, reported with the index of any element which fails.
+func (p *Parser) setFieldSlice(value string, fld reflect.Value, offset int) error {
 	t := fld.Type()
-	// TODO Check if value exist and append values
-	inst := reflect.MakeSlice(t, len(ss), len(ss))
-	for i, s := range ss {
-		if err := setValue(s, inst.Index(i)); err != nil {
-			return err
+	size := countDelimited(value, p.delimiter) + 1
+	inst := reflect.MakeSlice(t, size, size)
+	var partial ErrPartial
+	set := 0
+	for i := 0; i < size; i++ {
+		s, rest, _ := cutDelimited(value, p.delimiter)
+		value = rest
+		if err := p.setValue(s, inst.Index(set)); err != nil {
+			if !p.partialSlices {
+				return ErrElement{Index: offset + i, Err: err}
+			}
+			// leave the element out, reusing its place for the next
+			partial.Errs = append(partial.Errs, ErrElement{Index: offset + i, Err: err})
+			inst.Index(set).Set(reflect.Zero(t.Elem()))
+			continue
+		}
+		set++
+	}
+	fld.Set(inst.Slice(0, set))
+	if len(partial.Errs) > 0 {
+		return partial
+	}
+	return nil
+}
+
+// addMapEntries adds the delimited key=value entries in the given string to the given map.
+// Keys and values may be any type supported as a flag value, other than slices.
+// Delimiters escaped with a backslash are part of the entry, see DelimTagName.
+func (p *Parser) addMapEntries(value string, m reflect.Value) error {
+	t := m.Type()
+	for _, entry := range splitDelimited(value, p.delimiter) {
+		k, v, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid map entry %q, expected key=value", entry)
+		}
+		k = strings.TrimSpace(k)
+		key := reflect.New(t.Key()).Elem()
+		if err := p.setValue(k, key); err != nil {
+			return ErrElement{Key: k, Err: fmt.Errorf("invalid key  %w", err)}
 		}
+		elem := reflect.New(t.Elem()).Elem()
+		if err := p.setValue(v, elem); err != nil {
+			return ErrElement{Key: k, Err: err}
+		}
+		m.SetMapIndex(key, elem)
 	}
-	fld.Set(inst)
 	return nil
 }
 
@@ -105,91 +390,111 @@ func setFieldSlice(ss []string, fld reflect.Value) error {
 // If the given type contains a matching field, the index of that field is returned.
 // If the given type has no matching field, but has subargs, these are searched.
 // returns the indexes of each field, with the last index being the actual field.
-func findFieldIndex(name string, t reflect.Type, parents []int) []int {
+func (p *Parser) findFieldIndex(name string, t reflect.Type, parents []int) []int {
 	if t.Kind() == reflect.Ptr {
-		return findFieldIndex(name, t.Elem(), parents)
+		return p.findFieldIndex(name, t.Elem(), parents)
 	}
-	var subArgIndexes []int
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		if !f.IsExported() {
-			continue
-		}
-		if strings.EqualFold(name, f.Name) {
-			return append(parents, i)
-		}
-		tags := strings.Split(f.Tag.Get(FlagTagName), ",")
-		if isNameInTag(name, tags) {
-			return append(parents, i)
-		}
-		if isSubArgTag(tags) {
-			fld := t.Field(i)
-			if !isStructPointer(fld.Type) && fld.Type.Kind() != reflect.Struct {
-				log.Panicf("Field %s in %s is tagged as a sub argument field '+', but is not a struct or pointer to a struct", fld.Name, t.String())
-			}
-			subArgIndexes = append(subArgIndexes, i)
-		}
+	index, ok := p.typeIndexOf(t)[p.nameKey(name)]
+	if !ok {
+		return nil
 	}
-	// Not found in given value, search fields marked as subargs (tag:+)
-	for _, i := range subArgIndexes {
-		p := append(parents, i)
-		if is := findFieldIndex(name, t.Field(i).Type, p); len(is) > 0 {
-			return is
+	return append(parents, index...)
+}
+
+// hasTagOption checks if the flag tag of the given field contains the given option.
+func (p *Parser) hasTagOption(f reflect.StructField, option string) bool {
+	for _, tag := range strings.Split(f.Tag.Get(p.tagName), ",") {
+		if tag == option {
+			return true
 		}
 	}
-	return nil
+	return false
 }
 
 func isSubArgTag(tags []string) bool {
 	for _, tag := range tags {
-		if tag == "+" {
+		if strings.HasPrefix(tag, "+") {
 			return true
 		}
 	}
 	return false
 }
 
-func isNameInTag(name string, tags []string) bool {
-	for _, t := range tags {
-		if t == "omitempty" || t == "-" || t == "+" {
-			continue
+// isSubArg checks if the given field is a sub arg, either tagged as one, or an embedded struct, without a flag tag.
+// Embedded structs, or pointers to them, are implicit sub args, so their promoted fields are matched as flags,
+// unless they are set as a whole value, such as with a TextUnmarshaler.
+func (p *Parser) isSubArg(f reflect.StructField) bool {
+	tags := strings.Split(f.Tag.Get(p.tagName), ",")
+	if isSubArgTag(tags) {
+		return true
+	}
+	if !f.Anonymous || !f.IsExported() || !isSubArgType(f.Type) || tags[0] != "" {
+		return false
+	}
+	return !isWholeValue(reflect.New(f.Type).Elem())
+}
+
+// subArgPrefix gets the prefix of a prefixed sub arg tag, e.g. 'db' from `flag:"+db"`, or empty if the sub arg has no prefix.
+func subArgPrefix(tags []string) string {
+	for _, tag := range tags {
+		if prefix, ok := strings.CutPrefix(tag, "+"); ok {
+			return prefix
 		}
-		if strings.EqualFold(t, name) {
-			return true
+	}
+	return ""
+}
+
+// isTagOption checks if the given value in a flag tag is an option, rather than a flag name.
+func isTagOption(tag string) bool {
+	return tagOptions[tag] || strings.HasPrefix(tag, "+")
+}
+
+// isNilPreserved checks if the path to the given index passes through a nil sub arg tagged as 'preserve-nil'.
+func (p *Parser) isNilPreserved(v reflect.Value, index []int) bool {
+	for _, fi := range index[:len(index)-1] {
+		fld := v.Field(fi)
+		if fld.Kind() == reflect.Ptr {
+			if fld.IsNil() {
+				return p.hasTagOption(v.Type().Field(fi), optPreserveNil)
+			}
+			fld = fld.Elem()
 		}
+		v = fld
 	}
 	return false
 }
 
-func ensureNotNil(v reflect.Value, index []int) {
+// ensureNotNil instantiates any nil pointers along the given index.
+// Returns the dot delimited field paths of the sub arg fields instantiated.
+func ensureNotNil(v reflect.Value, index []int, path string) []string {
 	if len(index) == 0 {
-		return
+		return nil
 	}
+	var created []string
 	fld := v.Field(index[0])
 	t := fld.Type()
+	fldPath := v.Type().Field(index[0]).Name
+	if path != "" {
+		fldPath = strings.Join([]string{path, fldPath}, ".")
+	}
 	if t.Kind() == reflect.Ptr {
 		if fld.IsNil() {
 			fld.Set(reflect.New(t.Elem()))
+			if len(index) > 1 {
+				created = append(created, fldPath)
+			}
 		}
 		fld = fld.Elem()
 	}
-	ensureNotNil(fld, index[1:])
+	return append(created, ensureNotNil(fld, index[1:], fldPath)...)
 }
 
-func findField(name string, v reflect.Value) (reflect.Value, error) {
+func (p *Parser) newFlagField(name string, v reflect.Value) (*flagField, error) {
 	t := v.Type()
-	index := findFieldIndex(name, t, nil)
-	if len(index) == 0 {
-		return reflect.Zero(reflect.TypeOf("")), fmt.Errorf("field %s not found in %s", name, t.String())
-	}
-	ensureNotNil(v, index)
-	return v.FieldByIndex(index), nil
-}
-
-func newFlagField(name string, v reflect.Value) (FlagField, error) {
-	fld, err := findField(name, v)
-	if err != nil {
-		return nil, err
+	index := p.findFieldIndex(name, t, nil)
+	if len(index) == 0 || p.isNilPreserved(v, index) {
+		return nil, fmt.Errorf("field %s not found in %s", name, t.String())
 	}
-	return &flagField{fldValue: fld}, nil
+	created := ensureNotNil(v, index, "")
+	return &flagField{p: p, fldValue: v.FieldByIndex(index), root: v, index: index, instantiated: created}, nil
 }
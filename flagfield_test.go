@@ -0,0 +1,166 @@
+package argflags
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type listFlags struct {
+	Ints    []int     `flag:"ints"`
+	Strings []string  `flag:"strings"`
+	Floats  []float64 `flag:"floats"`
+}
+
+// listValue gets a delimited list of n elements, formatted by the given func.
+func listValue(n int, format func(i int) string) string {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = format(i)
+	}
+	return strings.Join(values, ",")
+}
+
+func TestLargeListFlags(t *testing.T) {
+	var lf listFlags
+	args := []string{"-ints", listValue(10000, strconv.Itoa), "-ints", "1,2"}
+	if _, err := NewParser().Apply(args, &lf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if len(lf.Ints) != 10002 || lf.Ints[9999] != 9999 || lf.Ints[10001] != 2 {
+		t.Errorf("expected 10002 ints ending 9999, 1, 2, got %d", len(lf.Ints))
+	}
+}
+
+func benchmarkListFlag(b *testing.B, name, value string) {
+	args := []string{"-" + name, value}
+	p := NewParser()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var lf listFlags
+		if _, err := p.Apply(args, &lf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIntList10k(b *testing.B) {
+	benchmarkListFlag(b, "ints", listValue(10000, strconv.Itoa))
+}
+
+func BenchmarkStringList10k(b *testing.B) {
+	benchmarkListFlag(b, "strings", listValue(10000, func(i int) string {
+		return "s" + strconv.Itoa(i)
+	}))
+}
+
+func BenchmarkFloatList10k(b *testing.B) {
+	benchmarkListFlag(b, "floats", listValue(10000, func(i int) string {
+		return strconv.Itoa(i) + ".5"
+	}))
+}
+
+func BenchmarkAppendIntList10k(b *testing.B) {
+	value := listValue(1000, strconv.Itoa)
+	args := make([]string, 0, 20)
+	for i := 0; i < 10; i++ {
+		args = append(args, "-ints", value)
+	}
+	p := NewParser()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var lf listFlags
+		if _, err := p.Apply(args, &lf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type mapFlags struct {
+	Labels map[string]string `flag:"label"`
+	Limits map[string]int    `flag:"limit"`
+}
+
+func TestMapFlags(t *testing.T) {
+	var mf mapFlags
+	if _, err := NewParser().Apply([]string{"-label", "app=web,tier=db", "-label", "env=prod", "-limit", "cpu=2"}, &mf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := mapFlags{Labels: map[string]string{"app": "web", "tier": "db", "env": "prod"}, Limits: map[string]int{"cpu": 2}}
+	if !reflect.DeepEqual(mf, expect) {
+		t.Errorf("expected %+v, got %+v", expect, mf)
+	}
+	if _, err := NewParser().Apply([]string{"-limit", "cpu=two"}, &mf); err == nil {
+		t.Errorf("expected an error for a map value which is not a number")
+	}
+}
+
+type repeatedFlags struct {
+	Tags     []string `flag:"tag"`
+	Excludes []string `flag:"exclude,replace"`
+}
+
+func TestRepeatedFlags(t *testing.T) {
+	rf := repeatedFlags{Tags: []string{"old"}}
+	args := []string{"-tag", "a", "-tag", "b,c", "-exclude", "x", "-exclude", "y,z"}
+	if _, err := NewParser().Apply(args, &rf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(rf.Tags, []string{"a", "b", "c"}) {
+		t.Errorf("expected the first -tag to replace the initial value, and later ones to add, got %v", rf.Tags)
+	}
+	if !reflect.DeepEqual(rf.Excludes, []string{"y", "z"}) {
+		t.Errorf("expected the last -exclude to replace the others, got %v", rf.Excludes)
+	}
+}
+
+// levels is a flag.Value, counting the times it is set.
+type levels []string
+
+func (l *levels) String() string {
+	return strings.Join(*l, "|")
+}
+
+func (l *levels) Set(s string) error {
+	if s == "" {
+		return fmt.Errorf("empty level")
+	}
+	*l = append(*l, s)
+	return nil
+}
+
+func TestFlagValue(t *testing.T) {
+	var v struct {
+		Levels levels `flag:"level"`
+	}
+	if _, err := NewParser().Apply([]string{"-level", "a,b", "-level", "c"}, &v); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(v.Levels, levels{"a,b", "c"}) {
+		t.Errorf("expected Set to be called with each value as given, got %q", v.Levels)
+	}
+}
+
+// celsius has a parser registered, in place of any conversion of its kind.
+type celsius float64
+
+func TestRegisterParser(t *testing.T) {
+	RegisterParser(func(s string) (celsius, error) {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(s, "C"), 64)
+		return celsius(f), err
+	})
+	var v struct {
+		Temp  celsius   `flag:"temp"`
+		Temps []celsius `flag:"temps"`
+	}
+	if _, err := NewParser().Apply([]string{"-temp", "21.5C", "-temps", "1C,2C"}, &v); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if v.Temp != 21.5 || !reflect.DeepEqual(v.Temps, []celsius{1, 2}) {
+		t.Errorf("expected the registered parser to be used, got %v and %v", v.Temp, v.Temps)
+	}
+}
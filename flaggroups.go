@@ -0,0 +1,120 @@
+package argflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ExclusiveTagName is the tag naming the groups of mutually exclusive flags a flag belongs to.
+// At most one flag of an exclusive group may be given.
+// e.g. JSON bool `flag:"json" exclusive:"output"` and YAML bool `flag:"yaml" exclusive:"output"` fails when both are given.
+const ExclusiveTagName = "exclusive"
+
+// TogetherTagName is the tag naming the groups of flags, a flag belongs to, which must be given together.
+// When any flag of the group is given, all of them must be.
+// e.g. User string `flag:"user" together:"auth"` and Password string `flag:"password" together:"auth"` fails when only one is given.
+// A flag may belong to more than one group, with comma delimited group names. e.g. `exclusive:"output,format"`
+// Flags count as given when set by a flag, positional argument, the environment or a config file, but not by their default.
+// Groups are formed from the flags of each struct, including its sub args, apart from any grouped sub args.
+const TogetherTagName = "together"
+
+// ErrExclusive is returned when more than one flag of an exclusive group is given.
+type ErrExclusive struct {
+	Group string
+	// Flags are the flags of the group which were given, with a leading dash.
+	Flags []string
+}
+
+func (e ErrExclusive) Error() string {
+	return fmt.Sprintf("flags %s can not be used together", strings.Join(e.Flags, ", "))
+}
+
+// ErrTogether is returned when some, but not all, of the flags of a together group are given.
+type ErrTogether struct {
+	Group string
+	// Flags are the flags of the group which were given, with a leading dash.
+	Flags []string
+	// Missing are the flags of the group which were not given, with a leading dash.
+	Missing []string
+}
+
+func (e ErrTogether) Error() string {
+	return fmt.Sprintf("'%s'  must be used with %s", strings.Join(e.Flags, ", "), strings.Join(e.Missing, ", "))
+}
+
+// flagGroup is the flags of a struct in a group named by an exclusive or together tag.
+type flagGroup struct {
+	name  string
+	flags []flagDescription
+}
+
+// flagGroups gets the groups, named in the given tag, of the flags in the given struct type, in the order of group names.
+func (p *Parser) flagGroups(t reflect.Type, tagName string) []flagGroup {
+	byName := map[string]*flagGroup{}
+	var names []string
+	for _, fd := range p.describeFlags(t) {
+		tag, ok := fd.field.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		for _, name := range strings.Split(tag, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if byName[name] == nil {
+				byName[name] = &flagGroup{name: name}
+				names = append(names, name)
+			}
+			byName[name].flags = append(byName[name].flags, fd)
+		}
+	}
+	sort.Strings(names)
+	groups := make([]flagGroup, len(names))
+	for i, name := range names {
+		groups[i] = *byName[name]
+	}
+	return groups
+}
+
+// checkFlagGroups checks the exclusive and together groups of each target, against the flags which were given.
+func (a *applier) checkFlagGroups() error {
+	var errs []error
+	for _, target := range a.targets {
+		for _, g := range a.p.flagGroups(target.value.Type(), ExclusiveTagName) {
+			if given, _ := a.givenInGroup(target, g); len(given) > 1 {
+				errs = append(errs, ErrExclusive{Group: g.name, Flags: given})
+			}
+		}
+		for _, g := range a.p.flagGroups(target.value.Type(), TogetherTagName) {
+			if given, missing := a.givenInGroup(target, g); len(given) > 0 && len(missing) > 0 {
+				errs = append(errs, ErrTogether{Group: g.name, Flags: given, Missing: missing})
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// givenInGroup gets the flags of the given group which were given, and those which were not, in the given target.
+// Flags within sub args which are not in use, or hidden in the target, are neither.
+func (a *applier) givenInGroup(target applyTarget, g flagGroup) (given, missing []string) {
+	for _, fd := range g.flags {
+		if target.hidden[indexKey(fd.index)] {
+			continue
+		}
+		fld, ok := a.p.fieldInUse(target.value, fd.index)
+		if !ok {
+			continue
+		}
+		key := keyOfField(fld)
+		if a.isApplied[key] || a.preset[key] || a.isFallback[key] || a.isFailed[key] {
+			given = append(given, "-"+fd.names[0])
+		} else {
+			missing = append(missing, "-"+fd.names[0])
+		}
+	}
+	return given, missing
+}
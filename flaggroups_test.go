@@ -0,0 +1,37 @@
+package argflags
+
+import (
+	"errors"
+	"testing"
+)
+
+type outputFlags struct {
+	JSON bool   `flag:"json" exclusive:"output"`
+	YAML bool   `flag:"yaml" exclusive:"output"`
+	User string `flag:"user" together:"auth"`
+	Pass string `flag:"pass" together:"auth"`
+}
+
+func TestExclusiveFlags(t *testing.T) {
+	var of outputFlags
+	_, err := NewParser().Apply([]string{"-json", "-yaml"}, &of)
+	var ee ErrExclusive
+	if !errors.As(err, &ee) || ee.Group != "output" || len(ee.Flags) != 2 {
+		t.Errorf("expected an exclusive error, got %v", err)
+	}
+	if _, err := NewParser().Apply([]string{"-yaml"}, &outputFlags{}); err != nil {
+		t.Errorf("unexpected error  %v", err)
+	}
+}
+
+func TestTogetherFlags(t *testing.T) {
+	var of outputFlags
+	_, err := NewParser().Apply([]string{"-user", "u"}, &of)
+	var te ErrTogether
+	if !errors.As(err, &te) || te.Group != "auth" || len(te.Missing) != 1 || te.Missing[0] != "-pass" {
+		t.Errorf("expected a together error missing -pass, got %v", err)
+	}
+	if _, err := NewParser().Apply([]string{"-user", "u", "-pass", "p"}, &outputFlags{}); err != nil {
+		t.Errorf("unexpected error  %v", err)
+	}
+}
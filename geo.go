@@ -0,0 +1,171 @@
+package argflags
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LatLng is a geographic coordinate of latitude and longitude, in decimal degrees.
+// It accepts decimal degrees, separated by a comma or space, e.g. '52.37,4.90' or '-33.86 151.21',
+// or degrees, minutes and seconds with a hemisphere, e.g. 52°22'12"N 4°54'0"E, '52 22 12 N, 4 54 0 E' or '52d22m12sN 4d54mE'.
+// Latitude must be within -90 to 90, longitude within -180 to 180.
+type LatLng struct {
+	Lat float64
+	Lng float64
+}
+
+func (ll LatLng) String() string {
+	return strings.Join([]string{
+		strconv.FormatFloat(ll.Lat, 'f', -1, 64),
+		strconv.FormatFloat(ll.Lng, 'f', -1, 64),
+	}, ",")
+}
+
+func (ll LatLng) MarshalText() ([]byte, error) {
+	return []byte(ll.String()), nil
+}
+
+func (ll *LatLng) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	lat, lng, err := splitLatLng(s)
+	if err != nil {
+		return fmt.Errorf("invalid coordinate %q  %v", s, err)
+	}
+	v := LatLng{}
+	if v.Lat, err = parseDegrees(lat, "NS"); err != nil {
+		return fmt.Errorf("invalid coordinate %q latitude  %v", s, err)
+	}
+	if v.Lng, err = parseDegrees(lng, "EW"); err != nil {
+		return fmt.Errorf("invalid coordinate %q longitude  %v", s, err)
+	}
+	if err := v.validate(); err != nil {
+		return fmt.Errorf("invalid coordinate %q  %v", s, err)
+	}
+	*ll = v
+	return nil
+}
+
+func (ll LatLng) validate() error {
+	if ll.Lat < -90 || ll.Lat > 90 {
+		return fmt.Errorf("latitude %v out of range -90 to 90", ll.Lat)
+	}
+	if ll.Lng < -180 || ll.Lng > 180 {
+		return fmt.Errorf("longitude %v out of range -180 to 180", ll.Lng)
+	}
+	return nil
+}
+
+// splitLatLng splits a coordinate into its latitude and longitude parts.
+// A latitude hemisphere letter ends the latitude, otherwise they are split by a comma or whitespace.
+func splitLatLng(s string) (string, string, error) {
+	if i := strings.IndexAny(s, "NS"); i >= 0 {
+		return s[:i+1], strings.TrimLeft(s[i+1:], " ,"), nil
+	}
+	if lat, lng, ok := strings.Cut(s, ","); ok {
+		return lat, lng, nil
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return "", "", fmt.Errorf("expected a latitude and longitude")
+	}
+	return fields[0], fields[1], nil
+}
+
+// parseDegrees parses decimal degrees, or degrees minutes and seconds, with an optional, upper case, hemisphere letter.
+// hemispheres are the positive then negative hemisphere letters allowed, e.g. "NS"
+func parseDegrees(s string, hemispheres string) (float64, error) {
+	s = strings.TrimSpace(s)
+	sign := 1.0
+	if s != "" {
+		last := s[len(s)-1:]
+		if strings.Contains(hemispheres, last) {
+			if last == hemispheres[1:] {
+				sign = -1
+			}
+			s = strings.TrimSpace(s[:len(s)-1])
+		} else if strings.ContainsAny(last, "NSEW") {
+			return 0, fmt.Errorf("unexpected hemisphere %s", last)
+		}
+	}
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '°' || r == '\'' || r == '"' || r == '′' || r == '″' || r == 'd' || r == 'm' || r == 's'
+	})
+	if len(parts) == 0 || len(parts) > 3 {
+		return 0, fmt.Errorf("expected degrees, minutes and seconds")
+	}
+	var degrees float64
+	for i, p := range parts {
+		n, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", p)
+		}
+		if i > 0 && (n < 0 || n >= 60) {
+			return 0, fmt.Errorf("minutes and seconds must be within 0 to 60")
+		}
+		if i == 0 && n < 0 {
+			sign, n = -sign, -n
+		}
+		for j := 0; j < i; j++ {
+			n /= 60
+		}
+		degrees += n
+	}
+	return sign * degrees, nil
+}
+
+// BoundingBox is a geographic area, between its south west and north east corners.
+// It accepts two coordinates, in any LatLng form, separated by a semicolon, e.g. '52.3,4.7;52.4,5.0'
+// or four decimal degrees, separated by commas, in the order south, west, north, east. e.g. '52.3,4.7,52.4,5.0'
+// South may not be greater than north.  West may be greater than east, for boxes crossing the antimeridian.
+type BoundingBox struct {
+	SouthWest LatLng
+	NorthEast LatLng
+}
+
+// Contains checks if the given coordinate is within the bounding box.
+func (bb BoundingBox) Contains(ll LatLng) bool {
+	if ll.Lat < bb.SouthWest.Lat || ll.Lat > bb.NorthEast.Lat {
+		return false
+	}
+	if bb.SouthWest.Lng <= bb.NorthEast.Lng {
+		return ll.Lng >= bb.SouthWest.Lng && ll.Lng <= bb.NorthEast.Lng
+	}
+	// crosses the antimeridian
+	return ll.Lng >= bb.SouthWest.Lng || ll.Lng <= bb.NorthEast.Lng
+}
+
+func (bb BoundingBox) String() string {
+	return strings.Join([]string{bb.SouthWest.String(), bb.NorthEast.String()}, ",")
+}
+
+func (bb BoundingBox) MarshalText() ([]byte, error) {
+	return []byte(bb.String()), nil
+}
+
+func (bb *BoundingBox) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	var sw, ne string
+	if a, b, ok := strings.Cut(s, ";"); ok {
+		sw, ne = a, b
+	} else {
+		parts := strings.Split(s, ",")
+		if len(parts) != 4 {
+			return fmt.Errorf("invalid bounding box %q, expected south,west,north,east", s)
+		}
+		sw = strings.Join(parts[:2], ",")
+		ne = strings.Join(parts[2:], ",")
+	}
+	var v BoundingBox
+	if err := v.SouthWest.UnmarshalText([]byte(sw)); err != nil {
+		return fmt.Errorf("invalid bounding box %q  %v", s, err)
+	}
+	if err := v.NorthEast.UnmarshalText([]byte(ne)); err != nil {
+		return fmt.Errorf("invalid bounding box %q  %v", s, err)
+	}
+	if v.SouthWest.Lat > v.NorthEast.Lat {
+		return fmt.Errorf("invalid bounding box %q, south is greater than north", s)
+	}
+	*bb = v
+	return nil
+}
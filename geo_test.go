@@ -0,0 +1,53 @@
+package argflags
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLatLng(t *testing.T) {
+	tests := map[string]LatLng{
+		"52.37,4.90":           {Lat: 52.37, Lng: 4.9},
+		"-33.86 151.21":        {Lat: -33.86, Lng: 151.21},
+		`52°22'12"N 4°54'0"E`:  {Lat: 52.37, Lng: 4.9},
+		"52 22 12 N, 4 54 0 E": {Lat: 52.37, Lng: 4.9},
+		"33d51m36sS 151d12mE":  {Lat: -33.86, Lng: 151.2},
+	}
+	for s, expect := range tests {
+		var ll LatLng
+		if err := ll.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if math.Abs(ll.Lat-expect.Lat) > 1e-9 || math.Abs(ll.Lng-expect.Lng) > 1e-9 {
+			t.Errorf("%s  expected %v, got %v", s, expect, ll)
+		}
+	}
+	for _, s := range []string{"91,0", "0,181", "52.37", "52N 4N", "x,y", "52 61 0 N, 4 E"} {
+		var ll LatLng
+		if err := ll.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	var bb BoundingBox
+	if err := bb.UnmarshalText([]byte("52.3,4.7,52.4,5.0")); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !bb.Contains(LatLng{Lat: 52.35, Lng: 4.9}) || bb.Contains(LatLng{Lat: 52.35, Lng: 5.1}) {
+		t.Errorf("expected the box to contain only the points within it, got %v", bb)
+	}
+	if err := bb.UnmarshalText([]byte("-10,170;10,-170")); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !bb.Contains(LatLng{Lat: 0, Lng: 179}) || !bb.Contains(LatLng{Lat: 0, Lng: -175}) || bb.Contains(LatLng{Lat: 0, Lng: 0}) {
+		t.Errorf("expected the box to cross the antimeridian, got %v", bb)
+	}
+	for _, s := range []string{"52.4,4.7,52.3,5.0", "1,2,3", "a;b"} {
+		if err := bb.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
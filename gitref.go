@@ -0,0 +1,139 @@
+package argflags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VerifyGitRef, when set, is called with every GitRef flag value, once its syntax has been validated,
+// to check the ref exists. e.g. by running 'git rev-parse --verify'.  Any error it returns fails the flag.
+var VerifyGitRef func(ref GitRef) error
+
+// GitRefKind is the kind of a GitRef, a commit SHA, a ref name, such as a branch or tag, or a refspec.
+type GitRefKind int
+
+const (
+	GitRefName GitRefKind = iota
+	GitSHA
+	GitRefspec
+)
+
+func (k GitRefKind) String() string {
+	switch k {
+	case GitSHA:
+		return "sha"
+	case GitRefspec:
+		return "refspec"
+	}
+	return "ref"
+}
+
+// GitRef is a git revision or refspec.
+// A revision is either a commit SHA, full or abbreviated to at least 4 hex digits, or a ref name, such as 'main',
+// 'v1.2.0' or 'refs/heads/main', validated with the rules of 'git check-ref-format'.
+// Revisions may be followed by ancestry suffixes, e.g. 'HEAD~2' or 'main^'.
+// A refspec is an optional '+', followed by a source and destination, separated by a colon, e.g. '+refs/heads/*:refs/remotes/origin/*'
+type GitRef struct {
+	Kind GitRefKind
+	// Ref is the revision, or the whole refspec.
+	Ref string
+	// Src and Dst are the source and destination of a refspec.
+	Src, Dst string
+	// Force is set on a refspec given with a leading '+'.
+	Force bool
+}
+
+// IsBranch checks if the ref is fully qualified as a branch, in refs/heads/
+func (gr GitRef) IsBranch() bool {
+	return gr.Kind == GitRefName && strings.HasPrefix(gr.Ref, "refs/heads/")
+}
+
+// IsTag checks if the ref is fully qualified as a tag, in refs/tags/
+func (gr GitRef) IsTag() bool {
+	return gr.Kind == GitRefName && strings.HasPrefix(gr.Ref, "refs/tags/")
+}
+
+func (gr GitRef) String() string {
+	return gr.Ref
+}
+
+func (gr GitRef) MarshalText() ([]byte, error) {
+	return []byte(gr.String()), nil
+}
+
+func (gr *GitRef) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	v, err := parseGitRef(s)
+	if err != nil {
+		return fmt.Errorf("invalid git ref %q  %v", s, err)
+	}
+	if VerifyGitRef != nil {
+		if err := VerifyGitRef(v); err != nil {
+			return fmt.Errorf("git ref %q  %v", s, err)
+		}
+	}
+	*gr = v
+	return nil
+}
+
+func parseGitRef(s string) (GitRef, error) {
+	if strings.HasPrefix(s, "+") || strings.Contains(s, ":") {
+		src, dst, _ := strings.Cut(strings.TrimPrefix(s, "+"), ":")
+		for _, side := range []string{src, dst} {
+			if side == "" {
+				continue
+			}
+			if err := checkRefName(strings.Replace(side, "*", "x", 1)); err != nil {
+				return GitRef{}, err
+			}
+		}
+		if strings.Contains(src, "*") != strings.Contains(dst, "*") && dst != "" {
+			return GitRef{}, fmt.Errorf("refspec patterns must have a '*' in both the source and destination")
+		}
+		return GitRef{Kind: GitRefspec, Ref: s, Src: src, Dst: dst, Force: strings.HasPrefix(s, "+")}, nil
+	}
+	name := s
+	if i := strings.IndexAny(s, "~^"); i >= 0 {
+		name = s[:i]
+		if strings.Trim(s[i:], "~^0123456789") != "" {
+			return GitRef{}, fmt.Errorf("invalid ancestry suffix %q", s[i:])
+		}
+	}
+	if isGitSHA(name) {
+		return GitRef{Kind: GitSHA, Ref: s}, nil
+	}
+	if err := checkRefName(name); err != nil {
+		return GitRef{}, err
+	}
+	return GitRef{Kind: GitRefName, Ref: s}, nil
+}
+
+// isGitSHA checks if the given name is 4 to 64 lower case hex digits
+func isGitSHA(s string) bool {
+	return len(s) >= 4 && len(s) <= 64 && strings.Trim(s, "0123456789abcdef") == ""
+}
+
+// checkRefName checks the given name follows the rules of 'git check-ref-format'
+func checkRefName(name string) error {
+	switch {
+	case name == "" || name == "@":
+		return fmt.Errorf("ref name can not be empty or '@'")
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.HasSuffix(name, "."):
+		return fmt.Errorf("ref name can not begin or end with '/' or end with '.'")
+	case strings.Contains(name, "..") || strings.Contains(name, "//") || strings.Contains(name, "@{"):
+		return fmt.Errorf("ref name can not contain '..', '//' or '@{'")
+	case strings.ContainsAny(name, " ~^:?*[\\\x7f"):
+		return fmt.Errorf("ref name can not contain spaces or any of ~^:?*[\\")
+	}
+	for _, r := range name {
+		if r < 0x20 {
+			return fmt.Errorf("ref name can not contain control characters")
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return fmt.Errorf("ref name components can not begin with '.' or end with '.lock'")
+		}
+	}
+	return nil
+}
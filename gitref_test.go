@@ -0,0 +1,61 @@
+package argflags
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGitRef(t *testing.T) {
+	tests := map[string]GitRefKind{
+		"main":                                GitRefName,
+		"v1.2.0":                              GitRefName,
+		"refs/heads/main":                     GitRefName,
+		"HEAD~2":                              GitRefName,
+		"main^":                               GitRefName,
+		"a1b2":                                GitSHA,
+		"deadbeef~1":                          GitSHA,
+		"+refs/heads/*:refs/remotes/origin/*": GitRefspec,
+		"main:refs/heads/main":                GitRefspec,
+	}
+	for s, kind := range tests {
+		var gr GitRef
+		if err := gr.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if gr.Kind != kind || gr.String() != s {
+			t.Errorf("%s  expected a %s, got a %s, %s", s, kind, gr.Kind, gr)
+		}
+	}
+	var gr GitRef
+	if err := gr.UnmarshalText([]byte("+refs/heads/*:refs/remotes/origin/*")); err != nil || !gr.Force || gr.Src != "refs/heads/*" || gr.Dst != "refs/remotes/origin/*" {
+		t.Errorf("expected a forced refspec, got %+v, %v", gr, err)
+	}
+	if gr.UnmarshalText([]byte("refs/tags/v1")); !gr.IsTag() || gr.IsBranch() {
+		t.Errorf("expected a tag, got %+v", gr)
+	}
+	for _, s := range []string{"", "@", "a..b", "/main", "main.", "a b", "main.lock", ".hidden", "main~x", "refs/heads/*:refs/x"} {
+		if err := gr.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestVerifyGitRef(t *testing.T) {
+	defer func(verify func(ref GitRef) error) {
+		VerifyGitRef = verify
+	}(VerifyGitRef)
+	VerifyGitRef = func(ref GitRef) error {
+		if ref.Ref != "main" {
+			return errors.New("not found")
+		}
+		return nil
+	}
+	var gr GitRef
+	if err := gr.UnmarshalText([]byte("main")); err != nil {
+		t.Errorf("unexpected error  %v", err)
+	}
+	if err := gr.UnmarshalText([]byte("develop")); err == nil || gr.Ref != "main" {
+		t.Errorf("expected a ref failing verification to be an error, got %+v, %v", gr, err)
+	}
+}
@@ -0,0 +1,109 @@
+package argflags
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Grouped sub args
+// A slice of structs, or pointers to structs, tagged as a prefixed sub arg, is a grouped sub arg, e.g.
+// Targets []Target `flag:"+target"`
+// Its prefix is a sentinel flag, each '-target' starting a new element of the slice,
+// with the flags following it, up to the next '-target', setting the fields of that element.
+// e.g. '-target -host a -port 80 -target -host b' sets two Targets, {a 80} and {b 0}.
+// Flags of the element take precedence over the other flags of the struct, whilst it is the current element.
+// The sentinel flag takes no value.
+
+// isGroupType checks if the given type may be a grouped sub arg, a slice of structs or pointers to structs.
+func isGroupType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && isSubArgType(t.Elem())
+}
+
+// isGroup checks if the given field is a grouped sub arg.
+func (p *Parser) isGroup(f reflect.StructField) bool {
+	return p.isSubArg(f) && isGroupType(f.Type)
+}
+
+// groupElemType gets the struct type of the elements of a grouped sub arg field type.
+func groupElemType(t reflect.Type) reflect.Type {
+	t = t.Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// startGroup adds a new element to the grouped sub arg field of the given sentinel flag, making it the current element,
+// which following flags are first matched to.
+func (a *applier) startGroup(flag string, fld *flagField) error {
+	slice := fld.fldValue
+	elem := reflect.New(groupElemType(slice.Type()))
+	if slice.Type().Elem().Kind() == reflect.Ptr {
+		slice.Set(reflect.Append(slice, elem))
+	} else {
+		slice.Set(reflect.Append(slice, elem.Elem()))
+	}
+	a.group = groupElem{slice: slice, index: slice.Len() - 1, path: fld.path(), flag: flag}
+	return a.p.beforeApply(a.group.value())
+}
+
+// groupElem is the current element of a grouped sub arg.
+type groupElem struct {
+	slice reflect.Value
+	index int
+	// path is the dot delimited path of field names to the grouped sub arg field.
+	path string
+	// flag is the sentinel flag, as given, which started the element.
+	flag string
+}
+
+// isValid checks if there is a current element.
+func (g groupElem) isValid() bool {
+	return g.slice.IsValid()
+}
+
+// value gets the struct value of the element.
+// The element is found each time, as adding to the slice may move its elements.
+func (g groupElem) value() reflect.Value {
+	v := g.slice.Index(g.index)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return v
+}
+
+// findGroupField finds the field for the given flag name in the current element of a grouped sub arg.
+// returns nil if there is no current element, or it has no matching field.
+func (a *applier) findGroupField(name string) *flagField {
+	if !a.group.isValid() {
+		return nil
+	}
+	fld, err := a.p.newFlagField(name, a.group.value())
+	if err != nil {
+		return nil
+	}
+	fld.parent = strings.Join([]string{a.group.path, strconv.Itoa(a.group.index)}, ".")
+	return fld
+}
+
+// groupArgs gets the arguments setting each element of the given grouped sub arg field,
+// each element being the sentinel flag followed by the flags of its fields.
+func (p *Parser) groupArgs(name string, fld reflect.Value) ([]string, error) {
+	var args []string
+	for i := 0; i < fld.Len(); i++ {
+		elem := fld.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+		eargs, err := p.structFlagArgs(elem)
+		if err != nil {
+			return nil, ErrElement{Index: i, Err: err}
+		}
+		args = append(append(args, "-"+name), eargs...)
+	}
+	return args, nil
+}
@@ -0,0 +1,47 @@
+package argflags
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type target struct {
+	Host string `flag:"host"`
+	Port int    `flag:"port"`
+}
+
+type targetFlags struct {
+	Name    string   `flag:"name"`
+	Targets []target `flag:"+target"`
+}
+
+func TestGroupedSubArgs(t *testing.T) {
+	var tf targetFlags
+	args := []string{"-target", "-host", "a", "-port", "80", "-name", "n", "-target", "-host", "b"}
+	if _, err := NewParser().Apply(args, &tf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := targetFlags{Name: "n", Targets: []target{{Host: "a", Port: 80}, {Host: "b"}}}
+	if !reflect.DeepEqual(tf, expect) {
+		t.Errorf("expected %+v, got %+v", expect, tf)
+	}
+
+	out, err := ToArgs(&tf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	var rt targetFlags
+	if _, err := out.ApplyTo(&rt); err != nil || !reflect.DeepEqual(rt, expect) {
+		t.Errorf("expected %+v from %v, got %+v  %v", expect, out, rt, err)
+	}
+}
+
+func TestGroupedSubArgElementError(t *testing.T) {
+	var tf targetFlags
+	_, err := NewParser().Apply([]string{"-target", "-port", "80", "-target", "-port", "x"}, &tf)
+	var ee ErrElement
+	if !errors.As(err, &ee) || ee.Index != 1 {
+		t.Errorf("expected an error of the second element, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package argflags
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// optNoHash leaves a flag out of the Hash of its struct, for flags which do not change the effective configuration,
+// e.g. Verbose bool `flag:"verbose,nohash"`
+const optNoHash = "nohash"
+
+// Hash gets a digest of the effective flag values of the given option struct, or struct pointer,
+// so a change to the configuration can be detected across restarts, or the options used in a cache key.
+// The digest is of the field names and their formatted values, as ToArgs gives them, so is the same for equal values,
+// however they were set, and whatever the flags are named.  Map entries are digested in key order.
+// Fields with a zero value are left out, so adding a new flag to the struct does not change the digest until it is set.
+// Fields tagged as `flag:"-"`, secret fields and flags tagged with the 'nohash' option are also left out,
+// as are the flags of sub args which are not in use, such as nil ones.
+func Hash(str interface{}) string {
+	return NewParser().Hash(str)
+}
+
+// Hash gets a digest of the effective flag values of the given option struct, as named by the parser.  See Hash.
+func (p *Parser) Hash(str interface{}) string {
+	h := sha256.New()
+	if v := reflect.Indirect(reflect.ValueOf(str)); v.Kind() == reflect.Struct {
+		h.Write([]byte(v.Type().String()))
+		p.hashStruct(h, v, "")
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashStruct writes the path and values of each flag, and positional field, of the given struct, to the given hash.
+// parent is the path to the struct, when it is an element of a grouped sub arg, or empty.
+func (p *Parser) hashStruct(h hash.Hash, v reflect.Value, parent string) {
+	write := func(path string, f reflect.StructField, fld reflect.Value) {
+		if fld.IsZero() || p.hasTagOption(f, optNoHash) || p.isSecretField(f, fld) {
+			return
+		}
+		if parent != "" {
+			path = strings.Join([]string{parent, path}, ".")
+		}
+		h.Write([]byte{0})
+		h.Write([]byte(path))
+		for _, value := range p.diffFormat(f, fld) {
+			h.Write([]byte{1})
+			h.Write([]byte(value))
+		}
+	}
+	for _, fd := range p.describeFlags(v.Type()) {
+		fld, ok := p.fieldInUse(v, fd.index)
+		if !ok {
+			continue
+		}
+		path := fieldNamePath(v.Type(), fd.index)
+		if p.isGroup(fd.field) {
+			if p.hasTagOption(fd.field, optNoHash) {
+				continue
+			}
+			for i := 0; i < fld.Len(); i++ {
+				if elem := reflect.Indirect(fld.Index(i)); elem.IsValid() {
+					p.hashStruct(h, elem, strings.Join([]string{path, strconv.Itoa(i)}, "."))
+				}
+			}
+			continue
+		}
+		write(path, fd.field, fld)
+	}
+	fields, _ := positionalFields(v.Type())
+	for _, pf := range fields {
+		if p.isPositionalOnly(pf.field) {
+			write(pf.field.Name, pf.field, v.Field(pf.index))
+		}
+	}
+}
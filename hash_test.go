@@ -0,0 +1,25 @@
+package argflags
+
+import "testing"
+
+type hashFlags struct {
+	Host     string            `flag:"host"`
+	Labels   map[string]string `flag:"label"`
+	Verbose  bool              `flag:"verbose,nohash"`
+	Password string            `flag:"password,secret"`
+}
+
+func TestHash(t *testing.T) {
+	a := hashFlags{Host: "h", Labels: map[string]string{"a": "1", "b": "2", "c": "3"}}
+	b := hashFlags{Host: "h", Labels: map[string]string{"c": "3", "b": "2", "a": "1"}, Verbose: true, Password: "p"}
+	if Hash(&a) != Hash(b) {
+		t.Errorf("expected the same hash, ignoring map order, nohash and secret fields")
+	}
+	if Hash(a) == Hash(resultFlags{Host: "h"}) {
+		t.Errorf("expected structs of different types to hash differently")
+	}
+	a.Host = "other"
+	if Hash(a) == Hash(b) {
+		t.Errorf("expected a different hash for a different host")
+	}
+}
@@ -0,0 +1,76 @@
+package argflags
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// Header is a set of HTTP headers, given as 'Name: value', as with curl's -H flag.
+// Header names are canonicalized, so 'content-type: text/plain' is set as 'Content-Type'.
+// A header flag given more than once accumulates each header, with repeated names holding multiple values.
+// e.g. -H 'Accept: text/html' -H 'Accept: application/json' -H 'X-Trace: 1'
+// As header values may contain commas, each flag value is a single header.
+type Header http.Header
+
+// HTTP gets the headers as an http.Header
+func (h Header) HTTP() http.Header {
+	return http.Header(h)
+}
+
+// String gets the headers as 'Name: value' lines, in name order.
+func (h Header) String() string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var lines []string
+	for _, name := range names {
+		for _, value := range h[name] {
+			lines = append(lines, fmt.Sprintf("%s: %s", name, value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (h Header) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// MarshalArgs gets a flag for each header value, in name order, with the first of the given names.
+func (h Header) MarshalArgs(names []string) ([]string, error) {
+	keys := make([]string, 0, len(h))
+	for name := range h {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	var args []string
+	for _, name := range keys {
+		for _, value := range h[name] {
+			args = append(args, fmt.Sprintf("-%s=%s: %s", names[0], name, value))
+		}
+	}
+	return args, nil
+}
+
+func (h *Header) UnmarshalText(text []byte) error {
+	*h = Header{}
+	return h.AddText(text)
+}
+
+// AddText adds the given 'Name: value' header to the existing headers.
+func (h *Header) AddText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	name, value, ok := strings.Cut(s, ":")
+	if !ok || name == "" || strings.ContainsAny(name, " \t\r\n") {
+		return fmt.Errorf("invalid header %q, expected 'Name: value'", s)
+	}
+	if *h == nil {
+		*h = Header{}
+	}
+	http.Header(*h).Add(textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(value))
+	return nil
+}
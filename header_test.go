@@ -0,0 +1,32 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHeader(t *testing.T) {
+	var flags struct {
+		Headers Header `flag:"H"`
+	}
+	args := []string{"-H", "content-type: text/plain", "-H", "Accept: text/html, application/json", "-H", "accept:*/*"}
+	if _, err := ArgFlags(args).ApplyTo(&flags); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := Header{"Content-Type": {"text/plain"}, "Accept": {"text/html, application/json", "*/*"}}
+	if !reflect.DeepEqual(flags.Headers, expect) {
+		t.Errorf("expected %v, got %v", expect, flags.Headers)
+	}
+	if flags.Headers.HTTP().Get("content-type") != "text/plain" {
+		t.Errorf("expected the canonical header names, got %v", flags.Headers)
+	}
+	if s := flags.Headers.String(); s != "Accept: text/html, application/json\nAccept: */*\nContent-Type: text/plain" {
+		t.Errorf("expected the headers in name order, got %q", s)
+	}
+	for _, s := range []string{"Accept", ": x", "Bad Name: x"} {
+		var h Header
+		if err := h.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package argflags
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistoryEntry is a single recorded invocation of a command.
+type HistoryEntry struct {
+	// Time is when the command was invoked.
+	Time time.Time `json:"time"`
+	// Args are the arguments the command was invoked with, with the values of secret flags masked.
+	Args []string `json:"args"`
+	// Error is the error message of the invocation if it failed, or empty if it succeeded.
+	Error string `json:"error,omitempty"`
+	// Secrets are the indexes of the Args with a masked secret value, either as a whole argument or attached to its flag.
+	Secrets []int `json:"secrets,omitempty"`
+}
+
+// HasSecrets checks if the entry has any masked secret values, so can not be rerun as recorded.
+// Entries without their Secrets recorded are checked for the mask in any of their arguments.
+func (entry HistoryEntry) HasSecrets() bool {
+	if len(entry.Secrets) > 0 {
+		return true
+	}
+	for _, arg := range entry.Args {
+		if strings.Contains(arg, secretMask) {
+			return true
+		}
+	}
+	return false
+}
+
+// Recorder records each invocation of a command, appending it to a history file, as a line of JSON.
+// The values of secret flags are masked, so are never written to the history.
+type Recorder struct {
+	// Path is the path of the history file.
+	Path string
+	mu   sync.Mutex
+	// own are the history commands created by the recorder, which are not recorded
+	own []*Command
+}
+
+// Middleware gets the Middleware which records each invocation, once it has completed.
+// It should be added to the root command, with Use, to record invocations of every command.
+func (r *Recorder) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, inv *Invocation) error {
+			if r.isOwnCommand(inv.Command) {
+				return next(ctx, inv)
+			}
+			started := time.Now()
+			err := next(ctx, inv)
+			entry := HistoryEntry{Time: started, Args: inv.MaskedArgs, Secrets: inv.secrets}
+			if err != nil {
+				entry.Error = err.Error()
+			}
+			if rerr := r.Record(entry); rerr != nil {
+				return errors.Join(err, rerr)
+			}
+			return err
+		}
+	}
+}
+
+// Record appends the given entry to the history file.
+func (r *Recorder) Record(entry HistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, err := os.OpenFile(r.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// History reads all the entries in the history file, oldest first.
+// A missing history file has no entries.
+func (r *Recorder) History() ([]HistoryEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, err := os.Open(r.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s  %v", r.Path, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Commands gets the 'history' and 'rerun' commands, to be added to the given root command.
+// 'history' lists the recorded invocations, numbered from 1.
+// 'rerun N' executes the Nth recorded invocation again, on the root command.
+// Invocations containing masked secret values can not be rerun.
+func (r *Recorder) Commands(root *Command) []*Command {
+	history := &Command{
+		Name:    "history",
+		Summary: "list the previous invocations",
+		Handler: func(ctx context.Context, inv *Invocation) error {
+			entries, err := r.History()
+			if err != nil {
+				return err
+			}
+			out := Output(ctx)
+			for i, entry := range entries {
+				status := "ok"
+				if entry.Error != "" {
+					status = "failed"
+				}
+				fmt.Fprintf(out, "%5d  %s  %-6s  %s\n", i+1, entry.Time.Format(time.RFC3339), status, strings.Join(entry.Args, " "))
+			}
+			return nil
+		},
+	}
+	rerun := &Command{
+		Name:    "rerun",
+		Summary: "execute a previous invocation again, by its history number",
+		Handler: func(ctx context.Context, inv *Invocation) error {
+			if len(inv.Args) != 1 {
+				return fmt.Errorf("rerun requires a single history number")
+			}
+			n, err := strconv.Atoi(inv.Args[0])
+			if err != nil {
+				return fmt.Errorf("invalid history number  %v", err)
+			}
+			entries, err := r.History()
+			if err != nil {
+				return err
+			}
+			if n < 1 || n > len(entries) {
+				return fmt.Errorf("history number %d not found", n)
+			}
+			if entries[n-1].HasSecrets() {
+				return fmt.Errorf("history number %d contains secret values and can not be rerun", n)
+			}
+			return root.Execute(ctx, entries[n-1].Args)
+		},
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.own = append(r.own, history, rerun)
+	return []*Command{history, rerun}
+}
+
+func (r *Recorder) isOwnCommand(cmd *Command) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, own := range r.own {
+		if own == cmd {
+			return true
+		}
+	}
+	return false
+}
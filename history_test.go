@@ -0,0 +1,115 @@
+package argflags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	type loginFlags struct {
+		User     string `flag:"user"`
+		Password string `flag:"password,secret"`
+	}
+	r := &Recorder{Path: filepath.Join(t.TempDir(), "history")}
+	var users []string
+	lf := &loginFlags{}
+	root := &Command{Name: "app"}
+	root.Use(r.Middleware())
+	root.AddCommand(&Command{Name: "login", Flags: lf, Handler: func(ctx context.Context, inv *Invocation) error {
+		users = append(users, lf.User)
+		if lf.User == "" {
+			return errors.New("no user")
+		}
+		return nil
+	}})
+	root.AddCommand(r.Commands(root)...)
+
+	if err := root.Execute(context.Background(), []string{"login", "-user", "bob", "-password", "s3cret"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if err := root.Execute(context.Background(), []string{"login", "-user", "ann"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	lf.User = ""
+	if err := root.Execute(context.Background(), []string{"login"}); err == nil {
+		t.Fatalf("expected the login to fail")
+	}
+	entries, err := r.History()
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if len(entries) != 3 || !reflect.DeepEqual(entries[0].Args, []string{"login", "-user", "bob", "-password", secretMask}) {
+		t.Fatalf("expected the secret to be masked, got %+v", entries)
+	}
+	if entries[1].Error != "" || entries[2].Error != "no user" {
+		t.Errorf("expected the error of the failed invocation, got %+v", entries)
+	}
+
+	if err := root.Execute(context.Background(), []string{"rerun", "1"}); err == nil {
+		t.Errorf("expected an invocation with a secret not to be rerun")
+	}
+	if err := root.Execute(context.Background(), []string{"rerun", "2"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(users, []string{"bob", "ann", "", "ann"}) {
+		t.Errorf("expected the second invocation to be rerun, got %v", users)
+	}
+	buf := &bytes.Buffer{}
+	if err := root.Execute(context.WithValue(context.Background(), outputKey{}, buf), []string{"history"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[2], "failed") || !strings.HasSuffix(lines[0], "login -user bob -password "+secretMask) {
+		t.Errorf("expected the history, without its own commands, got %q", buf.String())
+	}
+}
+
+func TestRerunAttachedSecrets(t *testing.T) {
+	type loginFlags struct {
+		User     string `flag:"user"`
+		Password string `flag:"password,p,secret"`
+	}
+	r := &Recorder{Path: filepath.Join(t.TempDir(), "history")}
+	var passwords []string
+	lf := &loginFlags{}
+	root := &Command{Name: "app"}
+	root.Use(r.Middleware())
+	root.AddCommand(&Command{Name: "login", Flags: lf, Handler: func(ctx context.Context, inv *Invocation) error {
+		passwords = append(passwords, lf.Password)
+		return nil
+	}})
+	root.AddCommand(r.Commands(root)...)
+
+	for _, args := range [][]string{{"login", "--password=s3cret"}, {"login", "-user", "bob", "-p=s3cret"}} {
+		if err := root.Execute(context.Background(), args); err != nil {
+			t.Fatalf("unexpected error  %v", err)
+		}
+	}
+	// an entry recorded without the indexes of its secrets
+	if err := r.Record(HistoryEntry{Args: []string{"login", "-p=" + secretMask}}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	entries, err := r.History()
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if len(entries) != 3 || !reflect.DeepEqual(entries[0].Secrets, []int{1}) || !reflect.DeepEqual(entries[1].Secrets, []int{3}) {
+		t.Fatalf("expected the indexes of the secrets, got %+v", entries)
+	}
+	if entries[1].Args[3] != "-p="+secretMask {
+		t.Errorf("expected the attached secret to be masked, got %v", entries[1].Args)
+	}
+	for _, n := range []string{"1", "2", "3"} {
+		if err := root.Execute(context.Background(), []string{"rerun", n}); err == nil || !strings.Contains(err.Error(), "secret") {
+			t.Errorf("rerun %s  expected an invocation with a secret not to be rerun, got %v", n, err)
+		}
+	}
+	if !reflect.DeepEqual(passwords, []string{"s3cret", "s3cret"}) {
+		t.Errorf("expected no rerun with the masked password, got %v", passwords)
+	}
+}
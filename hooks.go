@@ -0,0 +1,80 @@
+package argflags
+
+import (
+	"errors"
+	"reflect"
+)
+
+// BeforeApplier is implemented by flag structs, and sub arg structs, which prepare themselves before flags are applied to them.
+// BeforeApply is called before any flag is applied, on the struct, then on each of its sub args which is not nil, and
+// on each element of a grouped sub arg, as the element is added.
+type BeforeApplier interface {
+	BeforeApply() error
+}
+
+// AfterApplier is implemented by flag structs, and sub arg structs, which check or complete themselves once all flags are applied.
+// AfterApply is called once the flags, environment variables and defaults are all applied, and validated, without error.
+// It is called on each sub arg which is not nil, and each element of a grouped sub arg, before the struct containing them,
+// so a struct may rely on its sub args being complete, for cross field checks and derived defaults.
+type AfterApplier interface {
+	AfterApply() error
+}
+
+// beforeApply calls BeforeApply on the given struct, then its sub args, if they implement BeforeApplier.
+func (p *Parser) beforeApply(v reflect.Value) error {
+	if ba, ok := v.Addr().Interface().(BeforeApplier); ok {
+		if err := ba.BeforeApply(); err != nil {
+			return err
+		}
+	}
+	var errs []error
+	for _, sub := range p.subArgValues(v) {
+		errs = append(errs, p.beforeApply(sub))
+	}
+	return errors.Join(errs...)
+}
+
+// afterApply calls AfterApply on the sub args of the given struct, then the struct, if they implement AfterApplier.
+func (p *Parser) afterApply(v reflect.Value) error {
+	var errs []error
+	for _, sub := range p.subArgValues(v) {
+		errs = append(errs, p.afterApply(sub))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if aa, ok := v.Addr().Interface().(AfterApplier); ok {
+		return aa.AfterApply()
+	}
+	return nil
+}
+
+// subArgValues gets the struct values of the sub args of the given struct, which are not nil, and of the elements of its grouped sub args.
+func (p *Parser) subArgValues(v reflect.Value) []reflect.Value {
+	var subs []reflect.Value
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() || !p.isSubArg(f) {
+			continue
+		}
+		fld := v.Field(i)
+		if p.isGroup(f) {
+			for j := 0; j < fld.Len(); j++ {
+				if elem := reflect.Indirect(fld.Index(j)); elem.IsValid() {
+					subs = append(subs, elem)
+				}
+			}
+			continue
+		}
+		if fld.Kind() == reflect.Ptr {
+			if fld.IsNil() {
+				continue
+			}
+			fld = fld.Elem()
+		}
+		if fld.Kind() == reflect.Struct {
+			subs = append(subs, fld)
+		}
+	}
+	return subs
+}
@@ -0,0 +1,53 @@
+package argflags
+
+import (
+	"fmt"
+	"testing"
+)
+
+type hookedOpts struct {
+	Host  string `flag:"hook-host"`
+	calls []string
+}
+
+func (ho *hookedOpts) BeforeApply() error {
+	ho.calls = append(ho.calls, "opts before")
+	return nil
+}
+
+func (ho *hookedOpts) AfterApply() error {
+	ho.calls = append(ho.calls, "opts after")
+	return nil
+}
+
+type hookedFlags struct {
+	Port  int         `flag:"port"`
+	Opts  *hookedOpts `flag:"+"`
+	calls []string
+}
+
+func (hf *hookedFlags) BeforeApply() error {
+	hf.calls = append(hf.calls, "before")
+	return nil
+}
+
+func (hf *hookedFlags) AfterApply() error {
+	if hf.Port == 0 {
+		return fmt.Errorf("a port is required")
+	}
+	hf.calls = append(hf.calls, "after")
+	return nil
+}
+
+func TestApplyHooks(t *testing.T) {
+	hf := hookedFlags{Opts: &hookedOpts{}}
+	if _, err := NewParser().Apply([]string{"-port", "80", "-hook-host", "h"}, &hf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if fmt.Sprint(hf.calls) != "[before after]" || fmt.Sprint(hf.Opts.calls) != "[opts before opts after]" {
+		t.Errorf("unexpected hook calls %v and %v", hf.calls, hf.Opts.calls)
+	}
+	if _, err := NewParser().Apply(nil, &hookedFlags{}); err == nil {
+		t.Errorf("expected the AfterApply error")
+	}
+}
@@ -0,0 +1,133 @@
+package argflags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultRegistry is the registry of image references which do not name a registry.
+const DefaultRegistry = "docker.io"
+
+// DefaultTag is the tag of image references which have neither a tag or a digest.
+const DefaultTag = "latest"
+
+// ImageRef is a container image reference, '[registry[:port]/]repository[:tag][@digest]'
+// References are normalized, so 'nginx' is set as 'docker.io/library/nginx:latest'.
+// The first part of the repository path is taken as the registry, when it contains a '.' or ':', or is 'localhost'.
+// Images in the default registry, without a path, are in the 'library' path.
+type ImageRef struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// Name gets the registry and repository of the image, without its tag or digest. e.g. 'docker.io/library/nginx'
+func (ir ImageRef) Name() string {
+	return strings.Join([]string{ir.Registry, ir.Repository}, "/")
+}
+
+func (ir ImageRef) String() string {
+	if ir == (ImageRef{}) {
+		return ""
+	}
+	s := ir.Name()
+	if ir.Tag != "" {
+		s = strings.Join([]string{s, ir.Tag}, ":")
+	}
+	if ir.Digest != "" {
+		s = strings.Join([]string{s, ir.Digest}, "@")
+	}
+	return s
+}
+
+func (ir ImageRef) MarshalText() ([]byte, error) {
+	return []byte(ir.String()), nil
+}
+
+func (ir *ImageRef) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	v, err := parseImageRef(s)
+	if err != nil {
+		return fmt.Errorf("invalid image reference %q  %v", s, err)
+	}
+	*ir = v
+	return nil
+}
+
+func parseImageRef(s string) (ImageRef, error) {
+	var ir ImageRef
+	name, digest, hasDigest := strings.Cut(s, "@")
+	if hasDigest {
+		if err := checkDigest(digest); err != nil {
+			return ImageRef{}, err
+		}
+		ir.Digest = digest
+	}
+	// a tag follows the last colon, after the last slash, so a registry port is not taken as a tag
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ir.Tag = name[:i], name[i+1:]
+		if !isImageTag(ir.Tag) {
+			return ImageRef{}, fmt.Errorf("invalid tag %q", ir.Tag)
+		}
+	}
+	if first, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ir.Registry, name = first, rest
+	}
+	if ir.Registry == "" {
+		ir.Registry = DefaultRegistry
+	}
+	if ir.Registry == DefaultRegistry && !strings.Contains(name, "/") {
+		name = strings.Join([]string{"library", name}, "/")
+	}
+	for _, part := range strings.Split(name, "/") {
+		if !isRepositoryPart(part) {
+			return ImageRef{}, fmt.Errorf("invalid repository %q, expected lower case names separated by '/'", name)
+		}
+	}
+	ir.Repository = name
+	if ir.Tag == "" && ir.Digest == "" {
+		ir.Tag = DefaultTag
+	}
+	return ir, nil
+}
+
+// isRepositoryPart checks the given path component is lower case alphanumerics, separated by '.', '_', '__' or '-'
+func isRepositoryPart(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		alnum := (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+		if !alnum && (i == 0 || i == len(s)-1 || !strings.ContainsRune("._-", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isImageTag checks the given tag is up to 128 word characters, '.' or '-', not beginning with '.' or '-'.
+func isImageTag(s string) bool {
+	if s == "" || len(s) > 128 {
+		return false
+	}
+	for i, r := range s {
+		word := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_'
+		if !word && (i == 0 || (r != '.' && r != '-')) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDigest checks the given digest is an algorithm and hex encoded hash, e.g. 'sha256:<64 hex digits>'
+func checkDigest(s string) error {
+	alg, hash, ok := strings.Cut(s, ":")
+	if !ok || alg == "" || hash == "" || strings.Trim(hash, "0123456789abcdef") != "" {
+		return fmt.Errorf("invalid digest %q, expected algorithm:hex", s)
+	}
+	if alg == "sha256" && len(hash) != 64 {
+		return fmt.Errorf("invalid digest %q, sha256 digests have 64 hex digits", s)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package argflags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImageRef(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := map[string]string{
+		"nginx":                            "docker.io/library/nginx:latest",
+		"bitnami/redis:7.2":                "docker.io/bitnami/redis:7.2",
+		"localhost:5000/app":               "localhost:5000/app:latest",
+		"ghcr.io/org/team/app:v1.0":        "ghcr.io/org/team/app:v1.0",
+		"registry.example.com/a@" + digest: "registry.example.com/a@" + digest,
+	}
+	for s, expect := range tests {
+		var ir ImageRef
+		if err := ir.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if ir.String() != expect {
+			t.Errorf("%s  expected %s, got %s", s, expect, ir)
+		}
+	}
+	var ir ImageRef
+	if err := ir.UnmarshalText([]byte("localhost:5000/app:dev")); err != nil || ir.Registry != "localhost:5000" || ir.Tag != "dev" || ir.Name() != "localhost:5000/app" {
+		t.Errorf("expected the registry port not to be taken as a tag, got %+v, %v", ir, err)
+	}
+	for _, s := range []string{"", "Nginx", "nginx:", "nginx:-x", "a//b", "nginx@sha256:abc", "nginx@md5"} {
+		if err := ir.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package argflags
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// quantityBinarySuffixes are the power of two suffixes of a ResourceQuantity, as their power of two.
+var quantityBinarySuffixes = map[string]int{"Ki": 10, "Mi": 20, "Gi": 30, "Ti": 40, "Pi": 50, "Ei": 60}
+
+// quantityDecimalSuffixes are the power of ten suffixes of a ResourceQuantity, as their power of ten.
+var quantityDecimalSuffixes = map[string]int{"n": -9, "u": -6, "m": -3, "": 0, "k": 3, "M": 6, "G": 9, "T": 12, "P": 15, "E": 18}
+
+// ResourceQuantity is a kubernetes style resource quantity, such as '500Mi', '2', '250m' or '1.5G'.
+// It accepts a signed decimal number, followed by an optional binary suffix (Ki, Mi, Gi, Ti, Pi, Ei),
+// decimal suffix (n, u, m, k, M, G, T, P, E) or a decimal exponent (e.g. '1e3').
+// The quantity is held exactly, and formatted as it was given.
+type ResourceQuantity struct {
+	s string
+	r *big.Rat
+}
+
+// Rat gets the quantity as an exact big.Rat
+func (q ResourceQuantity) Rat() *big.Rat {
+	if q.r == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(q.r)
+}
+
+// Value gets the quantity as a whole number, rounded up, as kubernetes does.  e.g. '250m' is 1.
+func (q ResourceQuantity) Value() int64 {
+	return ceilRat(q.Rat())
+}
+
+// MilliValue gets the quantity in thousandths, rounded up. e.g. '0.25' is 250.
+func (q ResourceQuantity) MilliValue() int64 {
+	return ceilRat(q.Rat().Mul(q.Rat(), big.NewRat(1000, 1)))
+}
+
+// Float64 gets the nearest float64 to the quantity.
+func (q ResourceQuantity) Float64() float64 {
+	f, _ := q.Rat().Float64()
+	return f
+}
+
+func (q ResourceQuantity) String() string {
+	if q.s == "" {
+		return "0"
+	}
+	return q.s
+}
+
+func (q ResourceQuantity) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+func (q *ResourceQuantity) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	num, suffix := splitQuantity(s)
+	r, ok := new(big.Rat).SetString(num)
+	if num == "" || strings.ContainsAny(num, "/eE") || !ok {
+		return fmt.Errorf("invalid quantity %q, expected a number with an optional suffix, e.g. '500Mi'", s)
+	}
+	scale, err := quantityScale(suffix)
+	if err != nil {
+		return fmt.Errorf("invalid quantity %q  %v", s, err)
+	}
+	*q = ResourceQuantity{s: s, r: r.Mul(r, scale)}
+	return nil
+}
+
+// splitQuantity splits the given quantity into its number and suffix.
+func splitQuantity(s string) (num, suffix string) {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	for i < len(s) && (s[i] == '.' || (s[i] >= '0' && s[i] <= '9')) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// quantityScale gets the multiple of the given quantity suffix.
+func quantityScale(suffix string) (*big.Rat, error) {
+	if pow, ok := quantityBinarySuffixes[suffix]; ok {
+		return new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(pow))), nil
+	}
+	pow, ok := quantityDecimalSuffixes[suffix]
+	if !ok && len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E') {
+		n, err := strconv.Atoi(suffix[1:])
+		pow, ok = n, err == nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown suffix %q", suffix)
+	}
+	ten := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(pow))), nil)
+	if pow < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), ten), nil
+	}
+	return new(big.Rat).SetInt(ten), nil
+}
+
+// ceilRat rounds the given rat up to the next whole number.
+func ceilRat(r *big.Rat) int64 {
+	q, m := new(big.Int).DivMod(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q.Int64()
+}
+
+func absInt(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// SelectorOperator is the operator of a LabelRequirement
+type SelectorOperator string
+
+const (
+	SelectorEquals       SelectorOperator = "="
+	SelectorNotEquals    SelectorOperator = "!="
+	SelectorIn           SelectorOperator = "in"
+	SelectorNotIn        SelectorOperator = "notin"
+	SelectorExists       SelectorOperator = "exists"
+	SelectorDoesNotExist SelectorOperator = "!"
+)
+
+// LabelRequirement is a single requirement of a LabelSelector, a label key, an operator and the values it applies to.
+type LabelRequirement struct {
+	Key      string
+	Operator SelectorOperator
+	Values   []string
+}
+
+// Matches checks if the given labels meet the requirement.
+func (lr LabelRequirement) Matches(labels map[string]string) bool {
+	value, ok := labels[lr.Key]
+	switch lr.Operator {
+	case SelectorExists:
+		return ok
+	case SelectorDoesNotExist:
+		return !ok
+	case SelectorEquals, SelectorIn:
+		return ok && containsString(lr.Values, value)
+	case SelectorNotEquals, SelectorNotIn:
+		return !ok || !containsString(lr.Values, value)
+	}
+	return false
+}
+
+func (lr LabelRequirement) String() string {
+	switch lr.Operator {
+	case SelectorExists:
+		return lr.Key
+	case SelectorDoesNotExist:
+		return "!" + lr.Key
+	case SelectorIn, SelectorNotIn:
+		return fmt.Sprintf("%s %s (%s)", lr.Key, lr.Operator, strings.Join(lr.Values, ","))
+	}
+	return fmt.Sprintf("%s%s%s", lr.Key, lr.Operator, strings.Join(lr.Values, ","))
+}
+
+// LabelSelector is a kubernetes style label selector, a comma delimited list of requirements, which must all match.
+// e.g. 'app=web,tier!=cache,env in (prod,staging),!legacy'
+// Requirements may be equality based, 'key=value', 'key==value', 'key!=value',
+// set based, 'key in (a,b)', 'key notin (a,b)', or test a label exists, 'key', or does not exist, '!key'.
+type LabelSelector struct {
+	Requirements []LabelRequirement
+}
+
+// Matches checks if the given labels meet every requirement of the selector.  An empty selector matches everything.
+func (ls LabelSelector) Matches(labels map[string]string) bool {
+	for _, lr := range ls.Requirements {
+		if !lr.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ls LabelSelector) String() string {
+	reqs := make([]string, len(ls.Requirements))
+	for i, lr := range ls.Requirements {
+		reqs[i] = lr.String()
+	}
+	return strings.Join(reqs, ",")
+}
+
+func (ls LabelSelector) MarshalText() ([]byte, error) {
+	return []byte(ls.String()), nil
+}
+
+func (ls *LabelSelector) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	var reqs []LabelRequirement
+	for _, part := range splitSelector(s) {
+		lr, err := parseLabelRequirement(strings.TrimSpace(part))
+		if err != nil {
+			return fmt.Errorf("invalid label selector %q  %v", s, err)
+		}
+		reqs = append(reqs, lr)
+	}
+	ls.Requirements = reqs
+	return nil
+}
+
+// splitSelector splits the given selector on the commas which are not within a set of values.
+func splitSelector(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseLabelRequirement(s string) (LabelRequirement, error) {
+	if key, ok := strings.CutPrefix(s, "!"); ok {
+		key = strings.TrimSpace(key)
+		return LabelRequirement{Key: key, Operator: SelectorDoesNotExist}, checkLabelKey(key)
+	}
+	if i := strings.IndexAny(s, "!="); i >= 0 {
+		key := strings.TrimSpace(s[:i])
+		op, value := SelectorEquals, s[i+1:]
+		if s[i] == '!' {
+			if !strings.HasPrefix(value, "=") {
+				return LabelRequirement{}, fmt.Errorf("invalid requirement %q", s)
+			}
+			op = SelectorNotEquals
+		}
+		value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+		if err := checkLabelKey(key); err != nil {
+			return LabelRequirement{}, err
+		}
+		if err := checkLabelValue(value); err != nil {
+			return LabelRequirement{}, err
+		}
+		return LabelRequirement{Key: key, Operator: op, Values: []string{value}}, nil
+	}
+	key, rest, _ := strings.Cut(s, " ")
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return LabelRequirement{Key: key, Operator: SelectorExists}, checkLabelKey(key)
+	}
+	var op SelectorOperator
+	switch {
+	case strings.HasPrefix(rest, string(SelectorNotIn)):
+		op = SelectorNotIn
+	case strings.HasPrefix(rest, string(SelectorIn)):
+		op = SelectorIn
+	default:
+		return LabelRequirement{}, fmt.Errorf("invalid requirement %q", s)
+	}
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, string(op)))
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return LabelRequirement{}, fmt.Errorf("invalid requirement %q, expected values in brackets", s)
+	}
+	if err := checkLabelKey(key); err != nil {
+		return LabelRequirement{}, err
+	}
+	var values []string
+	for _, v := range strings.Split(rest[1:len(rest)-1], ",") {
+		v = strings.TrimSpace(v)
+		if err := checkLabelValue(v); err != nil {
+			return LabelRequirement{}, err
+		}
+		values = append(values, v)
+	}
+	return LabelRequirement{Key: key, Operator: op, Values: values}, nil
+}
+
+// checkLabelKey checks the given key is a valid label key, an optional dns prefix and '/', followed by a label name.
+func checkLabelKey(key string) error {
+	prefix, name, hasPrefix := strings.Cut(key, "/")
+	if !hasPrefix {
+		prefix, name = "", key
+	}
+	if hasPrefix && (prefix == "" || len(prefix) > 253 || strings.Trim(strings.ToLower(prefix), "abcdefghijklmnopqrstuvwxyz0123456789-.") != "") {
+		return fmt.Errorf("invalid label key %q, invalid prefix", key)
+	}
+	if name == "" || !isLabelName(name) {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	return nil
+}
+
+// checkLabelValue checks the given value is a valid label value, which may be empty.
+func checkLabelValue(value string) error {
+	if value != "" && !isLabelName(value) {
+		return fmt.Errorf("invalid label value %q", value)
+	}
+	return nil
+}
+
+// isLabelName checks the given name is up to 63 alphanumerics, '-', '_' or '.', beginning and ending with an alphanumeric.
+func isLabelName(s string) bool {
+	if len(s) > 63 {
+		return false
+	}
+	for i, r := range s {
+		alnum := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !alnum && ((i == 0 || i == len(s)-1) || !strings.ContainsRune("-_.", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
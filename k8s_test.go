@@ -0,0 +1,62 @@
+package argflags
+
+import "testing"
+
+func TestResourceQuantity(t *testing.T) {
+	tests := map[string]int64{
+		"500Mi": 500 << 20,
+		"2":     2,
+		"250m":  1,
+		"1.5G":  1500000000,
+		"1Ki":   1024,
+		"-1k":   -1000,
+	}
+	for s, expect := range tests {
+		var q ResourceQuantity
+		if err := q.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if q.Value() != expect || q.String() != s {
+			t.Errorf("%s  expected %d, got %d as %s", s, expect, q.Value(), q)
+		}
+	}
+	var q ResourceQuantity
+	if err := q.UnmarshalText([]byte("0.25")); err != nil || q.MilliValue() != 250 || q.Float64() != 0.25 {
+		t.Errorf("expected 250 thousandths, got %d, %v", q.MilliValue(), err)
+	}
+	for _, s := range []string{"", "Mi", "1/2", "1X", "1.2.3"} {
+		if err := q.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	var ls LabelSelector
+	if err := ls.UnmarshalText([]byte("app=web, tier!=cache,env in (prod,staging),!legacy,team")); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if len(ls.Requirements) != 5 || ls.String() != "app=web,tier!=cache,env in (prod,staging),!legacy,team" {
+		t.Errorf("expected five requirements, got %s", ls)
+	}
+	matching := map[string]string{"app": "web", "env": "prod", "team": "a"}
+	if !ls.Matches(matching) {
+		t.Errorf("expected %v to match", matching)
+	}
+	for _, labels := range []map[string]string{
+		{"app": "web", "env": "dev", "team": "a"},
+		{"app": "web", "env": "prod", "team": "a", "tier": "cache"},
+		{"app": "web", "env": "prod", "team": "a", "legacy": ""},
+		{"app": "web", "env": "prod"},
+	} {
+		if ls.Matches(labels) {
+			t.Errorf("expected %v not to match", labels)
+		}
+	}
+	for _, s := range []string{"app!web", "env in prod", "env within (a)", "-app=web", "app=we b"} {
+		if err := ls.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
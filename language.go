@@ -0,0 +1,48 @@
+package argflags
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// SupportedLanguages, when set, are the only languages a Language flag accepts.
+// A given language is matched against them, and the Language set to the closest supported language.
+// e.g. with English and French supported, 'en-GB' is set as 'en' and 'de' is an error.
+var SupportedLanguages []language.Tag
+
+// Language is a BCP 47 language tag, such as 'en', 'en-GB' or 'zh-Hant-TW'.
+// The given tag is canonicalized, so 'EN_gb' is set as 'en-GB'.
+type Language struct {
+	language.Tag
+}
+
+func (l Language) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *Language) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	tag, err := language.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid language %q  %v", s, err)
+	}
+	if len(SupportedLanguages) > 0 {
+		_, i, conf := language.NewMatcher(SupportedLanguages).Match(tag)
+		if conf == language.No {
+			return fmt.Errorf("unsupported language %q, expected one of %s", s, supportedLanguageNames())
+		}
+		tag = SupportedLanguages[i]
+	}
+	l.Tag = tag
+	return nil
+}
+
+func supportedLanguageNames() string {
+	names := make([]string, len(SupportedLanguages))
+	for i, tag := range SupportedLanguages {
+		names[i] = tag.String()
+	}
+	return strings.Join(names, ", ")
+}
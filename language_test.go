@@ -0,0 +1,43 @@
+package argflags
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestLanguage(t *testing.T) {
+	tests := map[string]string{
+		"en":         "en",
+		"EN-gb":      "en-GB",
+		"zh-hant-tw": "zh-Hant-TW",
+	}
+	for s, expect := range tests {
+		var l Language
+		if err := l.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if l.String() != expect {
+			t.Errorf("%s  expected %s, got %s", s, expect, l)
+		}
+	}
+	var l Language
+	if err := l.UnmarshalText([]byte("not a language")); err == nil {
+		t.Errorf("expected an invalid tag to be an error")
+	}
+}
+
+func TestSupportedLanguages(t *testing.T) {
+	defer func(supported []language.Tag) {
+		SupportedLanguages = supported
+	}(SupportedLanguages)
+	SupportedLanguages = []language.Tag{language.English, language.French}
+	var l Language
+	if err := l.UnmarshalText([]byte("en-GB")); err != nil || l.Tag != language.English {
+		t.Errorf("expected the closest supported language, got %v, %v", l, err)
+	}
+	if err := l.UnmarshalText([]byte("de")); err == nil {
+		t.Errorf("expected an unsupported language to be an error")
+	}
+}
@@ -0,0 +1,89 @@
+package argflags
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// MaxLenTagName is the tag limiting the length of a string flag, in characters, or the number of elements of a slice or map flag.
+// e.g. Name string `flag:"name" maxlen:"64"` or Hosts []string `flag:"host" maxlen:"16"`
+// Slices and maps are checked once all the values given are added, so repeated flags count towards the limit.
+const MaxLenTagName = "maxlen"
+
+// MinLenTagName is the tag giving the minimum length of a string flag, or the number of elements of a slice or map flag.
+// The minimum is only checked when the flag is given, a flag not given is not too short.
+const MinLenTagName = "minlen"
+
+// hasLengthLimit checks if the given field has a maxlen or minlen tag.
+func hasLengthLimit(f reflect.StructField) bool {
+	_, hasMax := f.Tag.Lookup(MaxLenTagName)
+	_, hasMin := f.Tag.Lookup(MinLenTagName)
+	return hasMax || hasMin
+}
+
+// checkLength checks the length of the given field is within the limits of its maxlen and minlen tags.
+func checkLength(f reflect.StructField, fld reflect.Value) error {
+	if !hasLengthLimit(f) {
+		return nil
+	}
+	min, max, err := lengthLimits(f)
+	if err != nil {
+		return err
+	}
+	n, ok := lengthOf(fld)
+	if !ok {
+		return fmt.Errorf("%s has no length to limit", fld.Type().String())
+	}
+	unit := "elements"
+	if reflect.Indirect(fld).Kind() == reflect.String {
+		unit = "characters"
+	}
+	if max >= 0 && n > max {
+		return fmt.Errorf("has %d %s, more than the maximum of %d", n, unit, max)
+	}
+	if n < min {
+		return fmt.Errorf("has %d %s, fewer than the minimum of %d", n, unit, min)
+	}
+	return nil
+}
+
+// lengthLimits gets the minimum and maximum lengths of the given field, from its tags.
+// The minimum is zero and the maximum is -1, when not limited.
+func lengthLimits(f reflect.StructField) (min, max int, err error) {
+	max = -1
+	if tag, ok := f.Tag.Lookup(MaxLenTagName); ok {
+		if max, err = parseLength(MaxLenTagName, tag); err != nil {
+			return 0, 0, err
+		}
+	}
+	if tag, ok := f.Tag.Lookup(MinLenTagName); ok {
+		if min, err = parseLength(MinLenTagName, tag); err != nil {
+			return 0, 0, err
+		}
+	}
+	return min, max, nil
+}
+
+// parseLength parses the value of the given length tag, a whole number from zero.
+func parseLength(name, tag string) (int, error) {
+	n, err := strconv.Atoi(tag)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s tag %q, expected a length from 0", name, tag)
+	}
+	return n, nil
+}
+
+// lengthOf gets the length of the given string, in characters, or the number of elements in the given slice or map.
+// returns false if the field has no length.
+func lengthOf(fld reflect.Value) (int, bool) {
+	fld = reflect.Indirect(fld)
+	switch fld.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(fld.String()), true
+	case reflect.Slice, reflect.Map:
+		return fld.Len(), true
+	}
+	return 0, false
+}
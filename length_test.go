@@ -0,0 +1,53 @@
+package argflags
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type lengthFlags struct {
+	Name   string            `flag:"name" maxlen:"5"`
+	Tags   []string          `flag:"tag" minlen:"2" maxlen:"3"`
+	Labels map[string]string `flag:"label" maxlen:"1"`
+	Note   *string           `flag:"note" minlen:"1"`
+}
+
+func TestLengthLimits(t *testing.T) {
+	var lf lengthFlags
+	if _, err := NewParser().Apply([]string{"-name", "abcde", "-tag", "a,b", "-tag", "c", "-label", "k=v", "-note", "x"}, &lf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if lf.Name != "abcde" || !reflect.DeepEqual(lf.Tags, []string{"a", "b", "c"}) || len(lf.Labels) != 1 || *lf.Note != "x" {
+		t.Errorf("unexpected flags %+v", lf)
+	}
+	tests := map[string][]string{
+		"name":  {"-name", "toolong"},
+		"tag":   {"-tag", "a"},
+		"tags":  {"-tag", "a,b,c,d"},
+		"label": {"-label", "a=1,b=2"},
+		"note":  {"-note", ""},
+	}
+	for name, args := range tests {
+		var lf lengthFlags
+		if _, err := NewParser().Apply(args, &lf); err == nil || !strings.Contains(err.Error(), "-"+strings.TrimSuffix(name, "s")) {
+			t.Errorf("%s  expected an error naming the flag, got %v", name, err)
+		}
+	}
+}
+
+func TestValidateLengthLimits(t *testing.T) {
+	tests := map[string]interface{}{
+		"is not a string, slice or map": &struct {
+			N int `maxlen:"1"`
+		}{},
+		"maxlen": &struct {
+			S string `maxlen:"x"`
+		}{},
+	}
+	for expect, str := range tests {
+		if err := Validate(str); err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("%T  expected an error containing %q, got %v", str, expect, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package argflags
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteManifests writes the env tagged fields of the given struct pointer as kubernetes manifests,
+// so a service configured with argflags can be deployed with its options in its environment.
+// The non secret fields are written as a ConfigMap, and the secret fields as an Opaque Secret, both with the given name,
+// separated by a '---' line.  Each is keyed by the environment variable names, as given by EnvFrom,
+// so a container may take them all with envFrom, using a configMapRef and a secretRef to the name.
+// The Secret is only written when there are secret fields to write.
+func WriteManifests(w io.Writer, name string, str interface{}) error {
+	return NewParser().WriteManifests(w, name, str)
+}
+
+// WriteManifests writes the env tagged fields of the given struct pointer as kubernetes manifests,
+// with the env prefix and delimiter of the parser.  See WriteManifests.
+func (p *Parser) WriteManifests(w io.Writer, name string, str interface{}) error {
+	if !isResourceName(name) {
+		return fmt.Errorf("%q is not a valid manifest name, expected lower case alphanumerics, '-' or '.'", name)
+	}
+	evs, err := p.envValues(str)
+	if err != nil {
+		return err
+	}
+	var data, secrets []string
+	for _, ev := range evs {
+		if ev.secret {
+			secrets = append(secrets, fmt.Sprintf("  %s: %s", ev.name, base64.StdEncoding.EncodeToString([]byte(ev.value))))
+		} else {
+			data = append(data, fmt.Sprintf("  %s: %s", ev.name, strconv.Quote(ev.value)))
+		}
+	}
+	buf := &strings.Builder{}
+	writeManifest(buf, "ConfigMap", name, "", data)
+	if len(secrets) > 0 {
+		buf.WriteString("---\n")
+		writeManifest(buf, "Secret", name, "Opaque", secrets)
+	}
+	_, err = io.WriteString(w, buf.String())
+	return err
+}
+
+// writeManifest writes a manifest of the given kind, with its data lines, already indented.
+func writeManifest(buf *strings.Builder, kind, name, typ string, data []string) {
+	fmt.Fprintf(buf, "apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n", kind, name)
+	if typ != "" {
+		fmt.Fprintf(buf, "type: %s\n", typ)
+	}
+	if len(data) == 0 {
+		buf.WriteString("data: {}\n")
+		return
+	}
+	buf.WriteString("data:\n")
+	for _, line := range data {
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+}
+
+// isResourceName checks the given name is a kubernetes resource name, up to 253 lower case alphanumerics, '-' or '.',
+// beginning and ending with an alphanumeric.
+func isResourceName(s string) bool {
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for i, r := range s {
+		alnum := (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+		if !alnum && ((i == 0 || i == len(s)-1) || !strings.ContainsRune("-.", r)) {
+			return false
+		}
+	}
+	return true
+}
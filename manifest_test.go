@@ -0,0 +1,36 @@
+package argflags
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+type deployFlags struct {
+	Host     string `flag:"host" env:"HOST"`
+	Port     int    `flag:"port" env:"PORT"`
+	Password string `flag:"password,secret" env:"PASSWORD"`
+	Debug    bool   `flag:"debug"`
+}
+
+func TestWriteManifests(t *testing.T) {
+	buf := &bytes.Buffer{}
+	df := deployFlags{Host: "example.com", Port: 80, Password: "s3cret", Debug: true}
+	if err := NewParser(WithEnvPrefix("APP_")).WriteManifests(buf, "myapp", &df); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	s := buf.String()
+	for _, expect := range []string{"kind: ConfigMap", "name: myapp", "APP_HOST:", "example.com", "kind: Secret",
+		"APP_PASSWORD: " + base64.StdEncoding.EncodeToString([]byte("s3cret"))} {
+		if !strings.Contains(s, expect) {
+			t.Errorf("expected %q in\n%s", expect, s)
+		}
+	}
+	if strings.Contains(s, "s3cret") || strings.Contains(s, "DEBUG") {
+		t.Errorf("expected no plain secret, or fields without an env tag, in\n%s", s)
+	}
+	if err := WriteManifests(buf, "My App", &df); err == nil {
+		t.Errorf("expected an error for an invalid name")
+	}
+}
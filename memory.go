@@ -0,0 +1,127 @@
+package argflags
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MemoryProber detects the memory available to the process, in bytes, for MemoryLimit values of 'auto'.
+// By default, it reads the cgroup memory limit, of cgroup v2 or v1, falling back to the total memory of the host.
+// It may be replaced, e.g. to read a limit from an orchestrator, or to give a fixed value in tests.
+var MemoryProber = probeMemory
+
+// cgroupMemoryLimits are the files holding the memory limit of the process cgroup, for cgroup v2 and v1.
+var cgroupMemoryLimits = []string{
+	"/sys/fs/cgroup/memory.max",
+	"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+}
+
+// MemoryLimit is a limit on the memory used, given as a byte size, e.g. '512MiB' or '2G',
+// or 'auto', meaning the memory available to the process, as detected by the MemoryProber,
+// or a percentage of the available memory, e.g. '75%'.
+// Detected values are resolved when the flag is parsed, so Bytes always holds the limit in bytes.
+type MemoryLimit struct {
+	// Bytes is the limit, in bytes
+	Bytes int64
+	// percent is the percentage of the detected memory, 100 for 'auto', or zero when given as a size.
+	percent int
+}
+
+// IsAuto checks if the limit was detected, with 'auto' or a percentage, rather than given as a size.
+func (ml MemoryLimit) IsAuto() bool {
+	return ml.percent > 0
+}
+
+func (ml MemoryLimit) String() string {
+	switch ml.percent {
+	case 0:
+		return Quantity[Bytes]{Value: float64(ml.Bytes)}.String()
+	case 100:
+		return "auto"
+	}
+	return strconv.Itoa(ml.percent) + "%"
+}
+
+func (ml MemoryLimit) MarshalText() ([]byte, error) {
+	return []byte(ml.String()), nil
+}
+
+func (ml *MemoryLimit) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	percent := 0
+	if strings.EqualFold(s, "auto") {
+		percent = 100
+	} else if pc, ok := strings.CutSuffix(s, "%"); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(pc))
+		if err != nil || n < 1 || n > 100 {
+			return fmt.Errorf("invalid memory limit %q, expected a percentage from 1 to 100", s)
+		}
+		percent = n
+	}
+	if percent > 0 {
+		available, err := MemoryProber()
+		if err != nil {
+			return fmt.Errorf("memory limit %q  failed to detect the available memory  %v", s, err)
+		}
+		*ml = MemoryLimit{Bytes: available * int64(percent) / 100, percent: percent}
+		return nil
+	}
+	var q Quantity[Bytes]
+	if err := q.UnmarshalText([]byte(s)); err != nil {
+		return fmt.Errorf("invalid memory limit %q, expected a size, a percentage or 'auto'", s)
+	}
+	if q.Value < 0 {
+		return fmt.Errorf("memory limit %q can not be negative", s)
+	}
+	*ml = MemoryLimit{Bytes: q.Int()}
+	return nil
+}
+
+// probeMemory gets the memory limit of the process cgroup, or if it has no limit, the total memory of the host.
+func probeMemory() (int64, error) {
+	host, err := hostMemory()
+	if err != nil {
+		return 0, err
+	}
+	for _, path := range cgroupMemoryLimits {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		// cgroup v2 has 'max' for no limit, v1 a very large number
+		limit, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err == nil && limit > 0 && limit < host {
+			return limit, nil
+		}
+		break
+	}
+	return host, nil
+}
+
+// hostMemory gets the total memory of the host, from /proc/meminfo
+func hostMemory() (int64, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "MemTotal:")
+		if !ok {
+			continue
+		}
+		kb, err := strconv.ParseInt(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "kB")), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid MemTotal in /proc/meminfo %q", value)
+		}
+		return kb * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("MemTotal not found in /proc/meminfo")
+}
@@ -0,0 +1,59 @@
+package argflags
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemoryLimit(t *testing.T) {
+	defer func(prober func() (int64, error)) {
+		MemoryProber = prober
+	}(MemoryProber)
+	MemoryProber = func() (int64, error) {
+		return 8 << 30, nil
+	}
+	tests := map[string]MemoryLimit{
+		"512MiB": {Bytes: 512 << 20},
+		"2G":     {Bytes: 2 << 30},
+		"1000":   {Bytes: 1000},
+		"auto":   {Bytes: 8 << 30, percent: 100},
+		"75%":    {Bytes: 6 << 30, percent: 75},
+	}
+	for s, expect := range tests {
+		var ml MemoryLimit
+		if err := ml.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if ml != expect {
+			t.Errorf("%s  expected %+v, got %+v", s, expect, ml)
+		}
+		var rt MemoryLimit
+		if err := rt.UnmarshalText([]byte(ml.String())); err != nil || rt != ml || ml.IsAuto() != (expect.percent > 0) {
+			t.Errorf("%s  expected %s to parse as the same limit, got %+v, %v", s, ml, rt, err)
+		}
+	}
+	for _, s := range []string{"", "0%", "101%", "-1G", "lots"} {
+		var ml MemoryLimit
+		if err := ml.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+	MemoryProber = func() (int64, error) {
+		return 0, errors.New("no cgroup")
+	}
+	var ml MemoryLimit
+	if err := ml.UnmarshalText([]byte("auto")); err == nil {
+		t.Errorf("expected a failed probe to be an error")
+	}
+}
+
+func TestProbeMemory(t *testing.T) {
+	n, err := probeMemory()
+	if err != nil {
+		t.Skipf("can not probe the memory  %v", err)
+	}
+	if n <= 0 {
+		t.Errorf("expected the available memory, got %d", n)
+	}
+}
@@ -0,0 +1,163 @@
+package argflags
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// currencyMinorUnits maps the active ISO 4217 currency codes to the number of decimal places of their minor unit.
+var currencyMinorUnits = map[string]int{
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2, "AUD": 2, "AWG": 2, "AZN": 2,
+	"BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2, "BHD": 3, "BIF": 0, "BMD": 2, "BND": 2, "BOB": 2, "BOV": 2,
+	"BRL": 2, "BSD": 2, "BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2, "CHE": 2, "CHF": 2,
+	"CHW": 2, "CLF": 4, "CLP": 0, "CNY": 2, "COP": 2, "COU": 2, "CRC": 2, "CUP": 2, "CVE": 2, "CZK": 2,
+	"DJF": 0, "DKK": 2, "DOP": 2, "DZD": 2, "EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2, "FJD": 2, "FKP": 2,
+	"GBP": 2, "GEL": 2, "GHS": 2, "GIP": 2, "GMD": 2, "GNF": 0, "GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2,
+	"HTG": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "IQD": 3, "IRR": 2, "ISK": 0, "JMD": 2, "JOD": 3,
+	"JPY": 0, "KES": 2, "KGS": 2, "KHR": 2, "KMF": 0, "KPW": 2, "KRW": 0, "KWD": 3, "KYD": 2, "KZT": 2,
+	"LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2, "LSL": 2, "LYD": 3, "MAD": 2, "MDL": 2, "MGA": 2, "MKD": 2,
+	"MMK": 2, "MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2, "MVR": 2, "MWK": 2, "MXN": 2, "MXV": 2, "MYR": 2,
+	"MZN": 2, "NAD": 2, "NGN": 2, "NIO": 2, "NOK": 2, "NPR": 2, "NZD": 2, "OMR": 3, "PAB": 2, "PEN": 2,
+	"PGK": 2, "PHP": 2, "PKR": 2, "PLN": 2, "PYG": 0, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2, "RWF": 0,
+	"SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2, "SLE": 2, "SOS": 2, "SRD": 2,
+	"SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2, "THB": 2, "TJS": 2, "TMT": 2, "TND": 3, "TOP": 2,
+	"TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2, "UAH": 2, "UGX": 0, "USD": 2, "USN": 2, "UYI": 0, "UYU": 2,
+	"UYW": 4, "UZS": 2, "VED": 2, "VES": 2, "VND": 0, "VUV": 0, "WST": 2, "XAF": 0, "XCD": 2, "XOF": 0,
+	"XPF": 0, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWG": 2,
+}
+
+// Decimal is an exact decimal number, held as text, so it never suffers the rounding of a float.
+// It accepts an optional sign, followed by digits with an optional decimal point, e.g. '-12.50'.
+// Trailing zeros in the fraction are preserved, as they may be significant. The zero value is zero.
+type Decimal struct {
+	s string
+}
+
+// ParseDecimal parses the given string as a Decimal.
+func ParseDecimal(s string) (Decimal, error) {
+	var d Decimal
+	err := d.UnmarshalText([]byte(s))
+	return d, err
+}
+
+// Rat gets the decimal as a big.Rat, for exact arithmetic.
+func (d Decimal) Rat() *big.Rat {
+	r, _ := new(big.Rat).SetString(d.String())
+	return r
+}
+
+// Scale gets the number of digits following the decimal point.
+func (d Decimal) Scale() int {
+	_, fraction, _ := strings.Cut(d.s, ".")
+	return len(fraction)
+}
+
+// IsZero checks if the decimal is zero.
+func (d Decimal) IsZero() bool {
+	return strings.Trim(d.String(), "-0.") == ""
+}
+
+func (d Decimal) String() string {
+	if d.s == "" {
+		return "0"
+	}
+	return d.s
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	sign := ""
+	number := s
+	switch {
+	case strings.HasPrefix(number, "-"):
+		sign, number = "-", number[1:]
+	case strings.HasPrefix(number, "+"):
+		number = number[1:]
+	}
+	whole, fraction, hasPoint := strings.Cut(number, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return fmt.Errorf("invalid decimal %q", s)
+	}
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+	number = whole
+	if hasPoint && fraction != "" {
+		number = strings.Join([]string{whole, fraction}, ".")
+	}
+	if strings.Trim(number, "0.") == "" {
+		sign = ""
+	}
+	d.s = sign + number
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Money is an exact amount in an ISO 4217 currency.
+// It accepts the amount and currency code in either order, with or without a space, e.g. '12.50 EUR', 'USD 3' or '1000JPY'.
+// The currency code must be a known ISO 4217 code, and the amount may not have more decimal places than the currency minor unit.
+type Money struct {
+	Amount   Decimal
+	Currency string
+}
+
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Amount.String()
+	}
+	return strings.Join([]string{m.Amount.String(), m.Currency}, " ")
+}
+
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	var amount, currency string
+	switch {
+	case len(s) > 3 && isLetters(s[:3]):
+		currency, amount = s[:3], s[3:]
+	case len(s) > 3 && isLetters(s[len(s)-3:]):
+		amount, currency = s[:len(s)-3], s[len(s)-3:]
+	default:
+		return fmt.Errorf("invalid money %q, expected an amount and currency code, e.g. '12.50 EUR'", s)
+	}
+	currency = strings.ToUpper(currency)
+	minor, ok := currencyMinorUnits[currency]
+	if !ok {
+		return fmt.Errorf("invalid money %q, unknown currency code %q", s, currency)
+	}
+	d, err := ParseDecimal(amount)
+	if err != nil {
+		return fmt.Errorf("invalid money %q, %v", s, err)
+	}
+	if d.Scale() > minor {
+		return fmt.Errorf("invalid money %q, %s has %d decimal places", s, currency, minor)
+	}
+	*m = Money{Amount: d, Currency: currency}
+	return nil
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
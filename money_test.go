@@ -0,0 +1,60 @@
+package argflags
+
+import "testing"
+
+func TestDecimal(t *testing.T) {
+	tests := map[string]string{
+		"12.50": "12.50",
+		"+007":  "7",
+		"-0.0":  "0.0",
+		".5":    "0.5",
+		"-3.":   "-3",
+	}
+	for s, expect := range tests {
+		d, err := ParseDecimal(s)
+		if err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if d.String() != expect {
+			t.Errorf("%s  expected %s, got %s", s, expect, d)
+		}
+	}
+	for _, s := range []string{"", ".", "1e3", "1,000", "0x10", "--1"} {
+		if _, err := ParseDecimal(s); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+	d, _ := ParseDecimal("0.10")
+	if d.Scale() != 2 || d.Rat().FloatString(1) != "0.1" || d.IsZero() {
+		t.Errorf("expected the scale of the trailing zero to be kept, got %d", d.Scale())
+	}
+	if !(Decimal{}).IsZero() || (Decimal{}).String() != "0" {
+		t.Errorf("expected the zero value to be zero")
+	}
+}
+
+func TestMoney(t *testing.T) {
+	tests := map[string]Money{
+		"12.50 EUR": {Amount: Decimal{s: "12.50"}, Currency: "EUR"},
+		"USD 3":     {Amount: Decimal{s: "3"}, Currency: "USD"},
+		"1000jpy":   {Amount: Decimal{s: "1000"}, Currency: "JPY"},
+		"-1.5gbp":   {Amount: Decimal{s: "-1.5"}, Currency: "GBP"},
+	}
+	for s, expect := range tests {
+		var m Money
+		if err := m.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if m != expect {
+			t.Errorf("%s  expected %v, got %v", s, expect, m)
+		}
+	}
+	for _, s := range []string{"12.50", "12 XYZ", "1.5 JPY", "1.005 USD", "EUR"} {
+		var m Money
+		if err := m.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package argflags
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mount types
+const (
+	MountBind   = "bind"
+	MountVolume = "volume"
+	MountTmpfs  = "tmpfs"
+)
+
+// Mount is a volume or bind mount, for container and sandboxing tools, given in either of docker's forms.
+// The short form, as with -v, is 'source:target[:options]', where the options are a comma delimited list, such as 'ro'.
+// A source beginning with '/' or '.' is a bind mount, otherwise it names a volume.  A target alone is an anonymous volume.
+// The long form, as with --mount, is a comma delimited list of key=value pairs,
+// e.g. 'type=bind,source=/data,target=/app/data,readonly'.  Keys other than type, source, target and readonly are kept in Options.
+// The target must always be an absolute path.
+// As the long form contains commas, which would split a []Mount, use Mounts to take a single mount from each flag.
+type Mount struct {
+	Type     string
+	Source   string
+	Target   string
+	ReadOnly bool
+	// Options are any other options of the mount, such as 'z' or 'volume-driver'. Options without a value have an empty value.
+	Options map[string]string
+}
+
+// mountKeys are the alternative names of the keys of a long form mount, and the name they are known by.
+var mountKeys = map[string]string{
+	"type":        "type",
+	"source":      "source",
+	"src":         "source",
+	"target":      "target",
+	"destination": "target",
+	"dst":         "target",
+	"readonly":    "readonly",
+	"ro":          "readonly",
+}
+
+// String gets the mount in its long form.
+func (m Mount) String() string {
+	if m.Target == "" {
+		return ""
+	}
+	parts := []string{"type=" + m.Type}
+	if m.Source != "" {
+		parts = append(parts, "source="+m.Source)
+	}
+	parts = append(parts, "target="+m.Target)
+	if m.ReadOnly {
+		parts = append(parts, "readonly")
+	}
+	keys := make([]string, 0, len(m.Options))
+	for k := range m.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if m.Options[k] == "" {
+			parts = append(parts, k)
+			continue
+		}
+		parts = append(parts, strings.Join([]string{k, m.Options[k]}, "="))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (m Mount) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Mount) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	parse := parseShortMount
+	if strings.Contains(strings.SplitN(s, ",", 2)[0], "=") {
+		parse = parseLongMount
+	}
+	v, err := parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid mount %q  %v", s, err)
+	}
+	if !path.IsAbs(v.Target) {
+		return fmt.Errorf("invalid mount %q, the target must be an absolute path", s)
+	}
+	*m = v
+	return nil
+}
+
+// Mounts are volume and bind mounts, each flag value being a single Mount, so the commas of its long form are part of the mount.
+// A mounts flag given more than once accumulates each mount.
+// e.g. -mount type=bind,source=/data,target=/app/data -mount cache:/var/cache:ro
+type Mounts []Mount
+
+// String gets the mounts in their long form, space delimited.
+func (ms Mounts) String() string {
+	mounts := make([]string, len(ms))
+	for i, m := range ms {
+		mounts[i] = m.String()
+	}
+	return strings.Join(mounts, " ")
+}
+
+func (ms Mounts) MarshalText() ([]byte, error) {
+	return []byte(ms.String()), nil
+}
+
+func (ms *Mounts) UnmarshalText(text []byte) error {
+	*ms = nil
+	return ms.AddText(text)
+}
+
+// AddText adds the given mount to the existing mounts.
+func (ms *Mounts) AddText(text []byte) error {
+	var m Mount
+	if err := m.UnmarshalText(text); err != nil {
+		return err
+	}
+	*ms = append(*ms, m)
+	return nil
+}
+
+// MarshalArgs gets a flag for each mount, in its long form, with the first of the given names.
+func (ms Mounts) MarshalArgs(names []string) ([]string, error) {
+	args := make([]string, len(ms))
+	for i, m := range ms {
+		args[i] = fmt.Sprintf("-%s=%s", names[0], m.String())
+	}
+	return args, nil
+}
+
+// parseShortMount parses the 'source:target[:options]' form of a mount.
+func parseShortMount(s string) (Mount, error) {
+	parts := strings.Split(s, ":")
+	m := Mount{Type: MountVolume}
+	switch len(parts) {
+	case 1:
+		m.Target = parts[0]
+	case 2, 3:
+		m.Source, m.Target = parts[0], parts[1]
+		if strings.HasPrefix(m.Source, "/") || strings.HasPrefix(m.Source, ".") {
+			m.Type = MountBind
+		}
+		if len(parts) == 3 {
+			for _, opt := range strings.Split(parts[2], ",") {
+				switch opt {
+				case "ro":
+					m.ReadOnly = true
+				case "rw":
+					m.ReadOnly = false
+				case "":
+					return Mount{}, fmt.Errorf("empty option")
+				default:
+					if m.Options == nil {
+						m.Options = map[string]string{}
+					}
+					m.Options[opt] = ""
+				}
+			}
+		}
+	default:
+		return Mount{}, fmt.Errorf("expected 'source:target[:options]'")
+	}
+	if m.Source == "" && len(parts) > 1 {
+		return Mount{}, fmt.Errorf("missing source")
+	}
+	return m, nil
+}
+
+// parseLongMount parses the 'key=value,...' form of a mount.
+func parseLongMount(s string) (Mount, error) {
+	m := Mount{Type: MountVolume}
+	for _, part := range strings.Split(s, ",") {
+		key, value, hasValue := strings.Cut(part, "=")
+		switch mountKeys[strings.ToLower(key)] {
+		case "type":
+			if value != MountBind && value != MountVolume && value != MountTmpfs {
+				return Mount{}, fmt.Errorf("unknown type %q, expected %s, %s or %s", value, MountBind, MountVolume, MountTmpfs)
+			}
+			m.Type = value
+		case "source":
+			m.Source = value
+		case "target":
+			m.Target = value
+		case "readonly":
+			ro := true
+			if hasValue {
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return Mount{}, fmt.Errorf("invalid readonly value %q", value)
+				}
+				ro = b
+			}
+			m.ReadOnly = ro
+		default:
+			if key == "" {
+				return Mount{}, fmt.Errorf("empty option")
+			}
+			if m.Options == nil {
+				m.Options = map[string]string{}
+			}
+			m.Options[key] = value
+		}
+	}
+	switch {
+	case m.Type == MountBind && m.Source == "":
+		return Mount{}, fmt.Errorf("bind mounts require a source")
+	case m.Type == MountTmpfs && m.Source != "":
+		return Mount{}, fmt.Errorf("tmpfs mounts can not have a source")
+	}
+	return m, nil
+}
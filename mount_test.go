@@ -0,0 +1,44 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+type mountFlags struct {
+	Mounts Mounts `flag:"mount,v"`
+}
+
+func TestMountsTakeWholeValues(t *testing.T) {
+	var mf mountFlags
+	args := []string{"-mount", "type=bind,source=/a,target=/b,readonly", "-v", "cache:/var/cache:ro,z"}
+	if _, err := NewParser().Apply(args, &mf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := Mounts{
+		{Type: MountBind, Source: "/a", Target: "/b", ReadOnly: true},
+		{Type: MountVolume, Source: "cache", Target: "/var/cache", ReadOnly: true, Options: map[string]string{"z": ""}},
+	}
+	if !reflect.DeepEqual(mf.Mounts, expect) {
+		t.Errorf("expected %+v, got %+v", expect, mf.Mounts)
+	}
+
+	out, err := ToArgs(&mf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	var rt mountFlags
+	if _, err := out.ApplyTo(&rt); err != nil {
+		t.Fatalf("unexpected error applying %v  %v", out, err)
+	}
+	if !reflect.DeepEqual(rt.Mounts, expect) {
+		t.Errorf("expected %+v, got %+v from %v", expect, rt.Mounts, out)
+	}
+}
+
+func TestMountTargetMustBeAbsolute(t *testing.T) {
+	var mf mountFlags
+	if _, err := NewParser().Apply([]string{"-mount", "type=volume,target=data"}, &mf); err == nil {
+		t.Errorf("expected an error for a relative target")
+	}
+}
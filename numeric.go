@@ -0,0 +1,335 @@
+package argflags
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Numeric tag options, for the integer flags of process control tools, such as umasks, file modes and resource limits.
+// optOctal parses the value in octal, with or without a leading '0' or '0o', e.g. '755', '0755' and '0o755' are all 493.
+// optUmask is octal, from 0 to 0777, e.g. Umask uint32 `flag:"umask,umask"`
+// optMode is octal, from 0 to 07777, as file permissions with the setuid, setgid and sticky bits.
+// optUlimit is a resource limit, from 0, or 'unlimited', which sets -1, or the maximum of an unsigned field.
+// The options apply to integer fields, pointers to them, and to each element of integer slices.
+const (
+	optOctal  = "octal"
+	optUmask  = "umask"
+	optMode   = "mode"
+	optUlimit = "ulimit"
+)
+
+// RangeTagName is the tag limiting a numeric flag to a range of values, given as 'min..max', inclusive.
+// Either bound may be omitted, e.g. `range:"1..100"`, `range:"0.."` or `range:"..-1"`
+// The bounds of fields with an octal option are also octal.
+const RangeTagName = "range"
+
+// MinTagName and MaxTagName limit a numeric flag to a minimum and maximum, inclusive, each setting one bound of its range.
+// e.g. Workers int `flag:"workers" min:"1" max:"64"` is the same as `range:"1..64"`
+const (
+	MinTagName = "min"
+	MaxTagName = "max"
+)
+
+// numericRanges are the range of each numeric option with its own range.
+var numericRanges = map[string]string{
+	optUmask:  "0..777",
+	optMode:   "0..7777",
+	optUlimit: "0..",
+}
+
+// unlimitedValues are the values a ulimit flag may be given for no limit.
+var unlimitedValues = []string{"unlimited", "infinity", "inf"}
+
+// setTagged sets the given value into the given field, with the set function,
+// applying the numeric options, transforms, range, length limits and pattern of its tags.
+func (p *Parser) setTagged(value string, f reflect.StructField, fld reflect.Value, set func(string, reflect.Value) error) error {
+	value = p.redelimit(f, value)
+	numeric := isIntegerType(f.Type) || hasNumericRange(f)
+	_, hasPattern := f.Tag.Lookup(PatternTagName)
+	if !numeric && !hasLengthLimit(f) && !hasTransform(f) && !hasPattern {
+		return set(value, fld)
+	}
+	if numeric {
+		v, err := p.numericValue(f, value)
+		if err != nil {
+			return err
+		}
+		value = v
+	}
+	ranged := len(p.ranges(f)) > 0
+	if !ranged && !hasLengthLimit(f) && !hasTransform(f) && !hasPattern {
+		return set(value, fld)
+	}
+	// a value failing its checks is not left in the field, which is set back to its value before it was set
+	prev := cloneValue(fld)
+	// a partially set slice still has the elements which were set checked
+	err := set(value, fld)
+	if err != nil && !errors.As(err, &ErrPartial{}) {
+		return err
+	}
+	if cerr := p.checkTagged(f, fld, ranged); cerr != nil {
+		fld.Set(prev)
+		return cerr
+	}
+	return err
+}
+
+// checkTagged applies the transforms of the given field, then checks its range, length limits and pattern.
+func (p *Parser) checkTagged(f reflect.StructField, fld reflect.Value, ranged bool) error {
+	if hasTransform(f) {
+		if err := applyTransforms(f, fld); err != nil {
+			return err
+		}
+	}
+	if ranged {
+		if err := p.checkRange(f, fld); err != nil {
+			return err
+		}
+	}
+	if err := checkLength(f, fld); err != nil {
+		return err
+	}
+	return checkPattern(f, fld)
+}
+
+// numericValue converts the given value, of an integer field with a numeric option, into the decimal form setValue parses.
+// Slice values have each of their delimited values converted.
+func (p *Parser) numericValue(f reflect.StructField, value string) (string, error) {
+	octal := p.isOctal(f)
+	unlimited := p.hasTagOption(f, optUlimit)
+	if !octal && !unlimited {
+		return value, nil
+	}
+	t := elemType(f.Type)
+	values := []string{value}
+	if f.Type.Kind() == reflect.Slice {
+		values = splitDelimited(value, p.delimiter)
+	}
+	for i, v := range values {
+		v = strings.TrimSpace(v)
+		switch {
+		case unlimited && containsString(unlimitedValues, strings.ToLower(v)):
+			values[i] = unlimitedValue(t)
+		case octal:
+			n, err := parseOctal(v)
+			if err != nil {
+				return "", err
+			}
+			values[i] = n.String()
+		}
+	}
+	return joinDelimited(values, p.delimiter), nil
+}
+
+// isOctal checks if the given field is tagged with any of the options parsing its value in octal.
+func (p *Parser) isOctal(f reflect.StructField) bool {
+	return p.hasTagOption(f, optOctal) || p.hasTagOption(f, optUmask) || p.hasTagOption(f, optMode)
+}
+
+// checkRange checks the value of the given field, or each element of a slice field, is within the range of its tags.
+func (p *Parser) checkRange(f reflect.StructField, fld reflect.Value) error {
+	octal := p.isOctal(f)
+	ulimit := p.hasTagOption(f, optUlimit)
+	ranges := p.ranges(f)
+	fld = reflect.Indirect(fld)
+	values := []reflect.Value{fld}
+	if fld.Kind() == reflect.Slice {
+		values = nil
+		for i := 0; i < fld.Len(); i++ {
+			values = append(values, reflect.Indirect(fld.Index(i)))
+		}
+	}
+	for _, v := range values {
+		if ulimit && formatNumber(v, 10) == unlimitedValue(v.Type()) {
+			continue
+		}
+		for _, rng := range ranges {
+			if err := checkInRange(v, rng, octal); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ranges gets the ranges, as 'min..max', of the numeric options and range tag of the given field.
+func (p *Parser) ranges(f reflect.StructField) []string {
+	var ranges []string
+	for opt, rng := range numericRanges {
+		if p.hasTagOption(f, opt) {
+			ranges = append(ranges, rng)
+		}
+	}
+	return append(ranges, tagRanges(f)...)
+}
+
+// tagRanges gets the ranges, as 'min..max', of the range, min and max tags of the given field.
+func tagRanges(f reflect.StructField) []string {
+	var ranges []string
+	if rng, ok := f.Tag.Lookup(RangeTagName); ok {
+		ranges = append(ranges, rng)
+	}
+	minimum, hasMin := f.Tag.Lookup(MinTagName)
+	maximum, hasMax := f.Tag.Lookup(MaxTagName)
+	if hasMin || hasMax {
+		ranges = append(ranges, minimum+".."+maximum)
+	}
+	return ranges
+}
+
+// checkInRange checks the given numeric value is within the given 'min..max' range.
+func checkInRange(v reflect.Value, rng string, octal bool) error {
+	n, ok := ratOf(v)
+	if !ok {
+		return fmt.Errorf("%s range tag %q can only be used on numeric fields", RangeTagName, rng)
+	}
+	base := 10
+	if octal {
+		base = 8
+	}
+	bounds, err := parseRange(rng, base)
+	if err != nil {
+		return err
+	}
+	if (bounds[0] != nil && n.Cmp(bounds[0]) < 0) || (bounds[1] != nil && n.Cmp(bounds[1]) > 0) {
+		minimum, maximum, _ := strings.Cut(rng, "..")
+		return fmt.Errorf("%s is out of range, expected %s", formatNumber(v, base), describeRange(minimum, maximum, base))
+	}
+	return nil
+}
+
+// parseRange parses the minimum and maximum of the given 'min..max' range, in the given base.
+// Either bound is nil when it is not given.
+func parseRange(rng string, base int) ([2]*big.Rat, error) {
+	var bounds [2]*big.Rat
+	minimum, maximum, ok := strings.Cut(rng, "..")
+	if !ok {
+		return bounds, fmt.Errorf("invalid %s tag %q, expected 'min..max'", RangeTagName, rng)
+	}
+	for i, bound := range []string{minimum, maximum} {
+		if bound == "" {
+			continue
+		}
+		b, err := parseBound(bound, base)
+		if err != nil {
+			return bounds, fmt.Errorf("invalid %s tag %q  %v", RangeTagName, rng, err)
+		}
+		bounds[i] = b
+	}
+	if bounds[0] != nil && bounds[1] != nil && bounds[0].Cmp(bounds[1]) > 0 {
+		return bounds, fmt.Errorf("invalid %s tag %q, min is greater than max", RangeTagName, rng)
+	}
+	return bounds, nil
+}
+
+// describeRange describes the given bounds of a range, e.g. 'from 0 to 0777' or 'at least 1'.
+func describeRange(minimum, maximum string, base int) string {
+	if base == 8 {
+		if minimum != "" {
+			minimum = "0" + strings.TrimLeft(minimum, "0")
+		}
+		if maximum != "" {
+			maximum = "0" + strings.TrimLeft(maximum, "0")
+		}
+	}
+	switch {
+	case minimum == "":
+		return fmt.Sprintf("at most %s", maximum)
+	case maximum == "":
+		return fmt.Sprintf("at least %s", minimum)
+	}
+	return fmt.Sprintf("from %s to %s", minimum, maximum)
+}
+
+func parseBound(s string, base int) (*big.Rat, error) {
+	if base == 8 {
+		n, err := parseOctal(s)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Rat).SetInt(n), nil
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a number", s)
+	}
+	return r, nil
+}
+
+// parseOctal parses the given octal number, with or without a leading '0' or '0o'.
+func parseOctal(s string) (*big.Int, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O")
+	n, ok := new(big.Int).SetString(digits, 8)
+	if !ok || digits == "" || strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		return nil, fmt.Errorf("invalid octal number %q", s)
+	}
+	return n, nil
+}
+
+// ratOf gets the given numeric value as an exact rat, or false if the value is not a number.
+func ratOf(v reflect.Value) (*big.Rat, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		return new(big.Rat).SetInt64(v.Int()), true
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return new(big.Rat).SetUint64(v.Uint()), true
+	case reflect.Float64, reflect.Float32:
+		r := new(big.Rat)
+		if r.SetFloat64(v.Float()) == nil {
+			return nil, false
+		}
+		return r, true
+	}
+	return nil, false
+}
+
+// formatNumber formats the given numeric value, in the given base for integers.  Octal numbers have a leading '0'.
+func formatNumber(v reflect.Value, base int) string {
+	prefix := ""
+	if base == 8 {
+		prefix = "0"
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		return prefix + strconv.FormatInt(v.Int(), base)
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return prefix + strconv.FormatUint(v.Uint(), base)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// unlimitedValue gets the value set for an unlimited ulimit field of the given type, -1 for signed integers, or the maximum of unsigned.
+func unlimitedValue(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return strconv.FormatUint(1<<t.Bits()-1, 10)
+	}
+	return "-1"
+}
+
+// isIntegerType checks if the given type is an integer, a pointer to one, or a slice of them.
+func isIntegerType(t reflect.Type) bool {
+	switch elemType(t).Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8,
+		reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return true
+	}
+	return false
+}
+
+// hasNumericRange checks if the given field has a range, min or max tag.
+func hasNumericRange(f reflect.StructField) bool {
+	return len(tagRanges(f)) > 0
+}
+
+// elemType gets the type of the given type, dereferencing pointers and slices, so the element of a []*int is int.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,150 @@
+package argflags
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type taggedFlags struct {
+	Names   []string `flag:"name" pattern:"^[a-z]+$"`
+	Ports   []int    `flag:"port" range:"1..65535"`
+	Workers int      `flag:"workers" range:"1..64"`
+	Hosts   []string `flag:"host" maxlen:"2"`
+	Umask   uint32   `flag:"umask,umask"`
+}
+
+func TestRejectedValuesNotSet(t *testing.T) {
+	tests := []struct {
+		args   []string
+		expect taggedFlags
+	}{
+		{args: []string{"-name", "ok", "-name", "ok,BAD"}, expect: taggedFlags{Names: []string{"ok"}}},
+		{args: []string{"-port", "80", "-port", "443,0"}, expect: taggedFlags{Ports: []int{80}}},
+		{args: []string{"-workers", "8", "-workers", "100"}, expect: taggedFlags{Workers: 8}},
+		{args: []string{"-host", "a,b", "-host", "c"}, expect: taggedFlags{Hosts: []string{"a", "b"}}},
+		{args: []string{"-umask", "022", "-umask", "1777"}, expect: taggedFlags{Umask: 022}},
+	}
+	for _, test := range tests {
+		var tf taggedFlags
+		if _, err := NewParser().Apply(test.args, &tf); err == nil {
+			t.Errorf("%v  expected an error", test.args)
+		}
+		if !reflect.DeepEqual(tf, test.expect) {
+			t.Errorf("%v  expected %+v, got %+v", test.args, test.expect, tf)
+		}
+	}
+}
+
+func TestRangeTag(t *testing.T) {
+	var tf taggedFlags
+	if _, err := NewParser().Apply([]string{"-workers", "64", "-port", "1,65535"}, &tf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if tf.Workers != 64 || !reflect.DeepEqual(tf.Ports, []int{1, 65535}) {
+		t.Errorf("unexpected flags %+v", tf)
+	}
+}
+
+type boundedFlags struct {
+	Workers int     `flag:"workers" min:"1" max:"64"`
+	Ratio   float64 `flag:"ratio" max:"1"`
+	Retries []int   `flag:"retry" min:"0"`
+	Mode    int     `flag:"mode,mode" max:"0777"`
+}
+
+func TestMinMaxTags(t *testing.T) {
+	var bf boundedFlags
+	if _, err := NewParser().Apply([]string{"-workers", "64", "-ratio", "-2.5", "-retry", "0,9", "-mode", "0755"}, &bf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if bf.Workers != 64 || bf.Ratio != -2.5 || !reflect.DeepEqual(bf.Retries, []int{0, 9}) || bf.Mode != 0755 {
+		t.Errorf("unexpected flags %+v", bf)
+	}
+	tests := map[string][]string{
+		"expected from 1 to 64": {"-workers", "0"},
+		"expected at most 1":    {"-ratio", "1.5"},
+		"expected at least 0":   {"-retry", "1,-1"},
+		"expected at most 0777": {"-mode", "1000"},
+	}
+	for expect, args := range tests {
+		var bf boundedFlags
+		_, err := NewParser().Apply(args, &bf)
+		if err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("%v  expected an error containing %q, got %v", args, expect, err)
+		}
+	}
+}
+
+func TestValidateMinMaxTags(t *testing.T) {
+	if err := Validate(&boundedFlags{}); err != nil {
+		t.Errorf("unexpected error  %v", err)
+	}
+	tests := map[string]interface{}{
+		"invalid min tag": &struct {
+			N int `min:"x"`
+		}{},
+		"invalid max tag": &struct {
+			N int `max:"1.2.3"`
+		}{},
+		"min tag greater than its max": &struct {
+			N int `min:"10" max:"1"`
+		}{},
+		"min is greater than max": &struct {
+			N int `range:"10..1"`
+		}{},
+		"but is not a number": &struct {
+			S string `min:"1"`
+		}{},
+	}
+	for expect, str := range tests {
+		if err := Validate(str); err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("%T  expected an error containing %q, got %v", str, expect, err)
+		}
+	}
+}
+
+type processFlags struct {
+	Umask  uint32   `flag:"umask,umask"`
+	Mode   int      `flag:"mode,mode"`
+	Octal  []int    `flag:"octal,octal"`
+	Files  int64    `flag:"files,ulimit"`
+	Stack  uint64   `flag:"stack,ulimit"`
+	Nice   *int     `flag:"nice" range:"-20..19"`
+	Limits []uint16 `flag:"limit,ulimit" range:"..1000"`
+}
+
+func TestNumericOptions(t *testing.T) {
+	var pf processFlags
+	args := []string{"-umask", "0o027", "-mode", "4755", "-octal", "10,010,0o10", "-files", "unlimited", "-stack", "Infinity", "-nice", "-5", "-limit", "10,inf"}
+	if _, err := NewParser().Apply(args, &pf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := processFlags{Umask: 027, Mode: 04755, Octal: []int{8, 8, 8}, Files: -1, Stack: 1<<64 - 1, Limits: []uint16{10, 1<<16 - 1}}
+	if pf.Nice == nil || *pf.Nice != -5 {
+		t.Errorf("expected nice -5, got %v", pf.Nice)
+	}
+	pf.Nice = nil
+	if !reflect.DeepEqual(pf, expect) {
+		t.Errorf("expected %+v, got %+v", expect, pf)
+	}
+}
+
+func TestNumericOptionErrors(t *testing.T) {
+	tests := map[string][]string{
+		"from 0 to 0777":              {"-umask", "1000"},
+		"from 0 to 07777":             {"-mode", "10000"},
+		"invalid octal":               {"-mode", "0789"},
+		"at least 0":                  {"-files", "-2"},
+		"at most 1000":                {"-limit", "1001"},
+		"from -20 to 19":              {"-nice", "20"},
+		"invalid octal number \"-1\"": {"-octal", "1,-1"},
+	}
+	for expect, args := range tests {
+		var pf processFlags
+		_, err := NewParser().Apply(args, &pf)
+		if err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("%v  expected an error containing %q, got %v", args, expect, err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package argflags
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Parallelism is a number of concurrent workers, defaulting to the number of CPUs.
+// It accepts a number of workers, e.g. '8', a percentage of the CPUs, e.g. '50%',
+// or '0' or 'auto', meaning the number of CPUs.  The zero value is 'auto'.
+// Neither may be more than MaxParallelism times the number of CPUs, e.g. '-j 1000000' is an error.
+type Parallelism struct {
+	workers int
+	percent int
+}
+
+// MaxParallelism is the most workers a Parallelism accepts, as a multiple of the number of CPUs.
+var MaxParallelism = 64
+
+// N gets the number of workers, which is always at least one.
+func (p Parallelism) N() int {
+	switch {
+	case p.percent > 0:
+		n := runtime.NumCPU() * p.percent / 100
+		if n < 1 {
+			n = 1
+		}
+		return n
+	case p.workers > 0:
+		return p.workers
+	default:
+		return runtime.NumCPU()
+	}
+}
+
+// IsAuto checks if the parallelism is the default, of the number of CPUs.
+func (p Parallelism) IsAuto() bool {
+	return p.percent == 0 && p.workers == 0
+}
+
+func (p Parallelism) String() string {
+	switch {
+	case p.percent > 0:
+		return strconv.Itoa(p.percent) + "%"
+	case p.workers > 0:
+		return strconv.Itoa(p.workers)
+	default:
+		return "auto"
+	}
+}
+
+func (p Parallelism) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Parallelism) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if strings.EqualFold(s, "auto") {
+		*p = Parallelism{}
+		return nil
+	}
+	if pc, ok := strings.CutSuffix(s, "%"); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(pc))
+		if err != nil {
+			return fmt.Errorf("invalid parallelism percentage %q", s)
+		}
+		if n < 1 {
+			return fmt.Errorf("parallelism percentage %q must be greater than zero", s)
+		}
+		if n > MaxParallelism*100 {
+			return fmt.Errorf("parallelism percentage %q is more than %d%%", s, MaxParallelism*100)
+		}
+		*p = Parallelism{percent: n}
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid parallelism %q, expected a number, percentage or 'auto'", s)
+	}
+	if n < 0 {
+		return fmt.Errorf("parallelism %q can not be negative", s)
+	}
+	if limit := MaxParallelism * runtime.NumCPU(); n > limit {
+		return fmt.Errorf("parallelism %q is more than %d, %d times the number of CPUs", s, limit, MaxParallelism)
+	}
+	*p = Parallelism{workers: n}
+	return nil
+}
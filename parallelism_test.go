@@ -0,0 +1,40 @@
+package argflags
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestParallelism(t *testing.T) {
+	tests := map[string]int{
+		"auto": runtime.NumCPU(),
+		"0":    runtime.NumCPU(),
+		"3":    3,
+		"100%": runtime.NumCPU(),
+		"200%": 2 * runtime.NumCPU(),
+	}
+	for s, expect := range tests {
+		var p Parallelism
+		if err := p.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if p.N() != expect {
+			t.Errorf("%s  expected %d, got %d", s, expect, p.N())
+		}
+	}
+}
+
+func TestParallelismLimit(t *testing.T) {
+	limit := MaxParallelism * runtime.NumCPU()
+	var p Parallelism
+	if err := p.UnmarshalText([]byte(strconv.Itoa(limit))); err != nil || p.N() != limit {
+		t.Errorf("expected %d to be accepted, got %v", limit, err)
+	}
+	for _, s := range []string{"1000000", strconv.Itoa(limit + 1), strconv.Itoa(MaxParallelism*100+1) + "%", "-1", "0%"} {
+		if err := p.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
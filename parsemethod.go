@@ -0,0 +1,52 @@
+package argflags
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ParserTagName is the tag naming a method, of the struct containing the field, which parses the flag value into the field.
+// e.g. Region string `flag:"region" parser:"ParseRegion"` calls func (o *Options) ParseRegion(s string) error
+// The method is given the value as given, each time the flag is given, and sets the field itself.
+// For one off conversions, which do not merit their own type, or a parser registered with RegisterParser.
+const ParserTagName = "parser"
+
+// parseMethod gets the set function calling the parser method of the given field, in the given root struct,
+// or nil if the field has no parser tag.
+func parseMethod(root reflect.Value, index []int) (func(string, reflect.Value) error, error) {
+	f := root.Type().FieldByIndex(index)
+	name, ok := f.Tag.Lookup(ParserTagName)
+	if !ok {
+		return nil, nil
+	}
+	parent := root
+	if len(index) > 1 {
+		parent = reflect.Indirect(root.FieldByIndex(index[:len(index)-1]))
+	}
+	if err := checkParseMethod(parent.Type(), f); err != nil {
+		return nil, fmt.Errorf("field %s %v", f.Name, err)
+	}
+	m := parent.Addr().MethodByName(name)
+	return func(value string, _ reflect.Value) error {
+		// the method may take a named string type
+		if err := m.Call([]reflect.Value{reflect.ValueOf(value).Convert(m.Type().In(0))})[0].Interface(); err != nil {
+			return err.(error)
+		}
+		return nil
+	}, nil
+}
+
+// checkParseMethod checks the method named by the parser tag, of the given field in the given struct type, exists.
+func checkParseMethod(t reflect.Type, f reflect.StructField) error {
+	name, ok := f.Tag.Lookup(ParserTagName)
+	if !ok {
+		return nil
+	}
+	m, ok := reflect.PtrTo(t).MethodByName(name)
+	if !ok || m.Type.NumIn() != 2 || m.Type.In(1).Kind() != reflect.String || m.Type.NumOut() != 1 || m.Type.Out(0) != errorType {
+		return fmt.Errorf("has a %s tag naming %s, which is not a method of %s, taking a string and returning an error", ParserTagName, name, t.String())
+	}
+	return nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
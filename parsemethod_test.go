@@ -0,0 +1,36 @@
+package argflags
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type regionFlags struct {
+	Region string `flag:"region" parser:"ParseRegion"`
+	Broken string `flag:"broken" parser:"Missing"`
+}
+
+func (rf *regionFlags) ParseRegion(s string) error {
+	if !strings.Contains(s, "-") {
+		return fmt.Errorf("invalid region %q", s)
+	}
+	rf.Region = strings.ToLower(s)
+	return nil
+}
+
+func TestParseMethod(t *testing.T) {
+	var rf regionFlags
+	if _, err := NewParser().Apply([]string{"-region", "EU-West-1"}, &rf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if rf.Region != "eu-west-1" {
+		t.Errorf("expected the parse method to set the region, got %q", rf.Region)
+	}
+	if _, err := NewParser().Apply([]string{"-region", "nowhere"}, &rf); err == nil {
+		t.Errorf("expected the parse method error")
+	}
+	if _, err := NewParser().Apply([]string{"-broken", "x"}, &rf); err == nil {
+		t.Errorf("expected an error for a missing parse method")
+	}
+}
@@ -0,0 +1,266 @@
+package argflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// parsers holds the registered parser of each type, keyed by its reflect.Type.
+var parsers sync.Map
+
+// RegisterParser registers the given function to parse flag values into fields of the type T.
+// Registered parsers take precedence over any other conversion, including the TextUnmarshaler of the type,
+// so types which are not owned by the application can be used as flags.
+// e.g. RegisterParser(func(s string) (decimal.Decimal, error) { return decimal.NewFromString(s) })
+// Registering a parser for a type already registered replaces the previous parser.
+func RegisterParser[T any](parse func(s string) (T, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	parsers.Store(t, func(s string) (reflect.Value, error) {
+		v, err := parse(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&v).Elem(), nil
+	})
+}
+
+// parserOf gets the registered parser for the given type, or nil if the type has no parser registered.
+func parserOf(t reflect.Type) func(s string) (reflect.Value, error) {
+	p, ok := parsers.Load(t)
+	if !ok {
+		return nil
+	}
+	return p.(func(s string) (reflect.Value, error))
+}
+
+// Parser applies argument flags to structs, with its own policy for how flags are named, matched and parsed.
+// ArgFlags.Apply and ApplyTo use a Parser with the default policy, and the package level settings, such as EnvPrefix.
+// A Parser is safe to use concurrently, and may be reused for any number of structs.
+type Parser struct {
+	tagName            string
+	delimiter          string
+	strict             bool
+	match              MatchPolicy
+	envPrefix          string
+	combinedShortFlags bool
+	configFile         string
+	partialSlices      bool
+	prefixMatching     bool
+	atomic             bool
+	warnings           io.Writer
+	help               io.Writer
+
+	observersMu sync.RWMutex
+	observers   []func(name, raw string, field reflect.Value)
+}
+
+// Option sets a policy of a Parser.
+type Option func(p *Parser)
+
+// NewParser creates a new Parser with the given options.
+// Without options, the Parser matches flags to the 'flag' tag, ignoring case, with comma delimited slices and maps,
+// returning unknown flags as unused, and takes the current EnvPrefix and CombinedShortFlags settings.
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{
+		tagName:            FlagTagName,
+		delimiter:          sliceDelimiter,
+		envPrefix:          EnvPrefix,
+		combinedShortFlags: CombinedShortFlags,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// WithTagName sets the name of the struct tag naming the flags of each field, in place of the 'flag' tag.
+func WithTagName(name string) Option {
+	return func(p *Parser) {
+		p.tagName = name
+	}
+}
+
+// WithDelimiter sets the delimiter between the values of slice and map flags, in place of a comma.
+func WithDelimiter(delimiter string) Option {
+	return func(p *Parser) {
+		p.delimiter = delimiter
+	}
+}
+
+// WithStrict sets if unknown flags are an error, rather than returned as unused.
+func WithStrict(strict bool) Option {
+	return func(p *Parser) {
+		p.strict = strict
+	}
+}
+
+// WithCaseSensitive sets if flag names must match the case of field names and tags, rather than ignoring case.
+// It is the same as WithMatchPolicy(MatchCase), or WithMatchPolicy(MatchIgnoreCase) when false.
+func WithCaseSensitive(caseSensitive bool) Option {
+	return func(p *Parser) {
+		p.match = MatchIgnoreCase
+		if caseSensitive {
+			p.match = MatchCase
+		}
+	}
+}
+
+// MatchPolicy is how a Parser matches flag names to fields.
+type MatchPolicy int
+
+const (
+	// MatchIgnoreCase matches flags to the field names and tag names, ignoring case, so '-n' and '-N' are the same flag.
+	// It is the default policy.
+	MatchIgnoreCase MatchPolicy = iota
+	// MatchCase matches flags to the field names and tag names, in the same case.
+	MatchCase
+	// MatchExactTag matches flags only to the names in the flag tags, in the same case.
+	// Fields without a name in their flag tag are matched by their field name, in the same case.
+	MatchExactTag
+)
+
+// WithMatchPolicy sets how flag names are matched to fields, see MatchPolicy.
+func WithMatchPolicy(policy MatchPolicy) Option {
+	return func(p *Parser) {
+		p.match = policy
+	}
+}
+
+// caseSensitive checks if the match policy of the parser matches the case of flag names.
+func (p *Parser) caseSensitive() bool {
+	return p.match != MatchIgnoreCase
+}
+
+// WithEnvPrefix sets the prefix of the environment variables named in env tags, in place of the EnvPrefix setting.
+func WithEnvPrefix(prefix string) Option {
+	return func(p *Parser) {
+		p.envPrefix = prefix
+	}
+}
+
+// WithCombinedShortFlags sets if single letter flags may be combined, in place of the CombinedShortFlags setting.
+func WithCombinedShortFlags(combined bool) Option {
+	return func(p *Parser) {
+		p.combinedShortFlags = combined
+	}
+}
+
+// WithPartialSlices sets if slice flags with elements which fail are set to the elements which did not fail,
+// rather than failing the whole flag. The elements which failed are reported in the Warnings of the Result.
+// For tools taking bulk input, which prefer to proceed with what they could parse.
+func WithPartialSlices(partial bool) Option {
+	return func(p *Parser) {
+		p.partialSlices = partial
+	}
+}
+
+// WithPrefixMatching sets if long flags may be abbreviated, as with getopt_long, rather than only matching whole flag names.
+// A flag, matching no flag name, matches the one flag it is the prefix of, e.g. '--time' matches '--timeout'.
+// A flag which is the prefix of more than one flag is an ErrAmbiguousFlag.
+// Long flags are those given with two dashes, or with one dash when short flags are not combined, of more than one letter.
+func WithPrefixMatching(prefixMatching bool) Option {
+	return func(p *Parser) {
+		p.prefixMatching = prefixMatching
+	}
+}
+
+// WithAtomic sets if the flags are applied to a copy of the struct, which is only set into the struct when every flag succeeds.
+// A failed Apply then leaves the struct as it was, rather than with the flags set which did not fail.
+// On success, the pointers, slices and maps of the struct are those of the copy, not those it had before.
+// The flags of a Command are applied as usual, a command at a time.
+func WithAtomic(atomic bool) Option {
+	return func(p *Parser) {
+		p.atomic = atomic
+	}
+}
+
+// OnSet registers the given function to be called for every field set, each time it is set, by a flag,
+// positional argument, environment variable, config file or default.
+// It is given the name the value was set by, as reported in the Result Fields, e.g. '-timeout' or '$MYAPP_HOST',
+// the raw value, with secret values masked, and the field, once set.
+// The values of a positional slice, or config array, are given together, joined by the delimiter.
+// Register observers before applying flags, they are called from within Apply, on the goroutine applying the flags.
+func (p *Parser) OnSet(fn func(name, raw string, field reflect.Value)) {
+	p.observersMu.Lock()
+	defer p.observersMu.Unlock()
+	p.observers = append(p.observers, fn)
+}
+
+// notifySet calls the registered OnSet functions with the given field, set by the given name to the given raw value.
+func (p *Parser) notifySet(name, raw string, field reflect.Value) {
+	p.observersMu.RLock()
+	observers := p.observers
+	p.observersMu.RUnlock()
+	for _, fn := range observers {
+		fn(name, raw, field)
+	}
+}
+
+// WithHelpOutput sets where help is written, when it is requested with -h or --help, in place of stdout.
+// Commands executed with a Request write their help to the Output of the request, which is returned in its Response.
+func WithHelpOutput(w io.Writer) Option {
+	return func(p *Parser) {
+		p.help = w
+	}
+}
+
+// helpOutput gets the writer requested help is written to, the Output of the context when it has one,
+// otherwise the help output of the parser, or stdout.
+func (p *Parser) helpOutput(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(outputKey{}).(io.Writer); ok {
+		return w
+	}
+	if p.help == nil {
+		return os.Stdout
+	}
+	return p.help
+}
+
+// Apply applies the given arguments to the given struct pointer, returning a Result reporting what was done to the struct.
+// See ArgFlags.ApplyTo for how the arguments are applied.
+// The Result is returned even when flags fail, with the errors of every failed flag, other than when help was requested.
+func (p *Parser) Apply(args []string, str interface{}) (*Result, error) {
+	v, err := getStructValue(str)
+	if err != nil {
+		return nil, err
+	}
+	target := *v
+	if p.atomic {
+		// apply to a shadow copy, committed to the struct only once every flag has succeeded
+		target = cloneValue(*v)
+	}
+	a := p.newApplier(applyTarget{value: target})
+	err = a.apply(args)
+	if errors.Is(err, ErrHelp) {
+		fmt.Fprint(p.helpOutput(context.Background()), p.Usage(str))
+		return nil, err
+	}
+	if p.atomic && err == nil {
+		v.Set(target)
+	}
+	return a.result, err
+}
+
+// ApplyTo applies the given arguments to the given struct pointer, returning the arguments which were not used.
+// Should any flags fail, the unused arguments are returned, along with the errors of every flag which failed.
+func (p *Parser) ApplyTo(args []string, str interface{}) ([]string, error) {
+	result, err := p.Apply(args, str)
+	if result == nil {
+		return nil, err
+	}
+	return result.Unused, err
+}
+
+// nameKey gets the key of the given flag name in a fieldIndex, folding its case unless the parser is case sensitive.
+func (p *Parser) nameKey(name string) string {
+	if p.caseSensitive() {
+		return name
+	}
+	return strings.ToLower(name)
+}
@@ -0,0 +1,49 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+type policyFlags struct {
+	ListenAddr string `flag:"listen"`
+	Name       string
+}
+
+func TestMatchPolicy(t *testing.T) {
+	tests := []struct {
+		policy MatchPolicy
+		args   []string
+		expect policyFlags
+	}{
+		{policy: MatchIgnoreCase, args: []string{"-LISTEN", "a", "-name", "n"}, expect: policyFlags{ListenAddr: "a", Name: "n"}},
+		{policy: MatchCase, args: []string{"-LISTEN", "a", "-Name", "n"}, expect: policyFlags{Name: "n"}},
+		{policy: MatchExactTag, args: []string{"-listenaddr", "a", "-listen", "b", "-Name", "n"}, expect: policyFlags{ListenAddr: "b", Name: "n"}},
+	}
+	for _, test := range tests {
+		var pf policyFlags
+		if _, err := NewParser(WithMatchPolicy(test.policy)).Apply(test.args, &pf); err != nil {
+			t.Errorf("%d  unexpected error  %v", test.policy, err)
+			continue
+		}
+		if !reflect.DeepEqual(pf, test.expect) {
+			t.Errorf("%d  expected %+v, got %+v", test.policy, test.expect, pf)
+		}
+	}
+}
+
+func TestParserOptions(t *testing.T) {
+	var v struct {
+		Tags []string `opt:"tag"`
+	}
+	p := NewParser(WithTagName("opt"), WithDelimiter(":"), WithStrict(true))
+	if _, err := p.Apply([]string{"-tag", "a:b,c"}, &v); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(v.Tags, []string{"a", "b,c"}) {
+		t.Errorf("expected the tags split on ':', got %q", v.Tags)
+	}
+	if _, err := p.Apply([]string{"-unknown"}, &v); err == nil {
+		t.Errorf("expected the strict parser to reject an unknown flag")
+	}
+}
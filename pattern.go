@@ -0,0 +1,62 @@
+package argflags
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+)
+
+// PatternTagName is the tag giving a regular expression which the whole value of a string flag must match.
+// e.g. Name string `flag:"name" pattern:"[a-z][a-z0-9-]*"`
+// It applies to string fields, pointers to strings and each element of string slices, after any transforms.
+const PatternTagName = "pattern"
+
+// patterns holds the compiled regular expression of each pattern tag, keyed by the pattern.
+var patterns sync.Map
+
+// compilePattern compiles the given pattern, anchored to match the whole value.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// checkPattern checks the string values of the given field match the pattern tag of the field, if it has one.
+func checkPattern(f reflect.StructField, fld reflect.Value) error {
+	pattern, ok := f.Tag.Lookup(PatternTagName)
+	if !ok {
+		return nil
+	}
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid %s tag  %v", PatternTagName, err)
+	}
+	fld = reflect.Indirect(fld)
+	if fld.Kind() == reflect.Slice {
+		for i := 0; i < fld.Len(); i++ {
+			if err := matchPattern(re, pattern, reflect.Indirect(fld.Index(i))); err != nil {
+				return ErrElement{Index: i, Err: err}
+			}
+		}
+		return nil
+	}
+	return matchPattern(re, pattern, fld)
+}
+
+// matchPattern checks the given string value matches the given compiled pattern.
+func matchPattern(re *regexp.Regexp, pattern string, v reflect.Value) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("%s can not be matched to a pattern, only strings", v.Type().String())
+	}
+	if !re.MatchString(v.String()) {
+		return fmt.Errorf("%q does not match the pattern %s", v.String(), pattern)
+	}
+	return nil
+}
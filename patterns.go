@@ -0,0 +1,116 @@
+package argflags
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Pattern is a single include or exclude rule of Patterns.
+// As text, it is given in the form of an rsync filter rule, '+ glob' to include, or '- glob' to exclude.
+// A glob without either is an include.
+type Pattern struct {
+	Exclude bool
+	Glob    string
+}
+
+// Match checks if the pattern matches the given slash separated path.
+// A glob without a '/' is matched against the last element of the path, a glob with one, against the whole path.
+func (pt Pattern) Match(name string) bool {
+	name = strings.TrimSuffix(name, "/")
+	glob := strings.TrimPrefix(pt.Glob, "/")
+	if !strings.Contains(glob, "/") {
+		name = path.Base(name)
+	} else {
+		name = strings.TrimPrefix(name, "/")
+	}
+	ok, _ := path.Match(glob, name)
+	return ok
+}
+
+func (pt Pattern) String() string {
+	if pt.Exclude {
+		return "- " + pt.Glob
+	}
+	return "+ " + pt.Glob
+}
+
+func (pt Pattern) MarshalText() ([]byte, error) {
+	return []byte(pt.String()), nil
+}
+
+func (pt *Pattern) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	v := Pattern{Glob: s}
+	if rule, glob, ok := strings.Cut(s, " "); ok && (rule == "+" || rule == "-") {
+		v = Pattern{Exclude: rule == "-", Glob: strings.TrimSpace(glob)}
+	}
+	if _, err := path.Match(v.Glob, ""); err != nil || v.Glob == "" {
+		return fmt.Errorf("invalid pattern %q", s)
+	}
+	*pt = v
+	return nil
+}
+
+// Patterns is an ordered list of include and exclude patterns, with rsync's semantics, where the first pattern matching
+// a path decides if it is included or excluded, so the order the patterns are given in matters.
+// Patterns is set by two flags, an include and an exclude flag, e.g. Filter Patterns `flag:"include,exclude"`
+// Each is added in the order it is given, so '-include *.go -exclude *' includes only the go files.
+// The flag names ending in 'exclude' add exclude patterns, all others add include patterns.
+// From the environment or a default tag, Patterns are a comma delimited list of '+ glob' and '- glob' rules.
+type Patterns []Pattern
+
+// Included checks if the given slash separated path is included, by the first matching pattern.
+// Paths matching no pattern are included.
+func (ps Patterns) Included(name string) bool {
+	for _, pt := range ps {
+		if pt.Match(name) {
+			return !pt.Exclude
+		}
+	}
+	return true
+}
+
+func (ps Patterns) String() string {
+	rules := make([]string, len(ps))
+	for i, pt := range ps {
+		rules[i] = pt.String()
+	}
+	return strings.Join(rules, ",")
+}
+
+// SetFlag adds the given glob as an include or exclude pattern, by the name of the flag it was given with.
+func (ps *Patterns) SetFlag(name, value string) error {
+	pt := Pattern{Exclude: strings.HasSuffix(strings.ToLower(name), "exclude"), Glob: value}
+	if _, err := path.Match(pt.Glob, ""); err != nil || pt.Glob == "" {
+		return fmt.Errorf("invalid pattern %q", value)
+	}
+	*ps = append(*ps, pt)
+	return nil
+}
+
+// MarshalArgs gets a flag for each pattern, using the first of the given names ending in 'exclude' for the exclude patterns,
+// and the first of the others for the include patterns.
+func (ps Patterns) MarshalArgs(names []string) ([]string, error) {
+	var include, exclude string
+	for _, name := range names {
+		isExclude := strings.HasSuffix(strings.ToLower(name), "exclude")
+		if isExclude && exclude == "" {
+			exclude = name
+		} else if !isExclude && include == "" {
+			include = name
+		}
+	}
+	args := make([]string, len(ps))
+	for i, pt := range ps {
+		name := include
+		if pt.Exclude {
+			name = exclude
+		}
+		if name == "" {
+			return nil, fmt.Errorf("no flag to set pattern %q", pt.String())
+		}
+		args[i] = fmt.Sprintf("-%s=%s", name, pt.Glob)
+	}
+	return args, nil
+}
@@ -0,0 +1,61 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+type filterFlags struct {
+	Filter Patterns `flag:"include,exclude"`
+}
+
+func TestPatterns(t *testing.T) {
+	var ff filterFlags
+	if _, err := (ArgFlags{"-include", "*.go", "-exclude", "vendor/*", "-exclude", "*"}).ApplyTo(&ff); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := Patterns{{Glob: "*.go"}, {Exclude: true, Glob: "vendor/*"}, {Exclude: true, Glob: "*"}}
+	if !reflect.DeepEqual(ff.Filter, expect) {
+		t.Fatalf("expected %v, got %v", expect, ff.Filter)
+	}
+	for name, included := range map[string]bool{"main.go": true, "cmd/app/main.go": true, "README.md": false, "vendor/x": false} {
+		if ff.Filter.Included(name) != included {
+			t.Errorf("%s  expected included %v", name, included)
+		}
+	}
+	if (Patterns{}).Included("any") != true {
+		t.Errorf("expected paths matching no pattern to be included")
+	}
+}
+
+func TestPatternsOrder(t *testing.T) {
+	var ff filterFlags
+	if _, err := (ArgFlags{"-exclude", "*_test.go", "-include", "*.go", "-exclude", "*"}).ApplyTo(&ff); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if ff.Filter.Included("a_test.go") || !ff.Filter.Included("a.go") {
+		t.Errorf("expected the first matching pattern to decide, got %v", ff.Filter)
+	}
+	out, err := ToArgs(&ff)
+	if err != nil || !reflect.DeepEqual(out, ArgFlags{"-exclude=*_test.go", "-include=*.go", "-exclude=*"}) {
+		t.Errorf("expected each pattern, in order, got %v, %v", out, err)
+	}
+}
+
+func TestPatternText(t *testing.T) {
+	var ff struct {
+		Filter Patterns `flag:"include,exclude" default:"+ *.go,- *"`
+	}
+	if _, err := (ArgFlags{}).ApplyTo(&ff); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if ff.Filter.String() != "+ *.go,- *" {
+		t.Errorf("expected the rules of the default, got %v", ff.Filter)
+	}
+	var pt Pattern
+	for _, s := range []string{"", "[", "- ["} {
+		if err := pt.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
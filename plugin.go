@@ -0,0 +1,73 @@
+package argflags
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Plugins gets the names of the plugin commands found on the PATH, for commands with a PluginPrefix.
+// Names already used by sub commands are not included.
+func (c *Command) Plugins() []string {
+	if c.PluginPrefix == "" {
+		return nil
+	}
+	prefix := c.PluginPrefix + "-"
+	found := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+				continue
+			}
+			name := strings.TrimPrefix(e.Name(), prefix)
+			if c.Command(name) != nil || c.pluginPath(name) == "" {
+				continue
+			}
+			found[name] = true
+		}
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pluginPath gets the path of the executable for the given plugin command name, or empty if not found.
+func (c *Command) pluginPath(name string) string {
+	if c.PluginPrefix == "" || name == "" || strings.ContainsRune(name, filepath.Separator) {
+		return ""
+	}
+	path, err := exec.LookPath(strings.Join([]string{c.PluginPrefix, name}, "-"))
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// executePlugin runs the plugin command of the given name, passing it the given raw arguments.
+// The plugin is invoked as a sub command, wrapped in the middleware of this command and its parents.
+// masked are all the arguments of the invocation, from the root command, with secret values masked.
+func (c *Command) executePlugin(ctx context.Context, name string, args []string, masked []string) error {
+	path := c.pluginPath(name)
+	plugin := &Command{
+		Name:   name,
+		parent: c,
+		Handler: func(ctx context.Context, inv *Invocation) error {
+			cmd := exec.CommandContext(ctx, path, inv.Args...)
+			cmd.Stdin = os.Stdin
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			return cmd.Run()
+		},
+	}
+	return plugin.handler()(ctx, &Invocation{Command: plugin, Args: args, MaskedArgs: masked})
+}
@@ -0,0 +1,33 @@
+package argflags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlugins(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := "#!/bin/sh\necho \"$@\" > " + out + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "app-hello"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	var invs []*Invocation
+	root, _, _ := newServeCommand(&invs)
+	root.PluginPrefix = "app"
+	if names := root.Plugins(); !reflect.DeepEqual(names, []string{"hello"}) {
+		t.Errorf("expected the hello plugin, got %v", names)
+	}
+	if err := root.Execute(context.Background(), []string{"hello", "-x", "y"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || strings.TrimSpace(string(data)) != "-x y" {
+		t.Errorf("expected the plugin to be run with the raw arguments, got %q, %v", data, err)
+	}
+}
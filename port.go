@@ -0,0 +1,96 @@
+package argflags
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Port is a network port number, from 1 to 65535, or 0 meaning any available port, chosen when listening.
+type Port uint16
+
+// IsAuto checks if the port is 0, for any available port to be chosen.
+func (p Port) IsAuto() bool {
+	return p == 0
+}
+
+func (p Port) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p Port) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Port) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	n, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port %q, expected 0 to 65535", s)
+	}
+	*p = Port(n)
+	return nil
+}
+
+// PortRange is an inclusive range of ports, given as 'first-last', e.g. '8000-8100', or a single port, e.g. '8080'.
+// Ports in a range must be from 1 to 65535, and the first may not be greater than the last.
+// Slices of PortRange may mix ranges and single ports, e.g. '80,443,8000-8100'
+type PortRange struct {
+	First, Last Port
+}
+
+// Contains checks if the given port is within the range.
+func (pr PortRange) Contains(p Port) bool {
+	return p >= pr.First && p <= pr.Last
+}
+
+// Len gets the number of ports in the range.
+func (pr PortRange) Len() int {
+	if pr.Last < pr.First {
+		return 0
+	}
+	return int(pr.Last-pr.First) + 1
+}
+
+// Ports gets every port in the range, in order.
+func (pr PortRange) Ports() []Port {
+	ports := make([]Port, 0, pr.Len())
+	for p := int(pr.First); p <= int(pr.Last); p++ {
+		ports = append(ports, Port(p))
+	}
+	return ports
+}
+
+func (pr PortRange) String() string {
+	if pr.First == pr.Last {
+		return pr.First.String()
+	}
+	return fmt.Sprintf("%s-%s", pr.First, pr.Last)
+}
+
+func (pr PortRange) MarshalText() ([]byte, error) {
+	return []byte(pr.String()), nil
+}
+
+func (pr *PortRange) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	first, last, isRange := strings.Cut(s, "-")
+	if !isRange {
+		last = first
+	}
+	var v PortRange
+	if err := v.First.UnmarshalText([]byte(first)); err != nil {
+		return fmt.Errorf("invalid port range %q  %v", s, err)
+	}
+	if err := v.Last.UnmarshalText([]byte(last)); err != nil {
+		return fmt.Errorf("invalid port range %q  %v", s, err)
+	}
+	if v.First.IsAuto() || v.Last.IsAuto() {
+		return fmt.Errorf("invalid port range %q, ports must be from 1 to 65535", s)
+	}
+	if v.First > v.Last {
+		return fmt.Errorf("invalid port range %q, the first port is greater than the last", s)
+	}
+	*pr = v
+	return nil
+}
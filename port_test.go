@@ -0,0 +1,46 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPort(t *testing.T) {
+	var p Port
+	if err := p.UnmarshalText([]byte("8080")); err != nil || p != 8080 || p.IsAuto() {
+		t.Errorf("expected 8080, got %v, %v", p, err)
+	}
+	if err := p.UnmarshalText([]byte("0")); err != nil || !p.IsAuto() {
+		t.Errorf("expected 0 to be any available port, got %v, %v", p, err)
+	}
+	for _, s := range []string{"", "-1", "65536", "http"} {
+		if err := p.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestPortRanges(t *testing.T) {
+	var flags struct {
+		Ports []PortRange `flag:"ports"`
+	}
+	if _, err := (ArgFlags{"-ports", "80,443,8000-8002"}).ApplyTo(&flags); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := []PortRange{{80, 80}, {443, 443}, {8000, 8002}}
+	if !reflect.DeepEqual(flags.Ports, expect) {
+		t.Fatalf("expected %v, got %v", expect, flags.Ports)
+	}
+	pr := flags.Ports[2]
+	if pr.Len() != 3 || !reflect.DeepEqual(pr.Ports(), []Port{8000, 8001, 8002}) || !pr.Contains(8001) || pr.Contains(8003) {
+		t.Errorf("expected the three ports of %v", pr)
+	}
+	if pr.String() != "8000-8002" || flags.Ports[0].String() != "80" {
+		t.Errorf("expected the ranges in their parsable form, got %v", flags.Ports)
+	}
+	for _, s := range []string{"0-10", "10-5", "1-", "a-b", "1-70000"} {
+		if err := pr.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package argflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ArgTagName is the tag binding a field to a positional argument, the non flag arguments, counted from zero.
+// e.g. Source string `arg:"0"` is set to the first non flag argument.
+// A slice field takes all the remaining arguments from its position, each one an element of the slice.
+// e.g. Files []string `arg:"1"` is set to the second and all following non flag arguments.
+// Positional fields are not flags, unless they also have a flag tag.
+// Arguments following a '--' terminator are all positional, even those beginning with a dash.
+// Arguments bound to a positional field are no longer returned as unused.
+const ArgTagName = "arg"
+
+// positionalField is a field tagged with the position of the argument it is bound to.
+type positionalField struct {
+	position int
+	index    int
+	field    reflect.StructField
+}
+
+// bindPositional binds the non flag arguments, not used by a flag, to the positional fields of the first target.
+func (a *applier) bindPositional() error {
+	if len(a.targets) == 0 {
+		return nil
+	}
+	target := a.targets[0]
+	fields, err := positionalFields(target.value.Type())
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	// argIndex are the indexes, in the unused arguments, of the non flag arguments
+	var argIndex []int
+	for i, arg := range a.result.Unused {
+		if !strings.HasPrefix(arg, "-") || a.isVerbatim(i) {
+			argIndex = append(argIndex, i)
+		}
+	}
+	bound := map[int]bool{}
+	for _, pf := range fields {
+		if pf.position >= len(argIndex) {
+			continue
+		}
+		fld := target.value.Field(pf.index)
+		positions := argIndex[pf.position : pf.position+1]
+		if fld.Kind() == reflect.Slice && !isWholeValue(fld) {
+			positions = argIndex[pf.position:]
+		}
+		if err := a.p.bindArgs(a.result.Unused, positions, fld); err != nil {
+			var ee ErrElement
+			if errors.As(err, &ee) {
+				// name the argument which failed, of those bound to the slice
+				return fmt.Errorf("'%s'  %s%v", a.result.Unused[positions[ee.Index]], strings.ToLower(pf.field.Name), err)
+			}
+			return fmt.Errorf("'%s'  %v", a.result.Unused[positions[0]], err)
+		}
+		for _, i := range positions {
+			bound[i] = true
+		}
+		ff := &flagField{p: a.p, fldValue: fld, root: target.value, index: []int{pf.index}}
+		a.setApplied(strings.ToLower(pf.field.Name), ff)
+		var values []string
+		for _, i := range positions {
+			values = append(values, a.result.Unused[i])
+		}
+		a.setFrom(SourceArg, pf.field.Name, ff, values...)
+	}
+	var unused []string
+	for i, arg := range a.result.Unused {
+		if !bound[i] {
+			unused = append(unused, arg)
+		}
+	}
+	a.result.Unused = unused
+	return nil
+}
+
+// bindArgs sets the given field to the arguments at the given positions.
+// Multiple positions are only given for slice fields, which are set with an element for each argument.
+func (p *Parser) bindArgs(args []string, positions []int, fld reflect.Value) error {
+	if fld.Kind() != reflect.Slice || isWholeValue(fld) {
+		return p.setValue(args[positions[0]], fld)
+	}
+	inst := reflect.MakeSlice(fld.Type(), len(positions), len(positions))
+	for i, pos := range positions {
+		if err := p.setValue(args[pos], inst.Index(i)); err != nil {
+			return ErrElement{Index: i, Err: err}
+		}
+	}
+	fld.Set(inst)
+	return nil
+}
+
+// positionalFields gets the fields, directly in the given struct type, tagged with a position, in position order.
+func positionalFields(t reflect.Type) ([]positionalField, error) {
+	var fields []positionalField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup(ArgTagName)
+		if !ok || !f.IsExported() {
+			continue
+		}
+		pos, err := strconv.Atoi(tag)
+		if err != nil || pos < 0 {
+			return nil, fmt.Errorf("field %s in %s has an invalid %s tag %q, expected a position from 0", f.Name, t.String(), ArgTagName, tag)
+		}
+		fields = append(fields, positionalField{position: pos, index: i, field: f})
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].position < fields[j].position
+	})
+	return fields, nil
+}
+
+// isPositionalOnly checks if the given field is a positional field without a flag tag.
+func (p *Parser) isPositionalOnly(f reflect.StructField) bool {
+	_, isArg := f.Tag.Lookup(ArgTagName)
+	_, isFlag := f.Tag.Lookup(p.tagName)
+	return isArg && !isFlag
+}
@@ -0,0 +1,30 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+type copyFlags struct {
+	Force   bool     `flag:"f"`
+	Source  string   `arg:"0"`
+	Targets []string `arg:"1"`
+}
+
+func TestPositionalArgs(t *testing.T) {
+	var cf copyFlags
+	res, err := NewParser().Apply([]string{"src", "-f", "a", "--", "-b"}, &cf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := copyFlags{Force: true, Source: "src", Targets: []string{"a", "-b"}}
+	if !reflect.DeepEqual(cf, expect) {
+		t.Errorf("expected %+v, got %+v", expect, cf)
+	}
+	if len(res.Unused) != 0 {
+		t.Errorf("expected the positional args to be used, got %v", res.Unused)
+	}
+	if sf, _ := res.Lookup("Targets"); sf.Source != SourceArg || !res.IsSet("Source") {
+		t.Errorf("expected Targets set from the arguments, got %+v", sf)
+	}
+}
@@ -0,0 +1,47 @@
+package argflags
+
+import "strings"
+
+// isLongFlag checks if the given flag, as given, is a long flag, which may be abbreviated with prefix matching.
+func (a *applier) isLongFlag(flag string) bool {
+	name := strings.TrimLeft(flag, "-")
+	if len([]rune(name)) < 2 {
+		return false
+	}
+	return strings.HasPrefix(flag, "--") || !a.p.combinedShortFlags
+}
+
+// matchPrefix gets the flag name which the given flag is the prefix of, or empty if it is the prefix of none.
+// The flags of the current element of a grouped sub arg are matched before those of the targets, as with whole names.
+// returns an ErrAmbiguousFlag if the flag is the prefix of more than one flag.
+func (a *applier) matchPrefix(flag string) (string, error) {
+	prefix := a.p.nameKey(strings.TrimLeft(flag, "-"))
+	var targets []applyTarget
+	if a.group.isValid() {
+		targets = append(targets, applyTarget{value: a.group.value()})
+	}
+	for _, target := range append(targets, a.targets...) {
+		var matches []string
+		for _, fd := range a.p.describeFlags(target.value.Type()) {
+			if target.hidden[indexKey(fd.index)] {
+				continue
+			}
+			for _, name := range fd.names {
+				if strings.HasPrefix(a.p.nameKey(name), prefix) {
+					matches = append(matches, name)
+					break
+				}
+			}
+		}
+		switch {
+		case len(matches) == 1:
+			return matches[0], nil
+		case len(matches) > 1:
+			for i := range matches {
+				matches[i] = "-" + matches[i]
+			}
+			return "", ErrAmbiguousFlag{Name: flag, Matches: matches}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,58 @@
+package argflags
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+type prefixFlags struct {
+	Filter  Patterns `flag:"include,exclude"`
+	Color   string   `flag:"color"`
+	Shade   string   `flag:"shade" deprecated:"use -color"`
+	Verbose bool     `flag:"verbose"`
+	Version bool     `flag:"version"`
+}
+
+func TestPrefixMatchUsesFullName(t *testing.T) {
+	var pf prefixFlags
+	p := NewParser(WithPrefixMatching(true), WithWarningOutput(io.Discard))
+	res, err := p.Apply([]string{"--incl", "*.go", "--excl", "*"}, &pf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if len(pf.Filter) != 2 || pf.Filter[0].Exclude || !pf.Filter[1].Exclude {
+		t.Errorf("expected an include then an exclude pattern, got %v", pf.Filter)
+	}
+	if len(res.Occurrences) != 2 || res.Occurrences[0].Flag != "--include" || res.Occurrences[1].Flag != "--exclude" {
+		t.Errorf("expected occurrences of the full flag names, got %+v", res.Occurrences)
+	}
+}
+
+func TestPrefixMatchDeprecated(t *testing.T) {
+	var pf prefixFlags
+	p := NewParser(WithPrefixMatching(true), WithWarningOutput(io.Discard))
+	res, err := p.Apply([]string{"--sha", "red"}, &pf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if pf.Shade != "red" {
+		t.Errorf("expected red, got %q", pf.Color)
+	}
+	if len(res.Warnings) != 1 {
+		t.Fatalf("expected a deprecated warning, got %v", res.Warnings)
+	}
+	var de ErrDeprecated
+	if !errors.As(res.Warnings[0], &de) || de.Flag != "--shade" {
+		t.Errorf("expected --shade to be deprecated, got %v", res.Warnings[0])
+	}
+}
+
+func TestPrefixMatchAmbiguous(t *testing.T) {
+	var pf prefixFlags
+	_, err := NewParser(WithPrefixMatching(true)).Apply([]string{"--ver"}, &pf)
+	var ae ErrAmbiguousFlag
+	if !errors.As(err, &ae) || len(ae.Matches) != 2 {
+		t.Errorf("expected an ambiguous flag error, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package argflags
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Dimension is a kind of quantity, such as bytes or requests, and the units it may be given in.
+type Dimension struct {
+	// Name is the name of the dimension, used in errors.
+	Name string
+	// BaseUnit is the suffix of the base unit, which quantity values are held in. May be empty.
+	BaseUnit string
+	// Units are the units, other than the base unit, which values may be given in.
+	// When formatting a quantity, the largest unit giving a whole number is used,
+	// or if none do, the largest unit which is not greater than the value.
+	// Where more than one unit has the same multiple, the first is used.
+	Units []Unit
+}
+
+// Unit is a unit of measurement within a Dimension.
+type Unit struct {
+	// Suffix follows the number in a value given in this unit. e.g. 'KiB'
+	Suffix string
+	// Multiple is the number of base units in this unit.
+	Multiple float64
+}
+
+// DimensionOf is implemented by types which define the Dimension of a Quantity.
+// A new dimension is defined by declaring a type with a Dimension method, returning its unit table.
+// e.g.  type Requests struct{}
+//
+//	func (Requests) Dimension() Dimension {
+//		return Dimension{Name: "requests", BaseUnit: "req", Units: []Unit{{Suffix: "kreq", Multiple: 1000}}}
+//	}
+//
+// then fields of the type Quantity[Requests] accept values such as '500req' or '2.5kreq'
+type DimensionOf interface {
+	Dimension() Dimension
+}
+
+// Quantity is a number of units in the dimension D, parsed from a number with an optional unit suffix.
+// e.g. Quantity[Bytes] accepts values such as '512MiB', '1.5GB' or '100' (bytes).
+// Suffixes are matched exactly, or if no suffix matches exactly, ignoring case.
+type Quantity[D DimensionOf] struct {
+	// Value is the quantity in the base unit of the dimension.
+	Value float64
+}
+
+// In gets the quantity in the unit with the given suffix.
+func (q Quantity[D]) In(suffix string) (float64, error) {
+	dim := q.dimension()
+	unit, ok := dim.unit(suffix)
+	if !ok {
+		return 0, fmt.Errorf("unknown %s unit %q", dim.Name, suffix)
+	}
+	return q.Value / unit.Multiple, nil
+}
+
+// Int gets the quantity in the base unit, rounded to the nearest whole number.
+func (q Quantity[D]) Int() int64 {
+	return int64(math.Round(q.Value))
+}
+
+func (q Quantity[D]) String() string {
+	dim := q.dimension()
+	// prefer the largest unit giving a whole number, otherwise the largest unit not greater than the value.
+	best := Unit{Suffix: dim.BaseUnit, Multiple: 1}
+	whole := math.Trunc(q.Value) == q.Value
+	for _, u := range dim.Units {
+		if u.Multiple <= best.Multiple || u.Multiple > math.Abs(q.Value) {
+			continue
+		}
+		isWhole := math.Trunc(q.Value/u.Multiple) == q.Value/u.Multiple
+		if isWhole || !whole {
+			best = u
+			whole = isWhole
+		}
+	}
+	return strconv.FormatFloat(q.Value/best.Multiple, 'f', -1, 64) + best.Suffix
+}
+
+func (q Quantity[D]) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+func (q *Quantity[D]) UnmarshalText(text []byte) error {
+	dim := q.dimension()
+	s := strings.TrimSpace(string(text))
+	n := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+' || r == 'e' || r == 'E')
+	})
+	// an 'e' may begin a unit suffix rather than an exponent, so only accept the longest parsable number
+	number, suffix := s, ""
+	if n >= 0 {
+		number, suffix = s[:n], strings.TrimSpace(s[n:])
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	for err != nil && len(number) > 1 && strings.ContainsAny(number[len(number)-1:], "eE+-") {
+		suffix = number[len(number)-1:] + suffix
+		number = number[:len(number)-1]
+		value, err = strconv.ParseFloat(number, 64)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s quantity %q", dim.Name, s)
+	}
+	unit, ok := dim.unit(suffix)
+	if !ok {
+		return fmt.Errorf("invalid %s quantity %q, unknown unit %q", dim.Name, s, suffix)
+	}
+	q.Value = value * unit.Multiple
+	return nil
+}
+
+func (q Quantity[D]) dimension() Dimension {
+	var d D
+	return d.Dimension()
+}
+
+// unit gets the unit with the given suffix, matching exactly, or if no exact match, ignoring case.
+// An empty suffix is the base unit.
+func (d Dimension) unit(suffix string) (Unit, bool) {
+	if suffix == "" || suffix == d.BaseUnit {
+		return Unit{Suffix: d.BaseUnit, Multiple: 1}, true
+	}
+	for _, u := range d.Units {
+		if u.Suffix == suffix {
+			return u, true
+		}
+	}
+	if strings.EqualFold(suffix, d.BaseUnit) {
+		return Unit{Suffix: d.BaseUnit, Multiple: 1}, true
+	}
+	for _, u := range d.Units {
+		if strings.EqualFold(u.Suffix, suffix) {
+			return u, true
+		}
+	}
+	return Unit{}, false
+}
+
+// Bytes is the Dimension of data sizes, in bytes.
+// Decimal units (KB, MB, GB, TB, PB) are multiples of 1000, binary units (KiB, MiB, GiB, TiB, PiB) multiples of 1024.
+// The short forms K, M, G, T and P are binary units.
+type Bytes struct{}
+
+func (Bytes) Dimension() Dimension {
+	return Dimension{
+		Name:     "bytes",
+		BaseUnit: "B",
+		Units: []Unit{
+			{Suffix: "KiB", Multiple: 1 << 10},
+			{Suffix: "MiB", Multiple: 1 << 20},
+			{Suffix: "GiB", Multiple: 1 << 30},
+			{Suffix: "TiB", Multiple: 1 << 40},
+			{Suffix: "PiB", Multiple: 1 << 50},
+			{Suffix: "KB", Multiple: 1e3},
+			{Suffix: "MB", Multiple: 1e6},
+			{Suffix: "GB", Multiple: 1e9},
+			{Suffix: "TB", Multiple: 1e12},
+			{Suffix: "PB", Multiple: 1e15},
+			{Suffix: "K", Multiple: 1 << 10},
+			{Suffix: "M", Multiple: 1 << 20},
+			{Suffix: "G", Multiple: 1 << 30},
+			{Suffix: "T", Multiple: 1 << 40},
+			{Suffix: "P", Multiple: 1 << 50},
+		},
+	}
+}
+
+// Count is the Dimension of a number of items, with the SI suffixes k, M, G and T.
+type Count struct{}
+
+func (Count) Dimension() Dimension {
+	return Dimension{
+		Name: "count",
+		Units: []Unit{
+			{Suffix: "k", Multiple: 1e3},
+			{Suffix: "M", Multiple: 1e6},
+			{Suffix: "G", Multiple: 1e9},
+			{Suffix: "T", Multiple: 1e12},
+		},
+	}
+}
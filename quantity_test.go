@@ -0,0 +1,65 @@
+package argflags
+
+import "testing"
+
+type requests struct{}
+
+func (requests) Dimension() Dimension {
+	return Dimension{Name: "requests", BaseUnit: "req", Units: []Unit{{Suffix: "kreq", Multiple: 1000}}}
+}
+
+func TestQuantity(t *testing.T) {
+	tests := map[string]float64{
+		"100":    100,
+		"512MiB": 512 << 20,
+		"1.5GB":  1.5e9,
+		"2k":     2048,
+		"1kib":   1024,
+		"-1B":    -1,
+		"1e3":    1000,
+	}
+	for s, expect := range tests {
+		var q Quantity[Bytes]
+		if err := q.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if q.Value != expect {
+			t.Errorf("%s  expected %v, got %v", s, expect, q.Value)
+		}
+	}
+	for _, s := range []string{"", "MiB", "12 parsecs", "1.2.3"} {
+		var q Quantity[Bytes]
+		if err := q.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestQuantityUnits(t *testing.T) {
+	q := Quantity[Bytes]{Value: 1536 << 20}
+	if q.String() != "1536MiB" {
+		t.Errorf("expected the largest whole unit, got %s", q)
+	}
+	if gib, err := q.In("GiB"); err != nil || gib != 1.5 {
+		t.Errorf("expected 1.5GiB, got %v, %v", gib, err)
+	}
+	if _, err := q.In("parsecs"); err == nil {
+		t.Errorf("expected an unknown unit to be an error")
+	}
+	if s := (Quantity[Count]{Value: 3000}).String(); s != "3k" {
+		t.Errorf("expected 3k, got %s", s)
+	}
+}
+
+func TestQuantityDimension(t *testing.T) {
+	var flags struct {
+		Limit Quantity[requests] `flag:"limit"`
+	}
+	if _, err := NewParser().Apply([]string{"-limit", "2.5kreq"}, &flags); err != nil || flags.Limit.Int() != 2500 {
+		t.Errorf("expected 2500 requests, got %v, %v", flags.Limit.Value, err)
+	}
+	if _, err := NewParser().Apply([]string{"-limit", "2MiB"}, &flags); err == nil {
+		t.Errorf("expected units of another dimension to be an error")
+	}
+}
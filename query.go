@@ -0,0 +1,98 @@
+package argflags
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Query is a set of URL query parameters, given as 'key=value'.
+// Values are given as plain text, and encoded when the query is encoded, so '-q "name=Joe Bloggs"' encodes as 'name=Joe+Bloggs'.
+// A query flag given more than once accumulates each parameter, with repeated keys holding multiple values.
+// e.g. -q tag=a -q tag=b -q limit=10
+type Query url.Values
+
+// Values gets the query as url.Values
+func (q Query) Values() url.Values {
+	return url.Values(q)
+}
+
+// String gets the encoded query, in key order.
+func (q Query) String() string {
+	return url.Values(q).Encode()
+}
+
+func (q Query) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// MarshalArgs gets a flag for each parameter value, in key order, with the first of the given names.
+func (q Query) MarshalArgs(names []string) ([]string, error) {
+	keys := make([]string, 0, len(q))
+	for key := range q {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var args []string
+	for _, key := range keys {
+		for _, value := range q[key] {
+			args = append(args, fmt.Sprintf("-%s=%s=%s", names[0], key, value))
+		}
+	}
+	return args, nil
+}
+
+func (q *Query) UnmarshalText(text []byte) error {
+	*q = Query{}
+	return q.AddText(text)
+}
+
+// AddText adds the given 'key=value' parameter to the existing parameters.
+func (q *Query) AddText(text []byte) error {
+	s := string(text)
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("invalid query parameter %q, expected key=value", s)
+	}
+	if *q == nil {
+		*q = Query{}
+	}
+	url.Values(*q).Add(key, value)
+	return nil
+}
+
+// URL is an absolute URL, with a scheme and host, e.g. 'https://example.com/api?v=2'
+type URL struct {
+	url.URL
+}
+
+// WithQuery gets a copy of the URL with the given query parameters added to any it already has.
+func (u URL) WithQuery(q Query) *url.URL {
+	nu := u.URL
+	values := nu.Query()
+	for key, vals := range q {
+		for _, v := range vals {
+			values.Add(key, v)
+		}
+	}
+	nu.RawQuery = values.Encode()
+	return &nu
+}
+
+func (u URL) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *URL) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	pu, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if pu.Scheme == "" || pu.Host == "" {
+		return fmt.Errorf("invalid url %q, expected an absolute url, such as https://example.com", s)
+	}
+	u.URL = *pu
+	return nil
+}
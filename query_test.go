@@ -0,0 +1,46 @@
+package argflags
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	var flags struct {
+		Query Query `flag:"q"`
+		URL   URL   `flag:"url"`
+	}
+	args := []string{"-url", "https://example.com/api?v=2", "-q", "name=Joe Bloggs", "-q", "tag=a", "-q", "tag=b"}
+	if _, err := ArgFlags(args).ApplyTo(&flags); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := Query{"name": {"Joe Bloggs"}, "tag": {"a", "b"}}
+	if !reflect.DeepEqual(flags.Query, expect) || !reflect.DeepEqual(flags.Query.Values(), url.Values(expect)) {
+		t.Errorf("expected %v, got %v", expect, flags.Query)
+	}
+	if s := flags.Query.String(); s != "name=Joe+Bloggs&tag=a&tag=b" {
+		t.Errorf("expected the encoded query, got %s", s)
+	}
+	if u := flags.URL.WithQuery(flags.Query).String(); u != "https://example.com/api?name=Joe+Bloggs&tag=a&tag=b&v=2" {
+		t.Errorf("expected the query added to the url, got %s", u)
+	}
+	if flags.URL.RawQuery != "v=2" {
+		t.Errorf("expected the url not to be changed, got %s", flags.URL.String())
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	for _, s := range []string{"name", "=x"} {
+		var q Query
+		if err := q.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+	for _, s := range []string{"example.com", "/api", "https://"} {
+		var u URL
+		if err := u.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package argflags
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateMultipliers are the suffixes which may follow the count of a rate.
+var rateMultipliers = map[string]float64{
+	"k": 1e3,
+	"K": 1e3,
+	"M": 1e6,
+	"G": 1e9,
+}
+
+// rateIntervals are the interval units which are not supported by time.ParseDuration.
+var rateIntervals = map[string]time.Duration{
+	"d":   24 * time.Hour,
+	"sec": time.Second,
+	"min": time.Minute,
+}
+
+// Rate is a number of events per interval of time, for throttling.
+// It parses values such as '100/s', '5/m', '1.5k/s' or '20/10s'.
+// The count may have a 'k', 'M' or 'G' suffix, and the interval may be a unit of 's', 'm', 'h' or 'd',
+// or a duration, such as '10s' or '1h30m'.
+// PerSecond may be used to create a golang.org/x/time/rate Limiter: rate.NewLimiter(rate.Limit(r.PerSecond()), burst)
+type Rate struct {
+	// Count is the number of events in each Interval.
+	Count float64
+	// Interval is the time the Count of events occur in.
+	Interval time.Duration
+}
+
+// PerSecond gets the rate as the number of events per second.
+func (r Rate) PerSecond() float64 {
+	if r.Interval <= 0 {
+		return 0
+	}
+	return r.Count / r.Interval.Seconds()
+}
+
+// Every gets the time between each event, or zero if the rate has no events.
+func (r Rate) Every() time.Duration {
+	if r.Count <= 0 {
+		return 0
+	}
+	return time.Duration(float64(r.Interval) / r.Count)
+}
+
+// IsZero checks if the rate has no events.
+func (r Rate) IsZero() bool {
+	return r.Count == 0
+}
+
+func (r Rate) String() string {
+	count := strconv.FormatFloat(r.Count, 'f', -1, 64)
+	switch r.Interval {
+	case time.Second:
+		return count + "/s"
+	case time.Minute:
+		return count + "/m"
+	case time.Hour:
+		return count + "/h"
+	case 24 * time.Hour:
+		return count + "/d"
+	default:
+		return count + "/" + r.Interval.String()
+	}
+}
+
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	cs, is, ok := strings.Cut(s, "/")
+	if !ok {
+		return fmt.Errorf("invalid rate %q, expected a count per interval, e.g. 100/s", s)
+	}
+	count, err := parseRateCount(strings.TrimSpace(cs))
+	if err != nil {
+		return fmt.Errorf("invalid rate %q  %v", s, err)
+	}
+	interval, err := parseRateInterval(strings.TrimSpace(is))
+	if err != nil {
+		return fmt.Errorf("invalid rate %q  %v", s, err)
+	}
+	*r = Rate{Count: count, Interval: interval}
+	return nil
+}
+
+func parseRateCount(s string) (float64, error) {
+	multiplier := 1.0
+	if len(s) > 0 {
+		if m, ok := rateMultipliers[s[len(s)-1:]]; ok {
+			multiplier = m
+			s = s[:len(s)-1]
+		}
+	}
+	count, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid count")
+	}
+	if count < 0 {
+		return 0, fmt.Errorf("count can not be negative")
+	}
+	count *= multiplier
+	if math.IsNaN(count) || math.IsInf(count, 0) {
+		return 0, fmt.Errorf("count must be a finite number")
+	}
+	return count, nil
+}
+
+func parseRateInterval(s string) (time.Duration, error) {
+	if d, ok := rateIntervals[strings.ToLower(s)]; ok {
+		return d, nil
+	}
+	// a unit without a number is a single unit of time
+	if s != "" && (s[0] < '0' || s[0] > '9') {
+		s = "1" + s
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval")
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("interval must be greater than zero")
+	}
+	return d, nil
+}
@@ -0,0 +1,47 @@
+package argflags
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRate(t *testing.T) {
+	tests := map[string]Rate{
+		"100/s":   {Count: 100, Interval: time.Second},
+		"5/m":     {Count: 5, Interval: time.Minute},
+		"5/min":   {Count: 5, Interval: time.Minute},
+		"1.5k/s":  {Count: 1500, Interval: time.Second},
+		"20/10s":  {Count: 20, Interval: 10 * time.Second},
+		"2/d":     {Count: 2, Interval: 24 * time.Hour},
+		"1/1h30m": {Count: 1, Interval: 90 * time.Minute},
+	}
+	for s, expect := range tests {
+		var r Rate
+		if err := r.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if r != expect {
+			t.Errorf("%s  expected %+v, got %+v", s, expect, r)
+		}
+	}
+	for _, s := range []string{"100", "x/s", "-1/s", "1/0s", "1/y", "NaN/s", "Inf/s", "+Inf/m", "-Inf/s", "infinity/h", "1e308G/s"} {
+		var r Rate
+		if err := r.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestRateConversions(t *testing.T) {
+	r := Rate{Count: 20, Interval: 10 * time.Second}
+	if r.PerSecond() != 2 || r.Every() != 500*time.Millisecond {
+		t.Errorf("expected 2 per second, every 500ms, got %v, %v", r.PerSecond(), r.Every())
+	}
+	if r.String() != "20/10s" || (Rate{Count: 5, Interval: time.Minute}).String() != "5/m" {
+		t.Errorf("expected the rate in its parsable form, got %s", r)
+	}
+	if (Rate{}).PerSecond() != 0 || (Rate{}).Every() != 0 || !(Rate{}).IsZero() {
+		t.Errorf("expected a zero rate to have no events")
+	}
+}
@@ -0,0 +1,53 @@
+package argflags
+
+import "reflect"
+
+// optRequired marks a flag as required, so applying arguments without that flag fails.
+const optRequired = "required"
+
+// checkRequired checks every field tagged as required, in each of the targets, has been set.
+// Required fields within a nil sub arg, or a sub arg which has not been activated, are not required.
+// returns an error listing all the required flags which are missing.
+func (a *applier) checkRequired() error {
+	var missing []string
+	for _, target := range a.targets {
+		for _, fd := range a.p.describeFlags(target.value.Type()) {
+			if target.hidden[indexKey(fd.index)] || !a.p.hasTagOption(fd.field, optRequired) {
+				continue
+			}
+			fld, ok := a.p.fieldInUse(target.value, fd.index)
+			if !ok {
+				continue
+			}
+			key := keyOfField(fld)
+			if a.isApplied[key] || a.preset[key] || a.isFallback[key] || a.isFailed[key] {
+				continue
+			}
+			missing = append(missing, "-"+fd.names[0])
+		}
+	}
+	if len(missing) > 0 {
+		return ErrMissingRequired{Flags: missing}
+	}
+	return nil
+}
+
+// fieldInUse gets the field at the given index, if the sub args it is within are not nil, and are activated.
+func (p *Parser) fieldInUse(v reflect.Value, index []int) (reflect.Value, bool) {
+	fld := v
+	for i, fi := range index {
+		if activator, ok := fld.Type().Field(fi).Tag.Lookup(ActivationTagName); ok && i < len(index)-1 {
+			if active, err := p.isActivated(v, activator); err != nil || !active {
+				return reflect.Value{}, false
+			}
+		}
+		fld = fld.Field(fi)
+		if i < len(index)-1 && fld.Kind() == reflect.Ptr {
+			if fld.IsNil() {
+				return reflect.Value{}, false
+			}
+			fld = fld.Elem()
+		}
+	}
+	return fld, true
+}
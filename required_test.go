@@ -0,0 +1,28 @@
+package argflags
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type requiredFlags struct {
+	Host string `flag:"host,required"`
+	Port int    `flag:"port,required" default:"80"`
+	User string `flag:"user,required"`
+}
+
+func TestRequiredFlags(t *testing.T) {
+	var rf requiredFlags
+	_, err := NewParser().Apply([]string{"-host", "a"}, &rf)
+	var mr ErrMissingRequired
+	if !errors.As(err, &mr) {
+		t.Fatalf("expected ErrMissingRequired, got %v", err)
+	}
+	if !reflect.DeepEqual(mr.Flags, []string{"-port", "-user"}) {
+		t.Errorf("expected -port and -user missing, as a default does not give a flag, got %v", mr.Flags)
+	}
+	if _, err := NewParser().Apply([]string{"-host", "a", "-port", "1", "-user", "b"}, &rf); err != nil {
+		t.Errorf("unexpected error  %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package argflags
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// dnsPort is the port of a DNSServer given without one.
+const dnsPort = "53"
+
+// DNSServer is the address of a DNS server, an IP address or host name with an optional port, defaulting to 53.
+// e.g. '1.1.1.1', '1.1.1.1:53', '[2606:4700:4700::1111]:53' or 'dns.local:5353'
+type DNSServer struct {
+	Host string
+	Port string
+}
+
+// String gets the server as a 'host:port' address.
+func (s DNSServer) String() string {
+	if s.Host == "" {
+		return ""
+	}
+	return net.JoinHostPort(s.Host, s.Port)
+}
+
+func (s DNSServer) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *DNSServer) UnmarshalText(text []byte) error {
+	addr := strings.TrimSpace(string(text))
+	host, port := addr, dnsPort
+	if h, p, err := net.SplitHostPort(addr); err == nil {
+		host, port = h, p
+	} else if strings.HasPrefix(addr, "[") && strings.HasSuffix(addr, "]") {
+		host = addr[1 : len(addr)-1]
+	}
+	if host == "" {
+		return fmt.Errorf("invalid dns server %q, missing host", addr)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid dns server %q, port must be 1 to 65535", addr)
+	}
+	if net.ParseIP(host) == nil && strings.ContainsAny(host, ":/ ") {
+		return fmt.Errorf("invalid dns server %q, expected an IP address or host name", addr)
+	}
+	s.Host, s.Port = host, port
+	return nil
+}
+
+// ResolverOpts is a mixin of flags selecting the DNS servers used for name lookups, for network diagnostic tools.
+// Include it in a flags struct as a sub arg: e.g. DNS argflags.ResolverOpts `flag:"+"`
+// then give flags such as: -dns 1.1.1.1 -dns 8.8.8.8:53 -dns-timeout 2s
+type ResolverOpts struct {
+	// Servers are the DNS servers queried, in turn, in place of those of the system.  -dns may be given more than once.
+	Servers []DNSServer `flag:"dns" help:"DNS server to query, in place of the system's, may be repeated"`
+	// Timeout limits the time of each query to a server. Zero has no limit.
+	Timeout time.Duration `flag:"dns-timeout" help:"time limit of each DNS query"`
+}
+
+// Resolver gets a net.Resolver querying the Servers, each query going to the next server in turn, limited by the Timeout.
+// With no Servers or Timeout, the default resolver is returned.
+func (o ResolverOpts) Resolver() *net.Resolver {
+	if len(o.Servers) == 0 && o.Timeout <= 0 {
+		return net.DefaultResolver
+	}
+	servers := make([]string, len(o.Servers))
+	for i, s := range o.Servers {
+		servers[i] = s.String()
+	}
+	timeout := o.Timeout
+	var next uint32
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			if len(servers) > 0 {
+				address = servers[int(atomic.AddUint32(&next, 1)-1)%len(servers)]
+			}
+			d := net.Dialer{Timeout: timeout}
+			conn, err := d.DialContext(ctx, network, address)
+			if err != nil || timeout <= 0 {
+				return conn, err
+			}
+			return conn, conn.SetDeadline(time.Now().Add(timeout))
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package argflags
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDNSServer(t *testing.T) {
+	tests := map[string]string{
+		"1.1.1.1":                   "1.1.1.1:53",
+		"8.8.8.8:5353":              "8.8.8.8:5353",
+		"[2606:4700:4700::1111]:53": "[2606:4700:4700::1111]:53",
+		"[::1]":                     "[::1]:53",
+		"dns.local":                 "dns.local:53",
+	}
+	for s, expect := range tests {
+		var ds DNSServer
+		if err := ds.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if ds.String() != expect {
+			t.Errorf("%s  expected %s, got %s", s, expect, ds)
+		}
+	}
+	for _, s := range []string{"", ":53", "1.1.1.1:0", "1.1.1.1:x", "dns.local:99999", "a b"} {
+		var ds DNSServer
+		if err := ds.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestResolverOpts(t *testing.T) {
+	var flags struct {
+		DNS ResolverOpts `flag:"+"`
+	}
+	if flags.DNS.Resolver() != net.DefaultResolver {
+		t.Errorf("expected the default resolver without any flags")
+	}
+	if _, err := (ArgFlags{"-dns", "127.0.0.1:5301", "-dns", "127.0.0.1:5302", "-dns-timeout", "2s"}).ApplyTo(&flags); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if len(flags.DNS.Servers) != 2 || flags.DNS.Timeout != 2*time.Second {
+		t.Fatalf("expected two servers and a timeout, got %+v", flags.DNS)
+	}
+	r := flags.DNS.Resolver()
+	var addrs []string
+	for i := 0; i < 3; i++ {
+		conn, err := r.Dial(context.Background(), "udp", "192.0.2.1:53")
+		if err != nil {
+			t.Fatalf("unexpected error  %v", err)
+		}
+		addrs = append(addrs, conn.RemoteAddr().String())
+		_ = conn.Close()
+	}
+	if !reflect.DeepEqual(addrs, []string{"127.0.0.1:5301", "127.0.0.1:5302", "127.0.0.1:5301"}) {
+		t.Errorf("expected each query to go to the next server in turn, got %v", addrs)
+	}
+}
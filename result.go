@@ -0,0 +1,110 @@
+package argflags
+
+// Result reports the outcome of applying argument flags to a struct.
+type Result struct {
+	// Unused are the arguments which were not applied to the struct, in the order they were given.
+	Unused []string
+
+	// Instantiated are the nil sub arg fields which were created to hold a flag value.
+	// Each is the dot delimited path of field names from the root struct, e.g. "Database.TLS".
+	// Sub args tagged as 'preserve-nil' are never instantiated, their flags remain unused whilst they are nil.
+	Instantiated []string
+
+	// Fields are the fields which were set, in the order they were first set, with where their values came from.
+	Fields []SetField
+
+	// Occurrences are the flags applied, in the order they occurred in the arguments, including every repeat of a flag.
+	// Flags which failed, or were not applied, are not included.
+	Occurrences []Occurrence
+
+	// Warnings are the errors which did not fail the flags they occurred in,
+	// such as the elements left out of a slice, when parsed with partial slices, see WithPartialSlices.
+	Warnings []error
+
+	// applied are the fields set by the flags.
+	applied []appliedField
+}
+
+// Source is where the value of a field was set from.
+type Source int
+
+const (
+	SourceFlag Source = iota + 1
+	SourceArg
+	SourceEnv
+	SourceDefault
+	SourceConfig
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceFlag:
+		return "flag"
+	case SourceArg:
+		return "arg"
+	case SourceEnv:
+		return "env"
+	case SourceDefault:
+		return "default"
+	case SourceConfig:
+		return "config"
+	}
+	return "unknown"
+}
+
+// SetField is a field which was set, where its value came from, and the raw values it was set with.
+type SetField struct {
+	// Field is the dot delimited path of field names, from the struct, to the field. e.g. "Database.Host"
+	Field  string
+	Source Source
+	// Name is the last flag which set the field, as given, the environment variable, e.g. '$MYAPP_HOST', the config file key,
+	// or for positional and default values, the field name.
+	Name string
+	// Values are the raw values the field was set with, in order, one for each time its flag was given,
+	// or for positional slice fields, each argument.  Secret values are masked.
+	Values []string
+}
+
+// Lookup gets the given field, named by its dot delimited path, and if it was set.
+func (r *Result) Lookup(field string) (SetField, bool) {
+	for _, sf := range r.Fields {
+		if sf.Field == field {
+			return sf, true
+		}
+	}
+	return SetField{}, false
+}
+
+// IsSet checks if the given field, named by its dot delimited path, was set explicitly in the arguments, by a flag or position,
+// rather than from the environment or a default.
+func (r *Result) IsSet(field string) bool {
+	sf, ok := r.Lookup(field)
+	return ok && (sf.Source == SourceFlag || sf.Source == SourceArg)
+}
+
+// Occurrence is a single flag, as it occurred in the arguments.
+type Occurrence struct {
+	// Ordinal is the position of the flag among the flags applied, counted from zero.
+	Ordinal int
+	// Arg is the index, in the arguments, of the argument holding the flag.
+	Arg int
+	// Flag is the flag as given, including its dashes.  Each of a group of combined short flags is given alone, e.g. '-x'
+	// and a flag matched by its prefix is given by the whole name it matched, e.g. '--verbose' for '--verb'
+	Flag string
+	// Value is the value the flag was given, 'true' for bool flags given without a value, or empty for counts.
+	// Secret values are masked.
+	Value string
+	// Field is the dot delimited path of field names, from the struct, to the field the flag set. e.g. "Database.Host"
+	Field string
+}
+
+// OccurrencesOf gets the occurrences of the flags which set the given field, named by its dot delimited path, in order.
+func (r *Result) OccurrencesOf(field string) []Occurrence {
+	var occs []Occurrence
+	for _, occ := range r.Occurrences {
+		if occ.Field == field {
+			occs = append(occs, occ)
+		}
+	}
+	return occs
+}
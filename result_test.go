@@ -0,0 +1,65 @@
+package argflags
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type resultFlags struct {
+	Host     string   `flag:"host" default:"localhost"`
+	Tags     []string `flag:"tag"`
+	Password string   `flag:"password,secret"`
+	Verbose  bool     `flag:"v"`
+}
+
+func TestOccurrences(t *testing.T) {
+	var rf resultFlags
+	res, err := NewParser().Apply([]string{"-tag", "a", "-v", "-password", "p", "-tag=b"}, &rf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	var flags []string
+	for _, occ := range res.Occurrences {
+		flags = append(flags, occ.Flag+" "+occ.Value)
+	}
+	if strings.Join(flags, ",") != "-tag a,-v true,-password "+secretMask+",-tag b" {
+		t.Errorf("unexpected occurrences %q", flags)
+	}
+	if occs := res.OccurrencesOf("Tags"); len(occs) != 2 || occs[1].Arg != 5 || occs[1].Ordinal != 3 {
+		t.Errorf("unexpected occurrences of Tags %+v", occs)
+	}
+}
+
+func TestSetFields(t *testing.T) {
+	var rf resultFlags
+	res, err := NewParser().Apply([]string{"-tag", "a", "-tag", "b", "-password", "p"}, &rf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	tags, _ := res.Lookup("Tags")
+	if tags.Source != SourceFlag || !reflect.DeepEqual(tags.Values, []string{"a", "b"}) {
+		t.Errorf("unexpected Tags %+v", tags)
+	}
+	if pw, _ := res.Lookup("Password"); pw.Values[0] != secretMask {
+		t.Errorf("expected the password to be masked, got %+v", pw)
+	}
+	if host, _ := res.Lookup("Host"); host.Source != SourceDefault || res.IsSet("Host") || res.IsSet("Verbose") {
+		t.Errorf("expected Host from its default, got %+v", host)
+	}
+}
+
+func TestOnSet(t *testing.T) {
+	var names []string
+	p := NewParser()
+	p.OnSet(func(name, raw string, field reflect.Value) {
+		names = append(names, name+"="+raw)
+	})
+	var rf resultFlags
+	if _, err := p.Apply([]string{"-tag", "a,b", "-password", "p"}, &rf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"-tag=a,b", "-password=" + secretMask, "Host=localhost"}) {
+		t.Errorf("unexpected callbacks %q", names)
+	}
+}
@@ -0,0 +1,52 @@
+package argflags
+
+import (
+	"context"
+	"time"
+)
+
+// RetryOpts is a mixin of flags for a commands timeout and retries, which yield a ready Context and retry helper for the handler.
+// Include it in a flags struct as a sub arg: e.g. Retry argflags.RetryOpts `flag:"+"`
+// then give flags such as: -timeout 30s -retries 3 -retry-backoff 500ms
+type RetryOpts struct {
+	// Timeout limits the time the whole command may take, including all of its retries. Zero has no limit.
+	Timeout time.Duration `flag:"timeout"`
+	// Retries is the number of times a failed operation is retried, after its first attempt.
+	Retries int `flag:"retries"`
+	// RetryBackoff is the delay before the first retry, doubling before each following retry.
+	RetryBackoff time.Duration `flag:"retry-backoff"`
+}
+
+// Context gets a Context from the given parent, which is cancelled when the Timeout expires.
+// With no Timeout, the context is only cancelled when the returned CancelFunc is called, or the parent is done.
+func (o RetryOpts) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	if o.Timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, o.Timeout)
+}
+
+// Retry calls the given function, retrying it when it fails, up to the number of Retries.
+// Before each retry, it waits for the backoff delay, which starts at RetryBackoff and doubles after each retry.
+// Retrying stops when the context is done, returning the last error from the function.
+func (o RetryOpts) Retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	backoff := o.RetryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(ctx); err == nil || attempt >= o.Retries {
+			return err
+		}
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+			backoff *= 2
+		} else if ctx.Err() != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package argflags
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryOptsFlags(t *testing.T) {
+	var flags struct {
+		Retry RetryOpts `flag:"+"`
+	}
+	if _, err := NewParser().Apply([]string{"-timeout", "30s", "-retries", "3", "-retry-backoff", "500ms"}, &flags); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if flags.Retry != (RetryOpts{Timeout: 30 * time.Second, Retries: 3, RetryBackoff: 500 * time.Millisecond}) {
+		t.Errorf("expected the retry flags to be set, got %+v", flags.Retry)
+	}
+	ctx, cancel := flags.Retry.Context(context.Background())
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > 30*time.Second {
+		t.Errorf("expected the context to have the timeout, got %v", deadline)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	failure := errors.New("failed")
+	attempts := 0
+	fails := func(n int) func(ctx context.Context) error {
+		attempts = 0
+		return func(ctx context.Context) error {
+			attempts++
+			if attempts <= n {
+				return failure
+			}
+			return nil
+		}
+	}
+	o := RetryOpts{Retries: 2, RetryBackoff: time.Millisecond}
+	if err := o.Retry(context.Background(), fails(1)); err != nil || attempts != 2 {
+		t.Errorf("expected success on the second attempt, got %d, %v", attempts, err)
+	}
+	if err := o.Retry(context.Background(), fails(5)); err != failure || attempts != 3 {
+		t.Errorf("expected the last error after the retries, got %d, %v", attempts, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	o.RetryBackoff = time.Hour
+	if err := o.Retry(ctx, fails(5)); err != failure || attempts != 1 {
+		t.Errorf("expected no retries once the context is done, got %d, %v", attempts, err)
+	}
+}
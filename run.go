@@ -0,0 +1,71 @@
+package argflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultGracePeriod is the time a command is given to stop, once it has been interrupted, unless -grace-period is given.
+const DefaultGracePeriod = 10 * time.Second
+
+// ErrForcedShutdown is returned by Run when a command did not stop within its grace period,
+// or a second interrupt was received whilst waiting for it to stop.
+var ErrForcedShutdown = errors.New("forced shutdown")
+
+// RunOpts are the flags accepted by Run, in addition to the flags of the commands.
+type RunOpts struct {
+	// GracePeriod is the time the command is given to stop, once interrupted, before Run returns regardless.
+	GracePeriod time.Duration `flag:"grace-period"`
+}
+
+// Run executes the command with the given arguments, as Execute, with the interrupt (SIGINT) and terminate (SIGTERM) signals handled.
+// The first signal cancels the Context passed to the command handler, which should then stop.
+// If the handler has not returned within the grace period, or a second signal is received, Run returns ErrForcedShutdown,
+// without waiting for the handler, so the program can exit.
+// The grace period may be given with the '-grace-period' flag, accepted by every command, or it defaults to DefaultGracePeriod.
+func (c *Command) Run(args []string) error {
+	opts := &RunOpts{GracePeriod: DefaultGracePeriod}
+	// the grace period is read once the flags are applied, as the signal may arrive whilst they are still being applied
+	var gracePeriod atomic.Int64
+	gracePeriod.Store(int64(opts.GracePeriod))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	done := make(chan error, 1)
+	go func() {
+		masked := make([]string, len(args))
+		copy(masked, args)
+		explicit := &explicitFields{isSet: map[fieldKey]bool{}, masked: masked, beforeHandler: func() {
+			gracePeriod.Store(int64(opts.GracePeriod))
+		}}
+		inherited := []applyTarget{{value: reflect.ValueOf(opts).Elem()}}
+		done <- c.execute(ctx, args, inherited, explicit)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case sig := <-signals:
+		cancel()
+		grace := time.NewTimer(time.Duration(gracePeriod.Load()))
+		defer grace.Stop()
+		select {
+		case err := <-done:
+			return err
+		case <-signals:
+		case <-grace.C:
+		}
+		return fmt.Errorf("%w after %v", ErrForcedShutdown, sig)
+	}
+}
@@ -0,0 +1,62 @@
+package argflags
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newBlockingCommand gets a command whose handler signals it has started, then waits for the context,
+// unless ignoreCancel, when it waits until the test ends.
+func newBlockingCommand(t *testing.T, ignoreCancel bool) (*Command, chan struct{}) {
+	started := make(chan struct{})
+	ended := make(chan struct{})
+	t.Cleanup(func() {
+		close(ended)
+	})
+	return &Command{Name: "app", Handler: func(ctx context.Context, inv *Invocation) error {
+		if len(inv.Args) > 0 {
+			return errors.New("unexpected arguments")
+		}
+		close(started)
+		if ignoreCancel {
+			<-ended
+			return nil
+		}
+		<-ctx.Done()
+		return nil
+	}}, started
+}
+
+func interrupt(t *testing.T, started chan struct{}) {
+	go func() {
+		<-started
+		if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+			t.Error(err)
+		}
+	}()
+}
+
+func TestRunInterrupted(t *testing.T) {
+	cmd, started := newBlockingCommand(t, false)
+	interrupt(t, started)
+	if err := cmd.Run(nil); err != nil {
+		t.Errorf("expected the handler to stop when interrupted, got %v", err)
+	}
+}
+
+func TestRunGracePeriod(t *testing.T) {
+	cmd, started := newBlockingCommand(t, true)
+	interrupt(t, started)
+	begin := time.Now()
+	err := cmd.Run([]string{"-grace-period", "50ms"})
+	if !errors.Is(err, ErrForcedShutdown) {
+		t.Errorf("expected a forced shutdown, got %v", err)
+	}
+	if time.Since(begin) > DefaultGracePeriod/2 {
+		t.Errorf("expected the -grace-period flag to be used, took %v", time.Since(begin))
+	}
+}
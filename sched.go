@@ -0,0 +1,156 @@
+package argflags
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Niceness is a process scheduling priority, from -20, the highest priority, to 19, the lowest, as used by nice(1).
+type Niceness int
+
+const (
+	MinNiceness Niceness = -20
+	MaxNiceness Niceness = 19
+)
+
+// Int gets the niceness as an int, as given to syscall.Setpriority
+func (n Niceness) Int() int {
+	return int(n)
+}
+
+func (n Niceness) String() string {
+	return strconv.Itoa(int(n))
+}
+
+func (n Niceness) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+func (n *Niceness) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	i, err := strconv.Atoi(s)
+	if err != nil || Niceness(i) < MinNiceness || Niceness(i) > MaxNiceness {
+		return fmt.Errorf("invalid niceness %q, expected %d to %d", s, MinNiceness, MaxNiceness)
+	}
+	*n = Niceness(i)
+	return nil
+}
+
+// MaxCPU is the highest CPU number accepted in a CPUSet
+var MaxCPU = 1023
+
+// CPUSet is a set of CPU numbers, as used for CPU affinity, given as a list of CPUs and ranges, e.g. '0-3,8'.
+// Ranges may have a stride, e.g. '0-7:2' is 0,2,4,6, as with taskset(1).
+type CPUSet struct {
+	cpus []int
+}
+
+// CPUs gets the CPU numbers in the set, in order.
+func (cs CPUSet) CPUs() []int {
+	return append([]int{}, cs.cpus...)
+}
+
+// Count gets the number of CPUs in the set.
+func (cs CPUSet) Count() int {
+	return len(cs.cpus)
+}
+
+// Contains checks if the given CPU is in the set.
+func (cs CPUSet) Contains(cpu int) bool {
+	i := sort.SearchInts(cs.cpus, cpu)
+	return i < len(cs.cpus) && cs.cpus[i] == cpu
+}
+
+// Mask gets the set as a bit mask, with a bit set for each CPU, in words of 64 CPUs, as used by sched_setaffinity.
+func (cs CPUSet) Mask() []uint64 {
+	if len(cs.cpus) == 0 {
+		return nil
+	}
+	mask := make([]uint64, cs.cpus[len(cs.cpus)-1]/64+1)
+	for _, cpu := range cs.cpus {
+		mask[cpu/64] |= 1 << (cpu % 64)
+	}
+	return mask
+}
+
+// String gets the set in its shortest list form, with consecutive CPUs as ranges, e.g. '0-3,8'
+func (cs CPUSet) String() string {
+	var parts []string
+	for i := 0; i < len(cs.cpus); {
+		j := i
+		for j+1 < len(cs.cpus) && cs.cpus[j+1] == cs.cpus[j]+1 {
+			j++
+		}
+		if j == i {
+			parts = append(parts, strconv.Itoa(cs.cpus[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", cs.cpus[i], cs.cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+func (cs CPUSet) MarshalText() ([]byte, error) {
+	return []byte(cs.String()), nil
+}
+
+func (cs *CPUSet) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	set := map[int]bool{}
+	for _, part := range strings.Split(s, ",") {
+		if err := addCPURange(set, strings.TrimSpace(part)); err != nil {
+			return fmt.Errorf("invalid cpu set %q  %v", s, err)
+		}
+	}
+	cpus := make([]int, 0, len(set))
+	for cpu := range set {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	cs.cpus = cpus
+	return nil
+}
+
+// addCPURange adds the CPUs of the given cpu, or range of cpus, 'first-last[:stride]', to the given set.
+func addCPURange(set map[int]bool, s string) error {
+	rng, strideText, hasStride := strings.Cut(s, ":")
+	first, last, isRange := strings.Cut(rng, "-")
+	if !isRange {
+		last = first
+	}
+	if hasStride && !isRange {
+		return fmt.Errorf("stride %q given without a range", s)
+	}
+	from, err := parseCPU(first)
+	if err != nil {
+		return err
+	}
+	to, err := parseCPU(last)
+	if err != nil {
+		return err
+	}
+	if from > to {
+		return fmt.Errorf("range %q is reversed", s)
+	}
+	stride := 1
+	if hasStride {
+		if stride, err = strconv.Atoi(strideText); err != nil || stride < 1 {
+			return fmt.Errorf("invalid stride %q", strideText)
+		}
+	}
+	for cpu := from; cpu <= to; cpu += stride {
+		set[cpu] = true
+	}
+	return nil
+}
+
+func parseCPU(s string) (int, error) {
+	cpu, err := strconv.Atoi(s)
+	if err != nil || cpu < 0 || cpu > MaxCPU {
+		return 0, fmt.Errorf("invalid cpu %q, expected 0 to %d", s, MaxCPU)
+	}
+	return cpu, nil
+}
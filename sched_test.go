@@ -0,0 +1,65 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNiceness(t *testing.T) {
+	var n Niceness
+	if err := n.UnmarshalText([]byte(" -5 ")); err != nil || n.Int() != -5 || n.String() != "-5" {
+		t.Errorf("expected -5, got %v, %v", n, err)
+	}
+	for _, s := range []string{"-21", "20", "low", ""} {
+		if err := n.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestCPUSet(t *testing.T) {
+	tests := map[string][]int{
+		"0-3,8": {0, 1, 2, 3, 8},
+		"0-7:2": {0, 2, 4, 6},
+		"3,1,3": {1, 3},
+		"64":    {64},
+	}
+	for s, expect := range tests {
+		var cs CPUSet
+		if err := cs.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if !reflect.DeepEqual(cs.CPUs(), expect) || cs.Count() != len(expect) {
+			t.Errorf("%s  expected %v, got %v", s, expect, cs.CPUs())
+		}
+	}
+	for _, s := range []string{"", "a", "3-1", "1:2", "0-3:0", "-1", "1024"} {
+		var cs CPUSet
+		if err := cs.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestCPUSetFormats(t *testing.T) {
+	var cs CPUSet
+	if err := cs.UnmarshalText([]byte("8,0-2,3,65")); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if cs.String() != "0-3,8,65" {
+		t.Errorf("expected the shortest list, got %s", cs)
+	}
+	if !cs.Contains(8) || cs.Contains(4) {
+		t.Errorf("expected only the cpus in the set, got %s", cs)
+	}
+	if mask := cs.Mask(); !reflect.DeepEqual(mask, []uint64{0x10f, 0x2}) {
+		t.Errorf("expected a mask of two words, got %x", mask)
+	}
+	var flags struct {
+		CPUs []CPUSet `flag:"cpus" delim:";"`
+	}
+	if _, err := (ArgFlags{"-cpus", "0-1,4;2-3"}).ApplyTo(&flags); err != nil || len(flags.CPUs) != 2 || flags.CPUs[0].Count() != 3 {
+		t.Errorf("expected a set for each delimited value, got %v, %v", flags.CPUs, err)
+	}
+}
@@ -0,0 +1,101 @@
+package argflags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// schemaCache holds the Schema of each struct type, built the first time the type is used, for each tag name and match policy.
+var schemaCache sync.Map
+
+// schemaKey identifies the Schema of a struct type, built with a tag name and match policy.
+type schemaKey struct {
+	t       reflect.Type
+	tagName string
+	match   MatchPolicy
+}
+
+// Schema is the compiled flags of a struct type, mapping every flag name, including those of its sub args, to the field it sets.
+// A Schema is built once for each type and then cached, so applying flags to the same type again does not walk the struct.
+// Schemas are read only, and safe to use concurrently.
+type Schema struct {
+	Type          reflect.Type
+	caseSensitive bool
+	index         fieldIndex
+	flags         []flagDescription
+	// err holds the errors in the struct tags of the type, found when the schema was built.
+	err error
+}
+
+// SchemaFlag describes a single flag field of a Schema.
+type SchemaFlag struct {
+	// Names are the flag names which set the field, without any shadowed by another field.
+	Names []string
+	// Index is the path of field indexes, from the struct down to the field, as used by reflect.Value.FieldByIndex
+	Index []int
+	Field reflect.StructField
+	// Group is the prefix of the prefixed sub arg the flag is within, e.g. 'db' for `flag:"+db"`, or empty if not prefixed.
+	Group string
+}
+
+// BuildSchema gets the Schema of the given struct type, or pointer to a struct, using the default 'flag' tag and match policy.
+func BuildSchema(t reflect.Type) (*Schema, error) {
+	return NewParser().Schema(t)
+}
+
+// Schema gets the Schema of the given struct type, or pointer to a struct, with the tag name and match policy of the parser.
+// An error is returned if the struct has fields tagged as sub args which are not structs.
+func (p *Parser) Schema(t reflect.Type) (*Schema, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct or pointer to a struct", t.String())
+	}
+	s := p.schemaOf(t)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s, nil
+}
+
+// schemaOf gets the Schema of the given struct type, building it if not already cached.
+func (p *Parser) schemaOf(t reflect.Type) *Schema {
+	key := schemaKey{t: t, tagName: p.tagName, match: p.match}
+	if s, ok := schemaCache.Load(key); ok {
+		return s.(*Schema)
+	}
+	s := &Schema{Type: t, caseSensitive: p.caseSensitive()}
+	s.index, s.err = p.buildFieldIndex(t, map[reflect.Type]bool{})
+	s.flags = p.describeFields(t, nil, "", s.index, map[reflect.Type]bool{})
+	actual, _ := schemaCache.LoadOrStore(key, s)
+	return actual.(*Schema)
+}
+
+// Lookup gets the index of the field set by the given flag name, or false if no field matches the name.
+func (s *Schema) Lookup(name string) ([]int, bool) {
+	if !s.caseSensitive {
+		name = strings.ToLower(name)
+	}
+	index, ok := s.index[name]
+	if !ok {
+		return nil, false
+	}
+	return append([]int{}, index...), true
+}
+
+// Flags gets each of the flag fields of the schema, in field order.
+func (s *Schema) Flags() []SchemaFlag {
+	flags := make([]SchemaFlag, len(s.flags))
+	for i, fd := range s.flags {
+		flags[i] = SchemaFlag{
+			Names: append([]string{}, fd.names...),
+			Index: append([]int{}, fd.index...),
+			Field: fd.field,
+			Group: fd.group,
+		}
+	}
+	return flags
+}
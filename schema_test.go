@@ -0,0 +1,48 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	type schemaFlags struct {
+		Port int     `flag:"port,p"`
+		DB   *dbOpts `flag:"+db"`
+	}
+	s, err := BuildSchema(reflect.TypeOf(&schemaFlags{}))
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if index, ok := s.Lookup("DB-Host"); !ok || !reflect.DeepEqual(index, []int{1, 0}) {
+		t.Errorf("expected the prefixed sub arg flag, got %v, %v", index, ok)
+	}
+	if _, ok := s.Lookup("host"); ok {
+		t.Errorf("expected the prefixed flag not to match without its prefix")
+	}
+	var names [][]string
+	for _, f := range s.Flags() {
+		names = append(names, f.Names)
+	}
+	if !reflect.DeepEqual(names, [][]string{{"port", "p"}, {"db.host"}, {"db.port"}}) || s.Flags()[1].Group != "db" {
+		t.Errorf("expected the flags in field order, got %v", names)
+	}
+	if again, _ := BuildSchema(reflect.TypeOf(schemaFlags{})); again != s {
+		t.Errorf("expected the schema to be cached")
+	}
+	if other, _ := NewParser(WithTagName("opt")).Schema(reflect.TypeOf(schemaFlags{})); other == s {
+		t.Errorf("expected a schema for each tag name")
+	}
+}
+
+func TestSchemaErrors(t *testing.T) {
+	if _, err := BuildSchema(reflect.TypeOf(1)); err == nil {
+		t.Errorf("expected a type which is not a struct to be an error")
+	}
+	var bad struct {
+		Sub string `flag:"+"`
+	}
+	if _, err := BuildSchema(reflect.TypeOf(bad)); err == nil {
+		t.Errorf("expected a sub arg which is not a struct to be an error")
+	}
+}
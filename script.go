@@ -0,0 +1,70 @@
+package argflags
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ScriptErrorPolicy defines how a script continues when one of its lines fails.
+type ScriptErrorPolicy int
+
+const (
+	// StopOnError ends the script at the first line to fail, returning its error.
+	StopOnError ScriptErrorPolicy = iota
+	// ContinueOnError executes every line of the script, returning the errors of all the lines which failed.
+	ContinueOnError
+)
+
+// ExecuteScript executes each line of the given script as a separate invocation of this command.
+// Each line is split into arguments, as a shell would, and executed as if those arguments were given on the command line.
+// Empty lines and lines beginning with '#' are ignored.
+// The flags of every command are reset to their initial values before each line is executed.
+// Errors are prefixed with the line number they occurred on.
+func (c *Command) ExecuteScript(ctx context.Context, script io.Reader, policy ScriptErrorPolicy) error {
+	initial := c.flagSnapshots()
+	scanner := bufio.NewScanner(script)
+	var errs []error
+	var lineNumber int
+	for scanner.Scan() {
+		lineNumber++
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args, err := splitCommandLine(line)
+		if err == nil {
+			initial.restore()
+			err = c.Execute(ctx, args)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d  %v", lineNumber, err))
+			if policy == StopOnError {
+				break
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ExecuteScriptFile executes the script in the named file, in the same way as ExecuteScript.
+func (c *Command) ExecuteScriptFile(ctx context.Context, path string, policy ScriptErrorPolicy) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+	return c.ExecuteScript(ctx, f, policy)
+}
@@ -0,0 +1,26 @@
+package argflags
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExecuteScript(t *testing.T) {
+	script := "# serve some ports\nserve -port 1\nserve -port x\n\nserve -port 3\n"
+	var ports []int
+	err := newPortsCommand(&ports).ExecuteScript(context.Background(), strings.NewReader(script), StopOnError)
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3  ") {
+		t.Errorf("expected the error of line 3, got %v", err)
+	}
+	if !reflect.DeepEqual(ports, []int{1}) {
+		t.Errorf("expected the script to stop at the error, got %v", ports)
+	}
+
+	ports = nil
+	err = newPortsCommand(&ports).ExecuteScript(context.Background(), strings.NewReader(script), ContinueOnError)
+	if err == nil || !reflect.DeepEqual(ports, []int{1, 3}) {
+		t.Errorf("expected the script to continue past the error, got %v, %v", ports, err)
+	}
+}
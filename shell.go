@@ -0,0 +1,81 @@
+package argflags
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Shell runs an interactive shell, reading command lines from the given reader and executing each one on this command.
+// Each line is split into arguments, as a shell would, and executed as if those arguments were given on the command line.
+// Errors are written to the output, and the shell continues with the next line.
+// The flags of every command are reset to their initial values before each line is executed.
+// The shell ends when the input ends, the context is done, or an 'exit' or 'quit' line is read.
+// A 'help' line, when the command has no sub command named help, writes the commands Help.
+func (c *Command) Shell(ctx context.Context, in io.Reader, out io.Writer) error {
+	prompt := c.Name + "> "
+	initial := c.flagSnapshots()
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, prompt)
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case line == "exit" || line == "quit":
+			return nil
+		case line == "help" && c.Command("help") == nil:
+			fmt.Fprint(out, c.Help())
+			continue
+		}
+		args, err := splitCommandLine(line)
+		if err == nil {
+			initial.restore()
+			err = c.Execute(ctx, args)
+		}
+		if err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+		}
+	}
+}
+
+// flagSnapshot is a copy of a flag struct value.
+type flagSnapshot struct {
+	value reflect.Value
+	copy  reflect.Value
+}
+
+type flagSnapshots []flagSnapshot
+
+// flagSnapshots takes a deep copy of the flag structs of this command and all its sub commands,
+// so slices and maps the flags add to are not shared with the snapshot.
+func (c *Command) flagSnapshots() flagSnapshots {
+	var snaps flagSnapshots
+	if c.Flags != nil {
+		if v, err := getStructValue(c.Flags); err == nil {
+			snaps = append(snaps, flagSnapshot{value: *v, copy: cloneValue(*v)})
+		}
+	}
+	for _, cmd := range c.commands {
+		snaps = append(snaps, cmd.flagSnapshots()...)
+	}
+	return snaps
+}
+
+// restore sets the flag structs back to the values they had when the snapshots were taken.
+// Each restore sets a new copy of the snapshot, so it may be restored again.
+func (snaps flagSnapshots) restore() {
+	for _, snap := range snaps {
+		snap.value.Set(cloneValue(snap.copy))
+	}
+}
@@ -0,0 +1,39 @@
+package argflags
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newPortsCommand gets a root command, with a 'serve' sub command recording the port of each invocation.
+func newPortsCommand(ports *[]int) *Command {
+	sf := &serveFlags{}
+	root := &Command{Name: "app"}
+	root.AddCommand(&Command{Name: "serve", Flags: sf, Handler: func(ctx context.Context, inv *Invocation) error {
+		*ports = append(*ports, sf.Port)
+		return nil
+	}})
+	return root
+}
+
+func TestShell(t *testing.T) {
+	var ports []int
+	root := newPortsCommand(&ports)
+	in := strings.NewReader("serve -port 80\n\nserve\nserve -port x\nhelp\nexit\nserve -port 81\n")
+	out := &bytes.Buffer{}
+	if err := root.Shell(context.Background(), in, out); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(ports, []int{80, 0}) {
+		t.Errorf("expected the flags reset before each line, and nothing after exit, got %v", ports)
+	}
+	if !strings.HasPrefix(out.String(), "app> ") || !strings.Contains(out.String(), "error: ") {
+		t.Errorf("expected the prompt and the error of the failed line, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), root.Help()) {
+		t.Errorf("expected help to write the command help, got %q", out.String())
+	}
+}
@@ -0,0 +1,104 @@
+package argflags
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// CombinedShortFlags, when set, enables POSIX style grouping of single letter flags, so '-xvf' is applied as '-x -v -f'.
+// An argument is only split when it has a single dash, does not match a flag itself,
+// and every letter matches a single letter flag.  Every letter, except the last, must be a bool or count flag.
+// The last letter may take a value, as any flag, so '-xvf out.tar' sets -f to 'out.tar'.
+// The value attached to the last letter, such as '-xvfout.tar', is split from it, as for any single letter flag.
+var CombinedShortFlags bool
+
+// splitShortFlags checks if the given flag is a single letter flag with its value attached, such as '-n5',
+// or, with combined short flags, a group of single letter flags, returning the letters when it is.
+// When a letter takes a value, and is followed by more of the flag, it is the last letter, the remainder being its attached value.
+func (a *applier) splitShortFlags(flag string) (letters []string, attached string) {
+	if strings.HasPrefix(flag, "--") {
+		return nil, ""
+	}
+	name := []rune(strings.TrimPrefix(flag, "-"))
+	if len(name) < 2 {
+		return nil, ""
+	}
+	for i, r := range name {
+		if i > 0 && !a.p.combinedShortFlags {
+			// without combining, only a letter with an attached value is split
+			return nil, ""
+		}
+		letter := string(r)
+		takesValue, ok := a.shortFlagTakesValue(letter)
+		if !ok {
+			return nil, ""
+		}
+		letters = append(letters, letter)
+		if takesValue && i < len(name)-1 {
+			return letters, string(name[i+1:])
+		}
+	}
+	return letters, ""
+}
+
+// shortFlagTakesValue checks if the given single letter flag takes a value, being neither a bool or count.
+// returns false if there is no such flag.
+func (a *applier) shortFlagTakesValue(letter string) (takesValue bool, ok bool) {
+	if fld := a.findGroupField(letter); fld != nil {
+		return !fld.isCount() && !isBoolType(fld.Type()), true
+	}
+	if !a.hasFlag(letter) {
+		return false, false
+	}
+	for _, target := range a.targets {
+		index := a.p.findFieldIndex(letter, target.value.Type(), nil)
+		if len(index) == 0 || target.hidden[indexKey(index)] {
+			continue
+		}
+		f := target.value.Type().FieldByIndex(index)
+		return !a.p.hasTagOption(f, optCount) && !isBoolType(f.Type), true
+	}
+	return false, false
+}
+
+// applyShortFlags applies each of the given letters, from the argument at the given index, as a flag without a value.
+// The flags must be a bool, which is set to true, or a count, which is counted.
+// returns the errors of all the letters which failed.
+func (a *applier) applyShortFlags(argIndex int, letters []string) error {
+	var errs []error
+	for _, letter := range letters {
+		fld := a.findFlagField(letter)
+		a.result.Instantiated = append(a.result.Instantiated, fld.instantiated...)
+		var err error
+		value := ""
+		switch {
+		case fld.isCount():
+			err = addCount(fld.fldValue, 1)
+		case isBoolType(fld.Type()):
+			value = strconv.FormatBool(true)
+			err = a.p.setValue(value, fld.fldValue)
+		default:
+			errs = append(errs, a.failed(fld, ErrMissingValue{Flag: "-" + letter, Reason: "requires a value, so must be the last of the combined flags"}))
+			continue
+		}
+		if err != nil {
+			errs = append(errs, a.failed(fld, ErrConversion{Flag: "-" + letter, Type: fld.Type(), Err: err}))
+			continue
+		}
+		a.setApplied("-"+letter, fld)
+		a.occurred(argIndex, "-"+letter, value, fld)
+	}
+	return errors.Join(errs...)
+}
+
+// hasFlag checks if any of the targets has a field for the given flag name, without instantiating any nil sub args.
+func (a *applier) hasFlag(name string) bool {
+	for _, target := range a.targets {
+		index := a.p.findFieldIndex(name, target.value.Type(), nil)
+		if len(index) > 0 && !target.hidden[indexKey(index)] && !a.p.isNilPreserved(target.value, index) {
+			return true
+		}
+	}
+	return false
+}
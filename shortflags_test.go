@@ -0,0 +1,77 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+type shortFlags struct {
+	N       int    `flag:"n"`
+	O       string `flag:"o"`
+	X       bool   `flag:"x"`
+	V       int    `flag:"v,count"`
+	Name    string `flag:"name"`
+	Verbose bool   `flag:"verbose"`
+}
+
+func TestAttachedShortValues(t *testing.T) {
+	var sf shortFlags
+	res, err := NewParser().Apply([]string{"-n5", "-ofile.txt"}, &sf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if sf.N != 5 || sf.O != "file.txt" {
+		t.Errorf("expected 5 and file.txt, got %d and %q", sf.N, sf.O)
+	}
+	if len(res.Unused) != 0 {
+		t.Errorf("expected no unused args, got %v", res.Unused)
+	}
+}
+
+func TestAttachedShortValueWithEquals(t *testing.T) {
+	var sf shortFlags
+	if _, err := NewParser().Apply([]string{"-ofile=a.txt"}, &sf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if sf.O != "file=a.txt" {
+		t.Errorf("expected file=a.txt, got %q", sf.O)
+	}
+}
+
+func TestShortFlagsNotCombinedByDefault(t *testing.T) {
+	var sf shortFlags
+	res, err := NewParser().Apply([]string{"-xv"}, &sf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if sf.X || sf.V != 0 {
+		t.Errorf("expected -xv not to be combined, got %+v", sf)
+	}
+	if len(res.Unused) != 1 || res.Unused[0] != "-xv" {
+		t.Errorf("expected -xv unused, got %v", res.Unused)
+	}
+}
+
+func TestCombinedShortFlags(t *testing.T) {
+	var sf shortFlags
+	if _, err := NewParser(WithCombinedShortFlags(true)).Apply([]string{"-xvvofile.txt", "-name", "n"}, &sf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !sf.X || sf.V != 2 || sf.O != "file.txt" || sf.Name != "n" {
+		t.Errorf("unexpected flags %+v", sf)
+	}
+}
+
+func TestCombinedShortFlagsFollowingValue(t *testing.T) {
+	var sf shortFlags
+	unused, err := NewParser(WithCombinedShortFlags(true)).ApplyTo([]string{"-xvo", "out.tar", "-xq", "-verbose"}, &sf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !sf.X || sf.V != 1 || sf.O != "out.tar" || !sf.Verbose {
+		t.Errorf("expected the last letter to take the following value, got %+v", sf)
+	}
+	if !reflect.DeepEqual(unused, []string{"-xq"}) {
+		t.Errorf("expected a group with an unknown letter not to be split, got %v", unused)
+	}
+}
@@ -0,0 +1,117 @@
+package argflags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Sprint renders the current values of the given option struct, or struct pointer, as a table of its flags, for showing the config in use.
+// Each flag is given by its first name, with its values, joined by the delimiter, quoted should they be empty or contain spaces.
+// Secret values are masked.  Flags with a default tag are annotated with '(default)' when they hold the default,
+// or with the default they would otherwise hold.  Flags of sub args which are not in use, such as nil ones, are left out.
+// Each element of a grouped sub arg is given by its index, e.g. '-target[0]', with its flags indented beneath it.
+// Fields bound to positional arguments follow the flags, named by their field name.
+// e.g. fmt.Print(argflags.Sprint(opts))
+func Sprint(str interface{}) string {
+	return NewParser().Sprint(str)
+}
+
+// Sprint renders the current values of the given option struct, as named by the parser.  See Sprint.
+func (p *Parser) Sprint(str interface{}) string {
+	v := reflect.Indirect(reflect.ValueOf(str))
+	if v.Kind() != reflect.Struct {
+		return fmt.Sprintf("%v", str)
+	}
+	rows := p.sprintRows(v, "")
+	width := 0
+	for _, r := range rows {
+		if len(r.name) > width {
+			width = len(r.name)
+		}
+	}
+	buf := &strings.Builder{}
+	for _, r := range rows {
+		line := fmt.Sprintf("%-*s  %s", width, r.name, r.value)
+		if r.note != "" {
+			line = strings.Join([]string{line, r.note}, " ")
+		}
+		buf.WriteString(strings.TrimRight(line, " "))
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// sprintRow is a line of the table rendered by Sprint.
+type sprintRow struct {
+	name  string
+	value string
+	note  string
+}
+
+// sprintRows gets a row for each flag, and positional field, of the given struct, with its name indented by the given indent.
+func (p *Parser) sprintRows(v reflect.Value, indent string) []sprintRow {
+	var rows []sprintRow
+	for _, fd := range p.describeFlags(v.Type()) {
+		fld, ok := p.fieldInUse(v, fd.index)
+		if !ok {
+			continue
+		}
+		name := indent + "-" + fd.names[0]
+		if p.isGroup(fd.field) {
+			for i := 0; i < fld.Len(); i++ {
+				elem := reflect.Indirect(fld.Index(i))
+				if !elem.IsValid() {
+					continue
+				}
+				rows = append(rows, sprintRow{name: fmt.Sprintf("%s[%d]", name, i)})
+				rows = append(rows, p.sprintRows(elem, indent+"  ")...)
+			}
+			continue
+		}
+		rows = append(rows, p.sprintRow(name, fd.field, fld))
+	}
+	fields, _ := positionalFields(v.Type())
+	for _, pf := range fields {
+		if p.isPositionalOnly(pf.field) {
+			rows = append(rows, p.sprintRow(indent+pf.field.Name, pf.field, v.Field(pf.index)))
+		}
+	}
+	return rows
+}
+
+// sprintRow gets the row for the given field, with its values and any default annotation.
+func (p *Parser) sprintRow(name string, f reflect.StructField, fld reflect.Value) sprintRow {
+	values := p.diffFormat(f, fld)
+	secret := p.isSecretField(f, fld)
+	r := sprintRow{name: name, value: p.sprintValues(values, f, secret)}
+	if def, ok := f.Tag.Lookup(DefaultTagName); ok {
+		defValue := reflect.New(f.Type).Elem()
+		if err := p.setTagged(def, f, defValue, p.setValue); err == nil && equalValues(values, p.diffFormat(f, defValue)) {
+			r.note = "(default)"
+		} else if secret {
+			r.note = fmt.Sprintf("(default %s)", secretMask)
+		} else {
+			r.note = fmt.Sprintf("(default %s)", quoteArg(def))
+		}
+	}
+	return r
+}
+
+// sprintValues joins the given values of the given field with its delimiter, each quoted, or masked when secret.
+// Values of slices and maps have any delimiters within them escaped.
+func (p *Parser) sprintValues(values []string, f reflect.StructField, secret bool) string {
+	if secret {
+		values = maskValues(values)
+	}
+	delim := p.delimiterOf(f)
+	delimited := isDelimitedType(f.Type)
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		if delimited {
+			value = escapeDelimited(value, delim)
+		}
+		quoted[i] = quoteArg(value)
+	}
+	return strings.Join(quoted, delim)
+}
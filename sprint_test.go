@@ -0,0 +1,27 @@
+package argflags
+
+import (
+	"strings"
+	"testing"
+)
+
+type sprintFlags struct {
+	Host     string   `flag:"host" default:"localhost"`
+	Port     int      `flag:"port" default:"80"`
+	Tags     []string `flag:"tag"`
+	Password string   `flag:"password,secret"`
+	Name     string   `flag:"name"`
+}
+
+func TestSprint(t *testing.T) {
+	sf := sprintFlags{Host: "localhost", Port: 8080, Tags: []string{"a,b", "c"}, Password: "p"}
+	s := Sprint(&sf)
+	for _, expect := range []string{"-host", "(default)", "8080", "(default 80)", `'a\,b',c`, secretMask, "''"} {
+		if !strings.Contains(s, expect) {
+			t.Errorf("expected %q in\n%s", expect, s)
+		}
+	}
+	if strings.Contains(s, "p\n") {
+		t.Errorf("expected the password to be masked in\n%s", s)
+	}
+}
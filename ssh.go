@@ -0,0 +1,122 @@
+package argflags
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultSSHPort is the port of an SSHDestination given without a port.
+const DefaultSSHPort = 22
+
+// SSHDestination is an SSH destination, '[user@]host[:port]' or an 'ssh://[user@]host[:port]' URL.
+// IPv6 hosts with a port are given in brackets, e.g. 'admin@[2001:db8::1]:2222'
+// Destinations without a port have the DefaultSSHPort.  The user may be empty, for the SSH client to use its own default.
+type SSHDestination struct {
+	User string
+	Host string
+	Port int
+}
+
+// Address gets the host and port, as used to dial the destination. e.g. 'example.com:22'
+func (d SSHDestination) Address() string {
+	return net.JoinHostPort(d.Host, strconv.Itoa(d.Port))
+}
+
+// String gets the destination as '[user@]host[:port]', omitting the port when it is the default.
+func (d SSHDestination) String() string {
+	s := d.Host
+	if d.Port != DefaultSSHPort && d.Port != 0 {
+		s = d.Address()
+	}
+	if d.User != "" {
+		s = strings.Join([]string{d.User, s}, "@")
+	}
+	return s
+}
+
+// URL gets the destination as an ssh:// URL
+func (d SSHDestination) URL() *url.URL {
+	u := &url.URL{Scheme: "ssh", Host: d.Address()}
+	if d.User != "" {
+		u.User = url.User(d.User)
+	}
+	return u
+}
+
+func (d SSHDestination) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *SSHDestination) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	v, err := parseSSHDestination(s)
+	if err != nil {
+		return fmt.Errorf("invalid ssh destination %q  %v", s, err)
+	}
+	*d = v
+	return nil
+}
+
+func parseSSHDestination(s string) (SSHDestination, error) {
+	d := SSHDestination{Port: DefaultSSHPort}
+	hostPort := s
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return SSHDestination{}, err
+		}
+		if u.Scheme != "ssh" {
+			return SSHDestination{}, fmt.Errorf("unsupported scheme %q, expected ssh://", u.Scheme)
+		}
+		if u.Path != "" && u.Path != "/" {
+			return SSHDestination{}, fmt.Errorf("ssh urls can not have a path")
+		}
+		d.User = u.User.Username()
+		hostPort = u.Host
+	} else if i := strings.LastIndex(s, "@"); i >= 0 {
+		d.User, hostPort = s[:i], s[i+1:]
+		if d.User == "" {
+			return SSHDestination{}, fmt.Errorf("missing user before '@'")
+		}
+	}
+	if strings.ContainsAny(d.User, " @:") {
+		return SSHDestination{}, fmt.Errorf("invalid user %q", d.User)
+	}
+	d.Host = hostPort
+	if host, port, err := net.SplitHostPort(hostPort); err == nil {
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 1 || p > 65535 {
+			return SSHDestination{}, fmt.Errorf("invalid port %q", port)
+		}
+		d.Host, d.Port = host, p
+	} else if strings.Count(hostPort, ":") == 1 {
+		return SSHDestination{}, err
+	}
+	d.Host = strings.TrimSuffix(strings.TrimPrefix(d.Host, "["), "]")
+	if d.Host == "" {
+		return SSHDestination{}, fmt.Errorf("missing host")
+	}
+	if net.ParseIP(d.Host) == nil && !isHostName(d.Host) {
+		return SSHDestination{}, fmt.Errorf("invalid host %q", d.Host)
+	}
+	return d, nil
+}
+
+// isHostName checks the given name is dot separated labels of alphanumerics and '-', not beginning or ending with '-'.
+func isHostName(s string) bool {
+	if len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(s, "."), ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		if strings.Trim(strings.ToLower(label), "abcdefghijklmnopqrstuvwxyz0123456789-") != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package argflags
+
+import "testing"
+
+func TestSSHDestination(t *testing.T) {
+	tests := map[string]SSHDestination{
+		"example.com":                      {Host: "example.com", Port: 22},
+		"git@github.com":                   {User: "git", Host: "github.com", Port: 22},
+		"admin@10.0.0.1:2222":              {User: "admin", Host: "10.0.0.1", Port: 2222},
+		"admin@[2001:db8::1]:2222":         {User: "admin", Host: "2001:db8::1", Port: 2222},
+		"2001:db8::1":                      {Host: "2001:db8::1", Port: 22},
+		"ssh://deploy@host.example.com:22": {User: "deploy", Host: "host.example.com", Port: 22},
+		"ssh://host":                       {Host: "host", Port: 22},
+	}
+	for s, expect := range tests {
+		var d SSHDestination
+		if err := d.UnmarshalText([]byte(s)); err != nil {
+			t.Errorf("%s  unexpected error  %v", s, err)
+			continue
+		}
+		if d != expect {
+			t.Errorf("%s  expected %+v, got %+v", s, expect, d)
+		}
+	}
+	for _, s := range []string{"", "@host", "host:0", "host:x", "http://host", "ssh://host/path", "bad_host", "a b@host"} {
+		var d SSHDestination
+		if err := d.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%s  expected an error", s)
+		}
+	}
+}
+
+func TestSSHDestinationFormats(t *testing.T) {
+	d := SSHDestination{User: "admin", Host: "2001:db8::1", Port: 2222}
+	if d.String() != "admin@[2001:db8::1]:2222" || d.URL().String() != "ssh://admin@[2001:db8::1]:2222" {
+		t.Errorf("expected the port with a bracketed host, got %s, %s", d, d.URL())
+	}
+	d = SSHDestination{Host: "example.com", Port: DefaultSSHPort}
+	if d.String() != "example.com" || d.Address() != "example.com:22" {
+		t.Errorf("expected the default port to be omitted, got %s, %s", d, d.Address())
+	}
+}
@@ -0,0 +1,96 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+type dbOpts struct {
+	Host string `flag:"host"`
+	Port int    `flag:"port"`
+}
+
+type cacheOpts struct {
+	Size int `flag:"cache-size"`
+}
+
+type LogOpts struct {
+	Level string `flag:"log-level"`
+}
+
+type serverFlags struct {
+	LogOpts
+	Host  string     `flag:"host"`
+	DB    *dbOpts    `flag:"+db"`
+	Cache *cacheOpts `flag:"+,preserve-nil"`
+}
+
+func TestPreserveNilSubArg(t *testing.T) {
+	var sf serverFlags
+	res, err := NewParser().Apply([]string{"-cache-size", "10"}, &sf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if sf.Cache != nil || !reflect.DeepEqual(res.Unused, []string{"-cache-size", "10"}) {
+		t.Errorf("expected the nil cache to be kept, and its flags unused, got %+v, unused %v", sf.Cache, res.Unused)
+	}
+	sf.Cache = &cacheOpts{}
+	if _, err := NewParser().Apply([]string{"-cache-size", "10"}, &sf); err != nil || sf.Cache.Size != 10 {
+		t.Errorf("expected the cache size set, got %+v  %v", sf.Cache, err)
+	}
+}
+
+func TestPrefixedSubArg(t *testing.T) {
+	var sf serverFlags
+	res, err := NewParser().Apply([]string{"-host", "web", "-db.host", "db", "-db-port", "5432"}, &sf)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if sf.Host != "web" || sf.DB == nil || sf.DB.Host != "db" || sf.DB.Port != 5432 {
+		t.Errorf("expected the db flags with their prefix, got %+v, %+v", sf, sf.DB)
+	}
+	if !reflect.DeepEqual(res.Instantiated, []string{"DB"}) {
+		t.Errorf("expected DB to be instantiated, got %v", res.Instantiated)
+	}
+}
+
+func TestEmbeddedSubArg(t *testing.T) {
+	var sf serverFlags
+	if _, err := NewParser().Apply([]string{"-log-level", "debug"}, &sf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if sf.Level != "debug" {
+		t.Errorf("expected the embedded flag to be set, got %q", sf.Level)
+	}
+}
+
+func TestNonStructSubArg(t *testing.T) {
+	var flags struct {
+		Name string `flag:"name"`
+		Sub  []int  `flag:"+"`
+	}
+	if _, err := NewParser().Apply([]string{"-name", "n"}, &flags); err == nil {
+		t.Errorf("expected a sub arg which is not a struct to be an error")
+	}
+	if err := Validate(&flags); err == nil {
+		t.Errorf("expected Validate to report the sub arg")
+	}
+}
+
+func TestSubArgNameNotAFlag(t *testing.T) {
+	type timeoutOpts struct {
+		Timeout int `flag:"timeout"`
+	}
+	var flags struct {
+		Timeout *timeoutOpts `flag:"+"`
+	}
+	if _, err := (ArgFlags{"-timeout", "5"}).ApplyTo(&flags); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if flags.Timeout == nil || flags.Timeout.Timeout != 5 {
+		t.Errorf("expected -timeout to set the flag within the sub arg of the same name, got %+v", flags.Timeout)
+	}
+	if err := Validate(&flags); err != nil {
+		t.Errorf("unexpected error  %v", err)
+	}
+}
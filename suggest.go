@@ -0,0 +1,62 @@
+package argflags
+
+import (
+	"strings"
+)
+
+// suggest finds the candidate closest to the given name, by edit distance, for suggesting when a name is not recognised.
+// returns an empty string if no candidate is close enough to be a likely typo of the name.
+func suggest(name string, candidates []string) string {
+	name = strings.ToLower(name)
+	limit := len(name) / 3
+	if limit < 1 {
+		limit = 1
+	}
+	var best string
+	bestDistance := limit + 1
+	for _, c := range candidates {
+		d := editDistance(name, strings.ToLower(c))
+		if d < bestDistance {
+			best = c
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// editDistance gets the number of single character insertions, deletions, substitutions or transpositions of adjacent characters,
+// required to change string a into string b (optimal string alignment distance).
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// three rows of the distance matrix, the previous two and the current
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = minInt(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+func minInt(i int, is ...int) int {
+	for _, n := range is {
+		if n < i {
+			i = n
+		}
+	}
+	return i
+}
@@ -0,0 +1,189 @@
+package argflags
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation, following stdin, stdout and stderr.
+const listenFDsStart = 3
+
+// activatedSocket is a socket inherited from systemd socket activation.
+type activatedSocket struct {
+	name     string
+	listener net.Listener
+	err      error
+	used     bool
+}
+
+var (
+	activatedSockets []*activatedSocket
+	activatedOnce    sync.Once
+	activatedMu      sync.Mutex
+)
+
+// SocketActivated checks if the process was started by systemd socket activation, with sockets passed to it.
+func SocketActivated() bool {
+	return len(systemdSockets()) > 0
+}
+
+// systemdSockets gets the sockets passed by systemd, reading LISTEN_FDS, LISTEN_PID and LISTEN_FDNAMES the first time it is called.
+// The variables are removed from the environment, so they are not inherited by child processes.
+func systemdSockets() []*activatedSocket {
+	activatedOnce.Do(func() {
+		if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+			return
+		}
+		count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+		if err != nil || count < 1 {
+			return
+		}
+		names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+		for i := 0; i < count; i++ {
+			s := &activatedSocket{name: "unknown"}
+			if i < len(names) && names[i] != "" {
+				s.name = names[i]
+			}
+			f := os.NewFile(uintptr(listenFDsStart+i), s.name)
+			s.listener, s.err = net.FileListener(f)
+			f.Close()
+			activatedSockets = append(activatedSockets, s)
+		}
+		for _, env := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+			os.Unsetenv(env)
+		}
+	})
+	return activatedSockets
+}
+
+// activatedListener takes the first unused, inherited socket matching the given address.
+// returns false if the process was not socket activated or no socket matches.
+func activatedListener(a Addr) (net.Listener, bool, error) {
+	activatedMu.Lock()
+	defer activatedMu.Unlock()
+	for _, s := range systemdSockets() {
+		if s.used || !s.matches(a) {
+			continue
+		}
+		s.used = true
+		return s.listener, true, s.err
+	}
+	return nil, false, nil
+}
+
+// matches checks if the socket is the one given by the address.
+// 'systemd' addresses match by the socket name, or any socket if no name is given.
+// Other addresses match a socket listening on the same unix path, or the same tcp port and, if given, host.
+func (s *activatedSocket) matches(a Addr) bool {
+	if a.Network == "systemd" {
+		return a.Address == "" || a.Address == s.name
+	}
+	if s.err != nil {
+		return false
+	}
+	switch la := s.listener.Addr().(type) {
+	case *net.UnixAddr:
+		return a.IsUnix() && la.Name == a.Address
+	case *net.TCPAddr:
+		if !strings.HasPrefix(a.Network, "tcp") {
+			return false
+		}
+		host, port, err := net.SplitHostPort(a.Address)
+		if err != nil || port != strconv.Itoa(la.Port) {
+			return false
+		}
+		return host == "" || la.IP.Equal(net.ParseIP(host)) || (la.IP.IsUnspecified() && net.ParseIP(host).IsUnspecified())
+	}
+	return false
+}
+
+// errNoActivatedSocket gets the error for a 'systemd' address which has no matching inherited socket.
+func errNoActivatedSocket(a Addr) error {
+	if !SocketActivated() {
+		return fmt.Errorf("%s requires systemd socket activation, but no sockets were passed", a.String())
+	}
+	if a.Address == "" {
+		return fmt.Errorf("no unused systemd socket for %s", a.String())
+	}
+	return fmt.Errorf("no unused systemd socket named %q", a.Address)
+}
+
+// WriteEnvironmentFile writes the env tagged fields of the given struct pointer as a systemd EnvironmentFile,
+// a KEY=VALUE line for each variable given by EnvFrom, so a tuned invocation can be kept as service configuration.
+// Values with spaces or quotes are double quoted.  Secret fields are written with their actual values,
+// so the file should only be readable by the service, as an EnvironmentFile can be, unlike the unit itself.
+func WriteEnvironmentFile(w io.Writer, str interface{}) error {
+	return NewParser().WriteEnvironmentFile(w, str)
+}
+
+// WriteEnvironmentFile writes the env tagged fields of the given struct pointer as a systemd EnvironmentFile,
+// with the env prefix and delimiter of the parser.  See WriteEnvironmentFile.
+func (p *Parser) WriteEnvironmentFile(w io.Writer, str interface{}) error {
+	evs, err := p.envValues(str)
+	if err != nil {
+		return err
+	}
+	buf := &strings.Builder{}
+	for _, ev := range evs {
+		fmt.Fprintf(buf, "%s=%s\n", ev.name, systemdQuote(ev.value, `\"$`+"`", false))
+	}
+	_, err = io.WriteString(w, buf.String())
+	return err
+}
+
+// WriteDropIn writes the env tagged fields of the given struct pointer as a systemd drop-in unit snippet,
+// a [Service] section with an Environment directive for each variable given by EnvFrom.
+// e.g. written to /etc/systemd/system/myapp.service.d/options.conf
+// Secret fields are left out, as units are readable by all, so should be given with WriteEnvironmentFile.
+func WriteDropIn(w io.Writer, str interface{}) error {
+	return NewParser().WriteDropIn(w, str)
+}
+
+// WriteDropIn writes the env tagged fields of the given struct pointer as a systemd drop-in unit snippet,
+// with the env prefix and delimiter of the parser.  See WriteDropIn.
+func (p *Parser) WriteDropIn(w io.Writer, str interface{}) error {
+	evs, err := p.envValues(str)
+	if err != nil {
+		return err
+	}
+	buf := &strings.Builder{}
+	buf.WriteString("[Service]\n")
+	for _, ev := range evs {
+		if ev.secret {
+			continue
+		}
+		// specifiers are expanded in unit files, so a literal '%' is doubled
+		assignment := strings.ReplaceAll(strings.Join([]string{ev.name, ev.value}, "="), "%", "%%")
+		fmt.Fprintf(buf, "Environment=%s\n", systemdQuote(assignment, `\"`, true))
+	}
+	_, err = io.WriteString(w, buf.String())
+	return err
+}
+
+// systemdQuote double quotes the given value, should it contain white space, quotes or any of the given escaped characters,
+// escaping those characters with a backslash.  Line breaks are escaped as '\n' when escapeLines is set, otherwise kept.
+func systemdQuote(value, escaped string, escapeLines bool) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r'#;"+escaped) {
+		return value
+	}
+	buf := &strings.Builder{}
+	buf.WriteString(`"`)
+	for _, r := range value {
+		switch {
+		case r == '\n' && escapeLines:
+			buf.WriteString(`\n`)
+		case strings.ContainsRune(escaped, r):
+			buf.WriteRune('\\')
+			buf.WriteRune(r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteString(`"`)
+	return buf.String()
+}
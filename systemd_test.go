@@ -0,0 +1,83 @@
+package argflags
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteEnvironmentFile(t *testing.T) {
+	buf := &bytes.Buffer{}
+	df := deployFlags{Host: `a "b" $c`, Password: "p"}
+	if err := WriteEnvironmentFile(buf, &df); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := "HOST=\"a \\\"b\\\" \\$c\"\nPASSWORD=p\n"
+	if buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+}
+
+func TestWriteDropIn(t *testing.T) {
+	buf := &bytes.Buffer{}
+	df := deployFlags{Host: "100% up", Port: 80, Password: "p"}
+	if err := WriteDropIn(buf, &df); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	s := buf.String()
+	if !strings.HasPrefix(s, "[Service]\n") || !strings.Contains(s, `Environment="HOST=100%% up"`) || !strings.Contains(s, "Environment=PORT=80") {
+		t.Errorf("unexpected drop in\n%s", s)
+	}
+	if strings.Contains(s, "PASSWORD") {
+		t.Errorf("expected secrets to be left out of\n%s", s)
+	}
+}
+
+func TestActivatedSocketMatches(t *testing.T) {
+	tl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("can not listen  %v", err)
+	}
+	defer tl.Close()
+	sock := filepath.Join(t.TempDir(), "app.sock")
+	ul, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("can not listen  %v", err)
+	}
+	defer ul.Close()
+	_, port, _ := net.SplitHostPort(tl.Addr().String())
+	tcp := &activatedSocket{name: "web", listener: tl}
+	unix := &activatedSocket{name: "unknown", listener: ul}
+	tests := []struct {
+		socket *activatedSocket
+		addr   Addr
+		expect bool
+	}{
+		{tcp, Addr{Network: "systemd"}, true},
+		{tcp, Addr{Network: "systemd", Address: "web"}, true},
+		{tcp, Addr{Network: "systemd", Address: "api"}, false},
+		{tcp, Addr{Network: "tcp", Address: ":" + port}, true},
+		{tcp, Addr{Network: "tcp", Address: "127.0.0.1:" + port}, true},
+		{tcp, Addr{Network: "tcp", Address: "10.0.0.1:" + port}, false},
+		{tcp, Addr{Network: "tcp", Address: ":1"}, false},
+		{tcp, Addr{Network: "unix", Address: sock}, false},
+		{unix, Addr{Network: "unix", Address: sock}, true},
+		{unix, Addr{Network: "unix", Address: sock + ".other"}, false},
+	}
+	for _, tc := range tests {
+		if tc.socket.matches(tc.addr) != tc.expect {
+			t.Errorf("%s  expected a match %v, with %v", tc.addr, tc.expect, tc.socket.listener.Addr())
+		}
+	}
+}
+
+func TestSystemdAddrWithoutActivation(t *testing.T) {
+	if SocketActivated() {
+		t.Skip("socket activated")
+	}
+	if _, err := (Addr{Network: "systemd", Address: "web"}).Listen(); err == nil {
+		t.Errorf("expected a systemd address to fail without socket activation")
+	}
+}
@@ -0,0 +1,264 @@
+package argflags
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ArgsMarshaler is implemented by flag values which give their own arguments, when a struct is converted with ToArgs.
+// MarshalArgs is given the flag names of the field, and returns the arguments, including the flags, setting its value.
+type ArgsMarshaler interface {
+	MarshalArgs(names []string) ([]string, error)
+}
+
+var argsMarshalerType = reflect.TypeOf((*ArgsMarshaler)(nil)).Elem()
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// ToArgs converts the given struct pointer back into the arguments which would set its current values.
+// Every flag field with a non zero value, or with a default tag, is given as '-name=value', using the first name of the field.
+// Bool flags which are true are given as '-name' alone.  Slices and maps have a flag for each element or entry,
+// with any delimiters within them escaped.
+// Fields bound to positional arguments follow the flags, after a '--' should any of them begin with a dash.
+// Secret fields are given with their actual values, so take care where the arguments are logged.
+func ToArgs(str interface{}) (ArgFlags, error) {
+	return NewParser().ToArgs(str)
+}
+
+// ToArgs converts the given struct pointer back into the arguments which would set its current values, as named by the parser.
+func (p *Parser) ToArgs(str interface{}) (ArgFlags, error) {
+	v, err := getStructValue(str)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.Schema(v.Type()); err != nil {
+		return nil, err
+	}
+	args, err := p.structFlagArgs(*v)
+	if err != nil {
+		return nil, err
+	}
+	positional, err := p.positionalArgs(*v)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range positional {
+		if strings.HasPrefix(arg, "-") {
+			args = append(args, argsTerminator)
+			break
+		}
+	}
+	return append(args, positional...), nil
+}
+
+// structFlagArgs gets the flag arguments setting the flag fields of the given struct.
+func (p *Parser) structFlagArgs(v reflect.Value) (ArgFlags, error) {
+	var args ArgFlags
+	for _, fd := range p.describeFlags(v.Type()) {
+		fld, ok := p.fieldInUse(v, fd.index)
+		if !ok {
+			continue
+		}
+		if _, hasDefault := fd.field.Tag.Lookup(DefaultTagName); fld.IsZero() && !hasDefault {
+			continue
+		}
+		var fargs []string
+		var err error
+		if p.isGroup(fd.field) {
+			fargs, err = p.groupArgs(fd.names[0], fld)
+		} else {
+			fargs, err = p.flagArgs(fd.names, fd.field, fld)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("'-%s'  %v", fd.names[0], err)
+		}
+		args = append(args, fargs...)
+	}
+	return args, nil
+}
+
+// flagArgs gets the arguments setting the given field, with the first of the given flag names.
+// The values of slices and maps have any delimiters within them escaped.
+func (p *Parser) flagArgs(names []string, f reflect.StructField, fld reflect.Value) ([]string, error) {
+	if am := asArgsMarshaler(fld); am != nil {
+		return am.MarshalArgs(names)
+	}
+	flag := "-" + names[0]
+	if fld.Kind() == reflect.Bool && fld.Bool() {
+		return []string{flag}, nil
+	}
+	values, err := p.formatFieldValues(f, fld)
+	if err != nil {
+		return nil, err
+	}
+	delimited := isDelimitedType(f.Type)
+	args := make([]string, len(values))
+	for i, value := range values {
+		if delimited {
+			value = escapeDelimited(value, p.delimiterOf(f))
+		}
+		args[i] = strings.Join([]string{flag, value}, "=")
+	}
+	return args, nil
+}
+
+// positionalArgs gets the values of the positional fields of the given struct, in position order.
+func (p *Parser) positionalArgs(v reflect.Value) ([]string, error) {
+	fields, err := positionalFields(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for _, pf := range fields {
+		fld := v.Field(pf.index)
+		if fld.IsZero() {
+			continue
+		}
+		values, err := p.formatFieldValues(pf.field, fld)
+		if err != nil {
+			return nil, fmt.Errorf("'%s'  %v", pf.field.Name, err)
+		}
+		args = append(args, values...)
+	}
+	return args, nil
+}
+
+// formatFieldValues formats the values of the given field, as formatValues does,
+// with the integers of fields tagged with an octal option formatted in octal, with a leading '0', so they apply back the same.
+func (p *Parser) formatFieldValues(f reflect.StructField, fld reflect.Value) ([]string, error) {
+	if !p.isOctal(f) {
+		return formatValues(fld)
+	}
+	if fld.Kind() == reflect.Ptr {
+		if fld.IsNil() {
+			return nil, nil
+		}
+		fld = fld.Elem()
+	}
+	elems := []reflect.Value{fld}
+	if fld.Kind() == reflect.Slice {
+		elems = make([]reflect.Value, fld.Len())
+		for i := range elems {
+			elems[i] = reflect.Indirect(fld.Index(i))
+		}
+	}
+	values := make([]string, len(elems))
+	for i, elem := range elems {
+		if !elem.IsValid() {
+			continue
+		}
+		values[i] = formatNumber(elem, 8)
+	}
+	return values, nil
+}
+
+// formatValues formats the given field as the values of a flag, a value for each element of a slice, or each entry of a map.
+func formatValues(fld reflect.Value) ([]string, error) {
+	if isFormattedWhole(fld) {
+		s, err := formatValue(fld)
+		return []string{s}, err
+	}
+	switch fld.Kind() {
+	case reflect.Ptr:
+		if fld.IsNil() {
+			return nil, nil
+		}
+		return formatValues(fld.Elem())
+	case reflect.Slice:
+		values := make([]string, fld.Len())
+		for i := range values {
+			s, err := formatValue(fld.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = s
+		}
+		return values, nil
+	case reflect.Map:
+		var values []string
+		for _, key := range fld.MapKeys() {
+			k, err := formatValue(key)
+			if err != nil {
+				return nil, err
+			}
+			v, err := formatValue(fld.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, strings.Join([]string{k, v}, "="))
+		}
+		sort.Strings(values)
+		return values, nil
+	}
+	s, err := formatValue(fld)
+	return []string{s}, err
+}
+
+// formatValue formats a single value, with its MarshalText or flag.Value String method, if it has one, or by its kind.
+func formatValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() {
+		v = v.Addr()
+	} else {
+		pv := reflect.New(v.Type())
+		pv.Elem().Set(v)
+		v = pv
+	}
+	switch iv := v.Interface().(type) {
+	case encoding.TextMarshaler:
+		b, err := iv.MarshalText()
+		return string(b), err
+	case flag.Value:
+		return iv.String(), nil
+	}
+	v = v.Elem()
+	if v.Type() == durationType {
+		return fmt.Sprint(v.Interface()), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float64, reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("%s can not be formatted as a flag value", v.Type().String())
+}
+
+// isFormattedWhole checks if the given value is formatted as a whole, by its MarshalText or flag.Value String method.
+func isFormattedWhole(v reflect.Value) bool {
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pt := reflect.PtrTo(t)
+	return pt.Implements(textMarshalerType) || pt.Implements(flagValueType)
+}
+
+// asArgsMarshaler gets the given value as an ArgsMarshaler, or nil if it does not support that interface.
+func asArgsMarshaler(fld reflect.Value) ArgsMarshaler {
+	fldPtr := fld
+	if fld.Kind() != reflect.Ptr {
+		fldPtr = fld.Addr()
+	} else if fld.IsNil() {
+		return nil
+	}
+	if !fldPtr.Type().Implements(argsMarshalerType) {
+		return nil
+	}
+	return fldPtr.Interface().(ArgsMarshaler)
+}
@@ -0,0 +1,81 @@
+package argflags
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type accumulatedFlags struct {
+	Headers Header   `flag:"H"`
+	Query   Query    `flag:"q"`
+	Cookies Cookies  `flag:"cookie"`
+	Env     EnvVars  `flag:"e"`
+	Tags    []string `flag:"tag"`
+}
+
+func TestToArgsAccumulators(t *testing.T) {
+	var af accumulatedFlags
+	args := []string{
+		"-H", "Accept: text/html", "-H", "Accept: application/json", "-H", "X-Trace: 1",
+		"-q", "tag=a", "-q", "tag=b", "-q", "limit=10",
+		"-cookie", "session=abc123; Path=/; Secure", "-cookie", "theme=dark",
+		"-e", "GREETING=hello, world", "-e", "DEBUG=1",
+		"-tag", `a\,b,c`,
+	}
+	if _, err := ArgFlags(args).ApplyTo(&af); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	out, err := ToArgs(&af)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	var rt accumulatedFlags
+	if _, err := out.ApplyTo(&rt); err != nil {
+		t.Fatalf("unexpected error applying %v  %v", out, err)
+	}
+	if !reflect.DeepEqual(af, rt) {
+		t.Errorf("expected %+v, got %+v from %v", af, rt, out)
+	}
+	if len(rt.Headers["Accept"]) != 2 || len(rt.Query["tag"]) != 2 || len(rt.Cookies) != 2 {
+		t.Errorf("expected every value to round trip, got %v", out)
+	}
+}
+
+type octalFlags struct {
+	Mode  int     `flag:"mode,mode" env:"MODE"`
+	Umask *uint32 `flag:"umask,umask"`
+	Bits  []int   `flag:"bits,octal"`
+	Files int     `flag:"files,ulimit"`
+}
+
+func TestToArgsOctal(t *testing.T) {
+	var of octalFlags
+	if _, err := ArgFlags([]string{"-mode", "0755", "-umask", "022", "-bits", "0,17", "-files", "unlimited"}).ApplyTo(&of); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	out, err := ToArgs(&of)
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if !reflect.DeepEqual(out, ArgFlags{"-mode=0755", "-umask=022", "-bits=00", "-bits=017", "-files=-1"}) {
+		t.Errorf("expected the octal flags in octal, got %v", out)
+	}
+	var rt octalFlags
+	if _, err := out.ApplyTo(&rt); err != nil {
+		t.Fatalf("unexpected error applying %v  %v", out, err)
+	}
+	if !reflect.DeepEqual(of, rt) || !Equal(&of, &rt) {
+		t.Errorf("expected %+v, got %+v from %v", of, rt, out)
+	}
+	diffs, err := Diff(&octalFlags{}, &of)
+	if err != nil || len(diffs) == 0 || diffs[0].Field != "Mode" || !reflect.DeepEqual(diffs[0].B, []string{"0755"}) {
+		t.Errorf("expected the mode difference in octal, got %+v, %v", diffs, err)
+	}
+	if s := Sprint(&of); !strings.Contains(s, "0755") || strings.Contains(s, "493") {
+		t.Errorf("expected the mode printed in octal, got %s", s)
+	}
+	if env, err := EnvFrom(&of); err != nil || !reflect.DeepEqual(env, []string{"MODE=0755"}) {
+		t.Errorf("expected the mode variable in octal, got %v, %v", env, err)
+	}
+}
@@ -0,0 +1,79 @@
+package argflags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewArgFlagsFromString splits the given command line into ArgFlags, in the manner of a shell, see splitCommandLine.
+// e.g. `-name "my app" -label 'tier=web db'` gives the four arguments: -name, my app, -label and tier=web db
+// It is the inverse of ArgFlags.String, so arguments may be kept as a single string, in config entries or test fixtures.
+func NewArgFlagsFromString(s string) (ArgFlags, error) {
+	args, err := splitCommandLine(s)
+	if err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// splitCommandLine splits the given command line into its arguments, in the manner of a shell.
+// Arguments are separated by unquoted whitespace.
+// Single quotes preserve everything within them, literally.
+// Double quotes preserve whitespace and single quotes, but allow backslash escaping of double quotes and backslashes.
+// Outside of quotes, a backslash escapes the following character.
+func splitCommandLine(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	var inArg bool
+	var quote rune
+	var escaped bool
+	for _, r := range s {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("unexpected end of line after escape character")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// quoteArg quotes the given argument, if required, so that it is split as a single argument.
+func quoteArg(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n\r'\"\\") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
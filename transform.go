@@ -0,0 +1,82 @@
+package argflags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// TransformTagName is the tag naming the transforms applied to a string flag, once set, in the order given.
+// e.g. Name string `flag:"name" transform:"trim,lower"`
+// The transforms are 'trim', removing leading and trailing white space, 'lower', 'upper' and 'title' case.
+// They apply to string fields, pointers to strings and each element of string slices.
+// Length limits are checked on the transformed value.
+const TransformTagName = "transform"
+
+// transforms are the string transforms, named in a transform tag.
+var transforms = map[string]func(s string) string{
+	"trim":  strings.TrimSpace,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"title": func(s string) string {
+		// a Caser is not safe to share between goroutines, so one is made for each value
+		return cases.Title(language.Und).String(s)
+	},
+}
+
+// hasTransform checks if the given field has a transform tag.
+func hasTransform(f reflect.StructField) bool {
+	_, ok := f.Tag.Lookup(TransformTagName)
+	return ok
+}
+
+// transformsOf gets the transforms named in the transform tag of the given field.
+func transformsOf(f reflect.StructField) ([]func(s string) string, error) {
+	var fns []func(s string) string
+	for _, name := range strings.Split(f.Tag.Get(TransformTagName), ",") {
+		fn, ok := transforms[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown transform %q, expected trim, lower, upper or title", name)
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+// applyTransforms applies the transforms in the transform tag, of the given field, to its string values.
+func applyTransforms(f reflect.StructField, fld reflect.Value) error {
+	fns, err := transformsOf(f)
+	if err != nil {
+		return err
+	}
+	fld = reflect.Indirect(fld)
+	values := []reflect.Value{fld}
+	if fld.Kind() == reflect.Slice {
+		values = nil
+		for i := 0; i < fld.Len(); i++ {
+			values = append(values, reflect.Indirect(fld.Index(i)))
+		}
+	}
+	for _, v := range values {
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("%s can not be transformed, only strings", v.Type().String())
+		}
+		s := v.String()
+		for _, fn := range fns {
+			s = fn(s)
+		}
+		v.SetString(s)
+	}
+	return nil
+}
+
+// isTransformable checks if the given type is a string, pointer to a string, or slice of either.
+func isTransformable(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
@@ -0,0 +1,23 @@
+package argflags
+
+import (
+	"reflect"
+	"testing"
+)
+
+type transformFlags struct {
+	Name  string   `flag:"name" transform:"trim,lower" maxlen:"5"`
+	Tags  []string `flag:"tag" transform:"upper" minlen:"2"`
+	Title string   `flag:"title" transform:"title"`
+}
+
+func TestTransforms(t *testing.T) {
+	var tf transformFlags
+	if _, err := NewParser().Apply([]string{"-name", "  ABCDE  ", "-tag", "a,b", "-title", "hello world"}, &tf); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	expect := transformFlags{Name: "abcde", Tags: []string{"A", "B"}, Title: "Hello World"}
+	if !reflect.DeepEqual(tf, expect) {
+		t.Errorf("expected %+v, got %+v", expect, tf)
+	}
+}
@@ -0,0 +1,68 @@
+package argflags
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var undoFuncs = template.FuncMap{
+	"quote": quoteArg,
+}
+
+// PrintUndo is an OnUndo function which writes the undo command line to the commands Output.
+func PrintUndo(ctx context.Context, inv *Invocation, undo ArgFlags) error {
+	root := inv.Command
+	for root.parent != nil {
+		root = root.parent
+	}
+	args := make([]string, len(undo))
+	for i, arg := range undo {
+		args[i] = quoteArg(arg)
+	}
+	_, err := fmt.Fprintf(Output(ctx), "to undo: %s %s\n", root.Name, strings.Join(args, " "))
+	return err
+}
+
+// UndoArgs gets the arguments which undo the given invocation, by executing the commands Undo template.
+// returns nil if the command has no Undo template.
+func (c *Command) UndoArgs(inv *Invocation) (ArgFlags, error) {
+	if c.Undo == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(c.Name).Funcs(undoFuncs).Parse(c.Undo)
+	if err != nil {
+		return nil, fmt.Errorf("command %s undo  %v", c.Path(), err)
+	}
+	data := inv.UndoData
+	if data == nil {
+		data = inv.Flags
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return nil, fmt.Errorf("command %s undo  %v", c.Path(), err)
+	}
+	args, err := splitCommandLine(buf.String())
+	if err != nil {
+		return nil, fmt.Errorf("command %s undo  %v", c.Path(), err)
+	}
+	return args, nil
+}
+
+// undo passes the undo arguments of the given invocation to the nearest OnUndo function.
+func (c *Command) undo(ctx context.Context, inv *Invocation) error {
+	var onUndo func(ctx context.Context, inv *Invocation, undo ArgFlags) error
+	for p := c; p != nil && onUndo == nil; p = p.parent {
+		onUndo = p.OnUndo
+	}
+	if onUndo == nil || c.Undo == "" {
+		return nil
+	}
+	args, err := c.UndoArgs(inv)
+	if err != nil {
+		return err
+	}
+	return onUndo(ctx, inv, args)
+}
@@ -0,0 +1,30 @@
+package argflags
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestUndo(t *testing.T) {
+	type createFlags struct {
+		Name string `flag:"name"`
+	}
+	root := &Command{Name: "app", OnUndo: PrintUndo}
+	root.AddCommand(&Command{Name: "create", Flags: &createFlags{}, Undo: "delete -name {{quote .Name}}",
+		Handler: func(ctx context.Context, inv *Invocation) error {
+			return nil
+		}})
+	buf := &bytes.Buffer{}
+	if err := root.Execute(context.WithValue(context.Background(), outputKey{}, buf), []string{"create", "-name", "my app"}); err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	if buf.String() != "to undo: app delete -name 'my app'\n" {
+		t.Errorf("expected the undo command line, got %q", buf.String())
+	}
+	args, err := root.Command("create").UndoArgs(&Invocation{Flags: &createFlags{Name: "my app"}})
+	if err != nil || !reflect.DeepEqual(args, ArgFlags{"delete", "-name", "my app"}) {
+		t.Errorf("expected the quoted name as one argument, got %q, %v", args, err)
+	}
+}
@@ -0,0 +1,33 @@
+package argflags
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// HelpTagName is the tag giving a description of a flag field, shown in the usage of the flags.
+// e.g. Port int `flag:"port,p" help:"the port to listen on"`
+const HelpTagName = "help"
+
+// ErrHelp is returned when the -h or --help flag is given, but not matched to any field.
+var ErrHelp = errors.New("help requested")
+
+// Usage gets the usage text of the given struct pointer, listing each of its flags, including those in sub args.
+// Each flag is listed with its aliases, type, any default and the description in its help tag.
+func Usage(str interface{}) string {
+	return NewParser().Usage(str)
+}
+
+// Usage gets the usage text of the given struct pointer, as the flags are named by the parser.
+func (p *Parser) Usage(str interface{}) string {
+	buf := &strings.Builder{}
+	buf.WriteString("Flags:\n")
+	p.writeFlagList(buf, reflect.TypeOf(str), nil, nil)
+	return buf.String()
+}
+
+// isHelpFlag checks if the given flag name is one of the help flags.
+func isHelpFlag(name string) bool {
+	return name == "h" || name == "help"
+}
@@ -0,0 +1,71 @@
+package argflags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestUsage(t *testing.T) {
+	type usageFlags struct {
+		Port int     `flag:"port,p" help:"the port to listen on" default:"80"`
+		DB   *dbOpts `flag:"+db"`
+	}
+	usage := Usage(&usageFlags{})
+	for _, expect := range []string{"-port, -p", "the port to listen on (default 80)", "-db.host", "string"} {
+		if !strings.Contains(usage, expect) {
+			t.Errorf("expected the usage to contain %q, got %q", expect, usage)
+		}
+	}
+	if strings.Index(usage, "-port") > strings.Index(usage, "-db.host") {
+		t.Errorf("expected the flags in field order, got %q", usage)
+	}
+	if usage := NewParser(WithTagName("opt")).Usage(&struct {
+		Name string `opt:"name"`
+	}{}); !strings.Contains(usage, "-name") {
+		t.Errorf("expected the flags as named by the parser, got %q", usage)
+	}
+}
+
+func TestHelpOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	p := NewParser(WithHelpOutput(buf))
+	if _, err := p.Apply([]string{"-help"}, &serveFlags{}); !errors.Is(err, ErrHelp) {
+		t.Fatalf("expected ErrHelp, got %v", err)
+	}
+	usage := buf.String()
+	if !strings.Contains(usage, "-port") {
+		t.Errorf("expected the usage in the help output, got %q", usage)
+	}
+	buf.Reset()
+	root := &Command{Name: "app", Parser: p}
+	root.AddCommand(&Command{Name: "serve", Flags: &serveFlags{}, Handler: func(ctx context.Context, inv *Invocation) error {
+		return nil
+	}})
+	if err := root.Execute(context.Background(), []string{"serve", "-help"}); !errors.Is(err, ErrHelp) {
+		t.Fatalf("expected ErrHelp, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "-port") {
+		t.Errorf("expected the command help in the same help output, got %q", buf.String())
+	}
+}
+
+func TestHelpOutputStdout(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error  %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	_, err = NewParser().Apply([]string{"-h"}, &serveFlags{})
+	os.Stdout = stdout
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	if !errors.Is(err, ErrHelp) || !strings.Contains(string(out), "-port") {
+		t.Errorf("expected requested help on stdout, got %q, %v", out, err)
+	}
+}
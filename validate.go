@@ -0,0 +1,72 @@
+package argflags
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// MaxConcurrentValidators limits the number of field validators run at the same time.
+// Validators may be expensive (DNS lookups, file checks), so they are run concurrently, up to this limit.
+var MaxConcurrentValidators = runtime.NumCPU()
+
+// Validator is implemented by field types which can check their own value, once set from a flag.
+// After all the flags have been applied, every field which was set and supports Validator is validated.
+type Validator interface {
+	Validate() error
+}
+
+// asValidator will return the Validator of the given value, if it supports the interface.
+// As with asTextUnmarshaler, non pointer values are checked using their address.
+func asValidator(fld reflect.Value) Validator {
+	if fld.Kind() == reflect.Ptr && fld.IsNil() {
+		return nil
+	}
+	if fld.Type().Implements(validatorType) {
+		return fld.Interface().(Validator)
+	}
+	if fld.CanAddr() && fld.Addr().Type().Implements(validatorType) {
+		return fld.Addr().Interface().(Validator)
+	}
+	return nil
+}
+
+// validateFields runs the validators of the given fields concurrently, bounded by MaxConcurrentValidators.
+// Fields not supporting Validator are skipped.
+// Errors are returned in the same order as the given fields, regardless of the order they completed in.
+func validateFields(fields []appliedField) error {
+	var validations []appliedField
+	var validators []Validator
+	for _, fv := range fields {
+		if vd := asValidator(fv.field); vd != nil {
+			validations = append(validations, fv)
+			validators = append(validators, vd)
+		}
+	}
+	if len(validators) == 0 {
+		return nil
+	}
+	limit := MaxConcurrentValidators
+	if limit < 1 {
+		limit = 1
+	}
+	errs := make([]error, len(validations))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, fv := range validations {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, fv appliedField) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := validators[i].Validate(); err != nil {
+				errs[i] = ErrValidation{Flag: fv.flag, Err: err}
+			}
+		}(i, fv)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,58 @@
+package argflags
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// evenNumber is a Validator, accepting only even numbers.
+type evenNumber int
+
+func (n evenNumber) Validate() error {
+	if n%2 != 0 {
+		return fmt.Errorf("%d is not even", n)
+	}
+	return nil
+}
+
+type validatedFlags struct {
+	A evenNumber `flag:"a"`
+	B evenNumber `flag:"b"`
+	C evenNumber `flag:"c"`
+}
+
+func TestValidators(t *testing.T) {
+	var vf validatedFlags
+	_, err := NewParser().Apply([]string{"-c", "3", "-a", "2", "-b", "5"}, &vf)
+	var ve ErrValidation
+	if !errors.As(err, &ve) || ve.Flag != "-c" {
+		t.Fatalf("expected the validation error of -c first, got %v", err)
+	}
+	if msg := err.Error(); strings.Count(msg, "is not even") != 2 || strings.Index(msg, "'-c'") > strings.Index(msg, "'-b'") {
+		t.Errorf("expected both errors, in the order the flags were given, got %v", err)
+	}
+}
+
+type badTags struct {
+	Port  int    `flag:"port" range:"10"`
+	Host  string `flag:"host"`
+	Other string `flag:"host"`
+	Count string `flag:"n,count"`
+}
+
+func TestValidateTags(t *testing.T) {
+	err := Validate(&badTags{})
+	if err == nil {
+		t.Fatalf("expected the tag mistakes to be found")
+	}
+	for _, mistake := range []string{"range", "host", "count"} {
+		if !strings.Contains(err.Error(), mistake) {
+			t.Errorf("expected a mistake with %s, got %v", mistake, err)
+		}
+	}
+	if err := Validate(validatedFlags{}); err != nil {
+		t.Errorf("unexpected error  %v", err)
+	}
+}